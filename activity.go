@@ -0,0 +1,475 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+)
+
+// Everyone who sees a task in their list can comment on it with
+// /comment <n> text or by replying to its card from /task <n>. In groups
+// the same commands work on the group's tasks, numbered as in its /list.
+// The card shows the task with a feed of its comments and changes:
+// completion, reopening, due time, priority and assignment. A task's owner
+// and assignee watch it unless they unwatch it, commenters start watching
+// it, and anyone who sees it can /watch it. Watchers hear about what others
+// do with the task, and replying to such a notice comments too.
+
+// Kinds of task activity. Completed and cancelled match the task status.
+const (
+	activityComment    = "comment"
+	activityCompleted  = "completed"
+	activityCancelled  = "cancelled"
+	activityReopened   = "reopened"
+	activityDue        = "due"
+	activityPriority   = "priority"
+	activityAssigned   = "assigned"
+	activityUnassigned = "unassigned"
+	activityAccepted   = "accepted"
+	activityDeclined   = "declined"
+)
+
+// maxFeedEntries is how many activity entries a task card shows
+const maxFeedEntries = 15
+
+// maxFeedComment is how many characters of a comment a task card shows
+const maxFeedComment = 300
+
+// taskStatusIcon returns the icon lists show for a task status
+func taskStatusIcon(status string) string {
+	switch status {
+	case "completed":
+		return "✅"
+	case "cancelled":
+		return "🗑️"
+	}
+	return "🔴"
+}
+
+// listedTodo gets a task by its number in the sender's /list, or in a group
+// the group's /list. It returns a nil todo after telling the sender when
+// there is no such task.
+func (b *Bot) listedTodo(message *tgbotapi.Message, c *Catalog, numArg string) (*Todo, *User, error) {
+	taskNum, err := strconv.Atoi(strings.TrimSpace(numArg))
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.invalid_number"))
+		_, err := b.api.Send(msg)
+		return nil, nil, err
+	}
+
+	if isGroupChat(message.Chat) {
+		todos, err := b.pendingChatTodos(message.Chat.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get todos: %w", err)
+		}
+		if taskNum < 1 || taskNum > len(todos) {
+			msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.not_found", "max", len(todos)))
+			_, err := b.api.Send(msg)
+			return nil, nil, err
+		}
+		return b.groupTodo(message.Chat.ID, message.From, todos[taskNum-1].ID.String())
+	}
+
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return nil, nil, err
+	}
+
+	todos, err := b.db.GetUserTodos(user.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get todos: %w", err)
+	}
+	if taskNum < 1 || taskNum > len(todos) {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.not_found", "max", len(todos)))
+		_, err := b.api.Send(msg)
+		return nil, nil, err
+	}
+
+	return &todos[taskNum-1], user, nil
+}
+
+// handleTask handles the /task command, showing a task with its activity
+func (b *Bot) handleTask(message *tgbotapi.Message) error {
+	c := b.catalog(message.From.ID)
+	args := strings.TrimSpace(message.CommandArguments())
+	if args == "" {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("activity.task_usage"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	todo, user, err := b.listedTodo(message, c, args)
+	if todo == nil {
+		return err
+	}
+	return b.sendTaskCard(message.Chat.ID, todo, user)
+}
+
+// sendTaskCard sends a new card of a task
+func (b *Bot) sendTaskCard(chatID int64, todo *Todo, user *User) error {
+	text, keyboard, err := b.taskCard(todo, user)
+	if err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = keyboard
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// taskCard renders a task and its latest activity for a user, with buttons
+// to refresh the card and to watch or unwatch the task. The buttons also
+// tell replies to the card which task they comment on.
+func (b *Bot) taskCard(todo *Todo, user *User) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	c := userCatalog(user)
+	tf := userTimeFormat(user)
+	names := map[uuid.UUID]string{}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("%s <b>%s</b>\n", taskStatusIcon(todo.Status), html.EscapeString(todo.Title)))
+	if todo.Description != nil && *todo.Description != "" {
+		text.WriteString(html.EscapeString(*todo.Description) + "\n")
+	}
+	text.WriteString("\n" + c.T("priority."+todo.Priority) + "\n")
+	if todo.DueTime != nil {
+		text.WriteString(c.T("list.due", "time", tf.Time(*todo.DueTime)) + "\n")
+	}
+	if note := b.sharingNote(c, todo, user, names); note != "" {
+		text.WriteString(note + "\n")
+	}
+
+	entries, err := b.db.GetTaskActivity(todo.ID, maxFeedEntries+1)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to get task activity: %w", err)
+	}
+
+	text.WriteString("\n" + c.T("activity.title") + "\n")
+	if len(entries) > maxFeedEntries {
+		entries = entries[1:]
+		text.WriteString(c.T("activity.earlier") + "\n")
+	} else {
+		created := c.T("activity.created", "name", html.EscapeString(b.creatorName(c, todo, names)))
+		text.WriteString(c.T("activity.line", "time", tf.Time(todo.CreatedAt), "entry", created) + "\n")
+	}
+	for _, entry := range entries {
+		text.WriteString(c.T("activity.line",
+			"time", tf.Time(entry.CreatedAt),
+			"entry", b.activityEntry(c, tf, &entry, names, maxFeedComment)) + "\n")
+	}
+	text.WriteString("\n" + c.T("activity.reply_hint"))
+
+	watchButton := tgbotapi.NewInlineKeyboardButtonData(c.T("activity.watch"), fmt.Sprintf("watch:%s", todo.ID))
+	if b.isWatching(todo, user) {
+		watchButton = tgbotapi.NewInlineKeyboardButtonData(c.T("activity.unwatch"), fmt.Sprintf("unwatch:%s", todo.ID))
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("activity.refresh"), fmt.Sprintf("task:%s", todo.ID)),
+			watchButton,
+		),
+	)
+	return text.String(), keyboard, nil
+}
+
+// activityEntry describes an activity entry for a user, cutting comments
+// longer than maxComment characters short when maxComment is positive
+func (b *Bot) activityEntry(c *Catalog, tf timeFormat, entry *TaskActivity, names map[uuid.UUID]string, maxComment int) string {
+	name := html.EscapeString(b.cachedUserName(entry.UserID, names))
+	body := ""
+	if entry.Body != nil {
+		body = *entry.Body
+	}
+
+	switch entry.Kind {
+	case activityComment:
+		if runes := []rune(body); maxComment > 0 && len(runes) > maxComment {
+			body = string(runes[:maxComment]) + "…"
+		}
+		return c.T("activity.comment", "name", name, "text", html.EscapeString(body))
+	case activityDue:
+		due, err := time.Parse(time.RFC3339, body)
+		if err != nil {
+			return c.T("activity.due_removed", "name", name)
+		}
+		return c.T("activity.due", "name", name, "time", tf.Time(due))
+	case activityPriority:
+		return c.T("activity.priority", "name", name, "priority", c.T("priority."+body))
+	case activityAssigned:
+		assignee := ""
+		if assigneeID, err := uuid.Parse(body); err == nil {
+			assignee = b.cachedUserName(assigneeID, names)
+		}
+		return c.T("activity.assigned", "name", name, "assignee", html.EscapeString(assignee))
+	}
+	return c.T("activity."+entry.Kind, "name", name)
+}
+
+// isWatching reports whether a user watches a task
+func (b *Bot) isWatching(todo *Todo, user *User) bool {
+	watchers, err := b.db.GetTaskWatchers(todo.ID)
+	if err != nil {
+		log.Printf("Failed to get watchers of todo %s: %v", todo.ID, err)
+		return false
+	}
+	for _, watcher := range watchers {
+		if watcher.ID == user.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// recordActivity adds a change to a task's feed and tells its watchers,
+// except the actor and the people in told who already heard about it
+func (b *Bot) recordActivity(todo *Todo, actor *User, kind string, body *string, told ...uuid.UUID) {
+	entry, err := b.db.CreateTaskActivity(NewTaskActivity{
+		TodoID: todo.ID,
+		UserID: actor.ID,
+		Kind:   kind,
+		Body:   body,
+	})
+	if err != nil {
+		log.Printf("Failed to record %s activity on todo %s: %v", kind, todo.ID, err)
+		return
+	}
+	b.notifyWatchers(todo, actor, entry, told)
+}
+
+// notifyWatchers tells the watchers of a task who can still see it about
+// new activity, with a button to open the task
+func (b *Bot) notifyWatchers(todo *Todo, actor *User, entry *TaskActivity, told []uuid.UUID) {
+	watchers, err := b.db.GetTaskWatchers(todo.ID)
+	if err != nil {
+		log.Printf("Failed to get watchers of todo %s: %v", todo.ID, err)
+		return
+	}
+
+	names := map[uuid.UUID]string{actor.ID: userDisplayName(actor)}
+	for _, watcher := range watchers {
+		if watcher.ID == actor.ID || containsUUID(told, watcher.ID) || !b.canViewTodo(todo, &watcher) {
+			continue
+		}
+
+		c := userCatalog(&watcher)
+		msg := tgbotapi.NewMessage(watcher.TelegramID, c.T("activity.notify",
+			"title", html.EscapeString(todo.Title),
+			"entry", b.activityEntry(c, userTimeFormat(&watcher), entry, names, 0)))
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(c.T("activity.open"), fmt.Sprintf("task:%s:new", todo.ID)),
+			),
+		)
+		if _, err := b.api.Send(msg); err != nil {
+			log.Printf("Failed to tell %d about activity on todo %s: %v", watcher.TelegramID, todo.ID, err)
+		}
+	}
+}
+
+// chatViewableTodo loads a task by ID for whoever wrote a message or pressed
+// a button: in a group one of the group's tasks, elsewhere a task the user
+// may view
+func (b *Bot) chatViewableTodo(chat *tgbotapi.Chat, from *tgbotapi.User, todoIDStr string) (*Todo, *User, error) {
+	if isGroupChat(chat) {
+		return b.groupTodo(chat.ID, from, todoIDStr)
+	}
+	return b.viewableTodo(from.ID, todoIDStr)
+}
+
+// containsUUID reports whether ids contains id
+func containsUUID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
+
+// handleComment handles the /comment command
+func (b *Bot) handleComment(message *tgbotapi.Message) error {
+	c := b.catalog(message.From.ID)
+	numArg, text, _ := strings.Cut(strings.TrimSpace(message.CommandArguments()), " ")
+	if strings.TrimSpace(text) == "" {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("activity.comment_usage"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	todo, user, err := b.listedTodo(message, c, numArg)
+	if todo == nil {
+		return err
+	}
+	return b.addComment(message.Chat.ID, c, todo, user, text)
+}
+
+// repliedTaskID returns the task a message replies to, when it replies to
+// a task card or activity notice
+func (b *Bot) repliedTaskID(message *tgbotapi.Message) (string, bool) {
+	reply := message.ReplyToMessage
+	if reply == nil || reply.From == nil || reply.From.ID != b.api.Self.ID || reply.ReplyMarkup == nil {
+		return "", false
+	}
+	for _, row := range reply.ReplyMarkup.InlineKeyboard {
+		for _, button := range row {
+			if button.CallbackData == nil {
+				continue
+			}
+			if rest, ok := strings.CutPrefix(*button.CallbackData, "task:"); ok {
+				todoID, _, _ := strings.Cut(rest, ":")
+				return todoID, true
+			}
+		}
+	}
+	return "", false
+}
+
+// handleCommentReply comments on a task with a reply to its card or to an
+// activity notice
+func (b *Bot) handleCommentReply(message *tgbotapi.Message, todoIDStr string) error {
+	c := b.catalog(message.From.ID)
+	todo, user, err := b.chatViewableTodo(message.Chat, message.From, todoIDStr)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.not_found_short"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	return b.addComment(message.Chat.ID, c, todo, user, message.Text)
+}
+
+// addComment adds a comment to a task, makes the commenter watch it and
+// tells the other watchers
+func (b *Bot) addComment(chatID int64, c *Catalog, todo *Todo, user *User, text string) error {
+	text = strings.TrimSpace(text)
+	entry, err := b.db.CreateTaskActivity(NewTaskActivity{
+		TodoID: todo.ID,
+		UserID: user.ID,
+		Kind:   activityComment,
+		Body:   &text,
+	})
+	if err != nil {
+		log.Printf("Failed to comment on todo %s: %v", todo.ID, err)
+		msg := tgbotapi.NewMessage(chatID, c.T("activity.comment_failed"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	if err := b.db.AddTaskWatcher(todo.ID, user.ID); err != nil {
+		log.Printf("Failed to make %s watch todo %s: %v", user.ID, todo.ID, err)
+	}
+	b.notifyWatchers(todo, user, entry, nil)
+
+	msg := tgbotapi.NewMessage(chatID, c.T("activity.commented", "title", html.EscapeString(todo.Title)))
+	msg.ParseMode = "HTML"
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleWatch handles the /watch command
+func (b *Bot) handleWatch(message *tgbotapi.Message) error {
+	return b.setWatching(message, true)
+}
+
+// handleUnwatch handles the /unwatch command
+func (b *Bot) handleUnwatch(message *tgbotapi.Message) error {
+	return b.setWatching(message, false)
+}
+
+// setWatching starts or stops watching a task by its number in /list
+func (b *Bot) setWatching(message *tgbotapi.Message, watching bool) error {
+	c := b.catalog(message.From.ID)
+	args := strings.TrimSpace(message.CommandArguments())
+	if args == "" {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("activity.watch_usage"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	todo, user, err := b.listedTodo(message, c, args)
+	if todo == nil {
+		return err
+	}
+
+	if err := b.db.SetTaskWatching(todo.ID, user.ID, watching); err != nil {
+		log.Printf("Failed to set watching of todo %s: %v", todo.ID, err)
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.update_failed"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	key := "activity.unwatched"
+	if watching {
+		key = "activity.watching"
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, c.T(key, "title", html.EscapeString(todo.Title)))
+	msg.ParseMode = "HTML"
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleTaskCallback handles the buttons of task cards and activity
+// notices: task refreshes a card, or with "new" sends one, while watch and
+// unwatch change whether the user watches the task
+func (b *Bot) handleTaskCallback(callback *tgbotapi.CallbackQuery, action, todoIDStr, arg string) error {
+	c := b.catalog(callback.From.ID)
+	todo, user, err := b.chatViewableTodo(callback.Message.Chat, callback.From, todoIDStr)
+	if err != nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            c.T("task.not_found_short"),
+		})
+		return err
+	}
+
+	var answer string
+	if action == "watch" || action == "unwatch" {
+		watching := action == "watch"
+		if err := b.db.SetTaskWatching(todo.ID, user.ID, watching); err != nil {
+			log.Printf("Failed to set watching of todo %s: %v", todo.ID, err)
+			_, err := b.api.Request(tgbotapi.CallbackConfig{
+				CallbackQueryID: callback.ID,
+				Text:            c.T("task.update_failed"),
+			})
+			return err
+		}
+		answer = c.T("activity.unwatched_short")
+		if watching {
+			answer = c.T("activity.watching_short")
+		}
+	}
+
+	if arg == "new" {
+		if err := b.sendTaskCard(callback.Message.Chat.ID, todo, user); err != nil {
+			log.Printf("Failed to send task card: %v", err)
+		}
+	} else {
+		text, keyboard, err := b.taskCard(todo, user)
+		if err != nil {
+			return err
+		}
+		edit := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID, text, keyboard)
+		edit.ParseMode = "HTML"
+		if _, err := b.api.Send(edit); err != nil {
+			log.Printf("Failed to refresh task card: %v", err)
+		}
+	}
+
+	_, err = b.api.Request(tgbotapi.CallbackConfig{
+		CallbackQueryID: callback.ID,
+		Text:            answer,
+	})
+	return err
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestActivityEntryPriority(t *testing.T) {
+	if err := loadCatalogs(); err != nil {
+		t.Fatalf("loadCatalogs error: %v", err)
+	}
+	b := &Bot{}
+	userID := uuid.New()
+	names := map[uuid.UUID]string{userID: "Ann <Ops>"}
+	tf := userTimeFormat(&User{Timezone: "UTC"})
+
+	// /priority records the new priority as the entry body
+	priority := "high"
+	entry := &TaskActivity{UserID: userID, Kind: activityPriority, Body: &priority}
+
+	want := "🎯 Ann &lt;Ops&gt; set the priority to 🔴 High"
+	if got := b.activityEntry(catalogFor("en"), tf, entry, names, 0); got != want {
+		t.Errorf("activityEntry = %q, want %q", got, want)
+	}
+}
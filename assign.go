@@ -0,0 +1,509 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+)
+
+// A task's owner can assign it to another bot user with /assign. The
+// assignee answers a private message with asg:<todo>:accept or :decline
+// buttons, and the owner hears about the answer and about completion.
+// Assigned tasks appear in the assignee's list, numbered along with their
+// own tasks. The assignee may complete and reopen the task but not change
+// it. Group tasks can be assigned by anyone in the group.
+
+// Assignment states of a task with an assignee
+const (
+	assignmentPending  = "pending"
+	assignmentAccepted = "accepted"
+)
+
+// maxAssignChoices is how many people /assign offers as buttons
+const maxAssignChoices = 8
+
+// ownsTodo reports whether a user owns a task
+func ownsTodo(todo *Todo, user *User) bool {
+	return todo.UserID == user.ID
+}
+
+// canEditTodo reports whether a user may change a task: its owner and the
+// editors of the owner's shared list may
+func (b *Bot) canEditTodo(todo *Todo, user *User) bool {
+	if ownsTodo(todo, user) {
+		return true
+	}
+	return todo.ChatID == nil && b.listRole(todo.UserID, user.ID) == roleEditor
+}
+
+// canCompleteTodo reports whether a user may complete or reopen a task
+func (b *Bot) canCompleteTodo(todo *Todo, user *User) bool {
+	return isAssignee(todo, user) || b.canEditTodo(todo, user)
+}
+
+// canViewTodo reports whether a user may see a task and its activity: its
+// owner, its assignee, the members of the owner's shared list and, for
+// group tasks, the people in the group
+func (b *Bot) canViewTodo(todo *Todo, user *User) bool {
+	if ownsTodo(todo, user) || isAssignee(todo, user) {
+		return true
+	}
+	if todo.ChatID != nil {
+		return b.inChat(*todo.ChatID, user)
+	}
+	return b.listRole(todo.UserID, user.ID) != ""
+}
+
+// inChat reports whether a user has been active in a group chat
+func (b *Bot) inChat(chatID int64, user *User) bool {
+	member, err := b.db.IsChatMember(chatID, user.ID)
+	if err != nil {
+		log.Printf("Failed to check whether %s is in chat %d: %v", user.ID, chatID, err)
+	}
+	return member
+}
+
+// isAssignee reports whether a task is assigned to a user
+func isAssignee(todo *Todo, user *User) bool {
+	return todo.AssigneeID != nil && *todo.AssigneeID == user.ID
+}
+
+// userDisplayName names a user for other people, falling back to their
+// @username
+func userDisplayName(user *User) string {
+	name := strings.TrimSpace(user.Name)
+	if name == "" && user.Username != nil {
+		name = "@" + *user.Username
+	}
+	return name
+}
+
+// cachedUserName returns a user's display name, looking each user up once
+// per message
+func (b *Bot) cachedUserName(userID uuid.UUID, names map[uuid.UUID]string) string {
+	name, ok := names[userID]
+	if !ok {
+		if user, err := b.db.GetUserByID(userID); err == nil && user != nil {
+			name = userDisplayName(user)
+		}
+		names[userID] = name
+	}
+	return name
+}
+
+// rememberSender keeps the sender's @username current and records who is
+// active in group chats, so /assign can find people
+func (b *Bot) rememberSender(message *tgbotapi.Message) {
+	if message.From == nil {
+		return
+	}
+	if err := b.db.UpdateUsername(message.From.ID, message.From.UserName); err != nil {
+		log.Printf("Failed to update username of %d: %v", message.From.ID, err)
+	}
+	if isGroupChat(message.Chat) {
+		if err := b.db.TouchChatMember(message.Chat.ID, message.From.ID); err != nil {
+			log.Printf("Failed to record member of chat %d: %v", message.Chat.ID, err)
+		}
+	}
+}
+
+// sharingNote describes whose a task is as seen by a user: whose shared
+// list it comes from for list members, who it is assigned to for the owner,
+// and who it is from for the assignee. A nil user, as in group lists,
+// always sees the assignee.
+func (b *Bot) sharingNote(c *Catalog, todo *Todo, user *User, names map[uuid.UUID]string) string {
+	if user != nil && !ownsTodo(todo, user) && !isAssignee(todo, user) {
+		return c.T("share.marker", "name", html.EscapeString(b.cachedUserName(todo.UserID, names)))
+	}
+	if todo.AssigneeID == nil {
+		return ""
+	}
+	pending := todo.AssignmentStatus != nil && *todo.AssignmentStatus == assignmentPending
+
+	key, person := "assign.marker_to", *todo.AssigneeID
+	if user != nil && !ownsTodo(todo, user) {
+		key, person = "assign.marker_from", todo.UserID
+	}
+	if pending {
+		key += "_pending"
+	}
+	return c.T(key, "name", html.EscapeString(b.cachedUserName(person, names)))
+}
+
+// sendNotAllowed tells a user that a task in their list is not theirs to change
+func (b *Bot) sendNotAllowed(chatID int64, c *Catalog, todo *Todo) error {
+	msg := tgbotapi.NewMessage(chatID, c.T("task.not_allowed", "title", html.EscapeString(todo.Title)))
+	msg.ParseMode = "HTML"
+	_, err := b.api.Send(msg)
+	return err
+}
+
+// handleAssign handles the /assign command
+func (b *Bot) handleAssign(message *tgbotapi.Message) error {
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	todos, err := b.db.GetUserTodos(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get todos: %w", err)
+	}
+
+	return b.assignTask(message, userCatalog(user), user, todos, func() ([]User, error) {
+		return b.db.GetRecentContacts(user.ID, maxAssignChoices)
+	})
+}
+
+// handleGroupAssign assigns a group task by its number in /list
+func (b *Bot) handleGroupAssign(message *tgbotapi.Message) error {
+	user, err := b.groupMember(message.From)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	todos, err := b.pendingChatTodos(message.Chat.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get todos: %w", err)
+	}
+
+	return b.assignTask(message, b.senderCatalog(message.From), user, todos, func() ([]User, error) {
+		return b.db.GetChatMembers(message.Chat.ID, maxAssignChoices)
+	})
+}
+
+// assignTask carries out /assign <n> [@username|off] against the caller's
+// numbered tasks. Without a username it offers people to pick from.
+func (b *Bot) assignTask(message *tgbotapi.Message, c *Catalog, user *User, todos []Todo, candidates func() ([]User, error)) error {
+	fields := strings.Fields(message.CommandArguments())
+	if len(fields) == 0 || len(fields) > 2 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("assign.usage"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	taskNum, err := strconv.Atoi(fields[0])
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.invalid_number"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	if taskNum < 1 || taskNum > len(todos) {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.not_found", "max", len(todos)))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	todo := todos[taskNum-1]
+
+	if todo.ChatID == nil && !ownsTodo(&todo, user) {
+		return b.sendNotAllowed(message.Chat.ID, c, &todo)
+	}
+	if todo.Status != "pending" {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("assign.not_pending", "title", html.EscapeString(todo.Title)))
+		msg.ParseMode = "HTML"
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	if len(fields) == 1 {
+		people, err := candidates()
+		if err != nil {
+			return fmt.Errorf("failed to get people to assign to: %w", err)
+		}
+		return b.sendAssignChoices(message.Chat.ID, c, &todo, taskNum, people)
+	}
+
+	if strings.EqualFold(fields[1], "off") {
+		return b.unassignTodo(message.Chat.ID, c, &todo, user)
+	}
+
+	username := strings.TrimPrefix(fields[1], "@")
+	assignee, err := b.db.GetUserByUsername(username)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if assignee == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("assign.unknown_user", "username", html.EscapeString(username)))
+		msg.ParseMode = "HTML"
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	return b.assignTodo(message.Chat.ID, c, &todo, user, assignee)
+}
+
+// sendAssignChoices offers recently active people as buttons
+func (b *Bot) sendAssignChoices(chatID int64, c *Catalog, todo *Todo, taskNum int, people []User) error {
+	if len(people) == 0 {
+		msg := tgbotapi.NewMessage(chatID, c.T("assign.no_members", "number", taskNum))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, person := range people {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(userDisplayName(&person), fmt.Sprintf("asgto:%s:%d", todo.ID, person.TelegramID)),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, c.T("assign.pick", "title", html.EscapeString(todo.Title)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+	_, err := b.api.Send(msg)
+	return err
+}
+
+// assignTodo assigns a task and asks the assignee to accept it. People
+// taking a task themselves need not answer.
+func (b *Bot) assignTodo(chatID int64, c *Catalog, todo *Todo, assigner, assignee *User) error {
+	if todo.ChatID == nil && assignee.ID == todo.UserID {
+		msg := tgbotapi.NewMessage(chatID, c.T("assign.self"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	status := assignmentPending
+	if assignee.ID == assigner.ID {
+		status = assignmentAccepted
+	}
+	updated, err := b.db.AssignTodo(todo.ID, &assignee.ID, &status)
+	if err != nil {
+		log.Printf("Failed to assign todo %s: %v", todo.ID, err)
+		msg := tgbotapi.NewMessage(chatID, c.T("assign.failed"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	// The assignee hears about it from the request below or did it themselves
+	b.recordActivity(updated, assigner, activityAssigned, stringPtr(assignee.ID.String()), assignee.ID)
+
+	name := html.EscapeString(userDisplayName(assignee))
+	title := html.EscapeString(updated.Title)
+	text := c.T("assign.taken", "name", name, "title", title)
+	if status == assignmentPending {
+		text = c.T("assign.sent", "name", name, "title", title)
+		if err := b.requestAssignment(updated, assigner, assignee); err != nil {
+			log.Printf("Failed to send assignment of todo %s to %d: %v", updated.ID, assignee.TelegramID, err)
+			text = c.T("assign.unreachable", "name", name, "title", title, "bot", b.api.Self.UserName)
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// requestAssignment asks the assignee in a private message to accept a task
+func (b *Bot) requestAssignment(todo *Todo, assigner, assignee *User) error {
+	c := userCatalog(assignee)
+	text := c.T("assign.request",
+		"name", html.EscapeString(userDisplayName(assigner)),
+		"title", html.EscapeString(todo.Title))
+	if todo.Description != nil {
+		text += "\n" + html.EscapeString(*todo.Description)
+	}
+	if todo.DueTime != nil {
+		text += "\n\n" + c.T("list.due", "time", userTimeFormat(assignee).Time(*todo.DueTime))
+	}
+
+	msg := tgbotapi.NewMessage(assignee.TelegramID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("assign.accept"), fmt.Sprintf("asg:%s:accept", todo.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("assign.decline"), fmt.Sprintf("asg:%s:decline", todo.ID)),
+		),
+	)
+	_, err := b.api.Send(msg)
+	return err
+}
+
+// unassignTodo takes a task back from its assignee and lets them know
+func (b *Bot) unassignTodo(chatID int64, c *Catalog, todo *Todo, user *User) error {
+	updated, err := b.db.AssignTodo(todo.ID, nil, nil)
+	if err != nil {
+		log.Printf("Failed to unassign todo %s: %v", todo.ID, err)
+		msg := tgbotapi.NewMessage(chatID, c.T("assign.failed"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	var told []uuid.UUID
+	if todo.AssigneeID != nil {
+		told = append(told, *todo.AssigneeID)
+	}
+	b.recordActivity(updated, user, activityUnassigned, nil, told...)
+
+	if todo.AssigneeID != nil && *todo.AssigneeID != user.ID {
+		if former, err := b.db.GetUserByID(*todo.AssigneeID); err == nil && former != nil {
+			notice := tgbotapi.NewMessage(former.TelegramID, userCatalog(former).T("assign.withdrawn",
+				"name", html.EscapeString(userDisplayName(user)),
+				"title", html.EscapeString(todo.Title)))
+			notice.ParseMode = "HTML"
+			if _, err := b.api.Send(notice); err != nil {
+				log.Printf("Failed to tell %d about unassigned todo %s: %v", former.TelegramID, todo.ID, err)
+			}
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, c.T("assign.removed", "title", html.EscapeString(updated.Title)))
+	msg.ParseMode = "HTML"
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleAssignToCallback assigns a task to the person picked from the
+// buttons of /assign in a private chat
+func (b *Bot) handleAssignToCallback(callback *tgbotapi.CallbackQuery, todoIDStr, telegramIDStr string) error {
+	c := b.catalog(callback.From.ID)
+	todo, user, err := b.ownTodo(callback.From.ID, todoIDStr)
+	if err != nil || todo.Status != "pending" {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            c.T("task.not_found_short"),
+		})
+		return err
+	}
+	return b.assignFromButton(callback, c, todo, user, telegramIDStr)
+}
+
+// handleGroupAssignCallback assigns a group task to the person picked from
+// the buttons of /assign
+func (b *Bot) handleGroupAssignCallback(callback *tgbotapi.CallbackQuery, todoIDStr, telegramIDStr string) error {
+	c := b.senderCatalog(callback.From)
+	todo, err := b.chatTodo(callback.Message.Chat.ID, todoIDStr)
+	if err != nil || todo.Status != "pending" {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            c.T("task.not_found_short"),
+		})
+		return err
+	}
+
+	user, err := b.groupMember(callback.From)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	return b.assignFromButton(callback, c, todo, user, telegramIDStr)
+}
+
+// assignFromButton assigns a task to the person behind a picked button
+func (b *Bot) assignFromButton(callback *tgbotapi.CallbackQuery, c *Catalog, todo *Todo, user *User, telegramIDStr string) error {
+	var assignee *User
+	if telegramID, err := strconv.ParseInt(telegramIDStr, 10, 64); err == nil {
+		assignee, err = b.db.GetUserByTelegramID(telegramID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+	}
+	if assignee == nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+		})
+		return err
+	}
+
+	// The choice is made, so drop the buttons
+	edit := tgbotapi.NewEditMessageReplyMarkup(callback.Message.Chat.ID, callback.Message.MessageID,
+		tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}})
+	if _, err := b.api.Send(edit); err != nil {
+		log.Printf("Failed to remove assign buttons: %v", err)
+	}
+
+	if _, err := b.api.Request(tgbotapi.CallbackConfig{CallbackQueryID: callback.ID}); err != nil {
+		log.Printf("Failed to answer assign callback: %v", err)
+	}
+	return b.assignTodo(callback.Message.Chat.ID, c, todo, user, assignee)
+}
+
+// handleAssignmentCallback handles the assignee's Accept and Decline buttons
+func (b *Bot) handleAssignmentCallback(callback *tgbotapi.CallbackQuery, todoIDStr, answer string) error {
+	c := b.catalog(callback.From.ID)
+	user, err := b.db.GetUserByTelegramID(callback.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	var todo *Todo
+	if todoID, err := uuid.Parse(todoIDStr); err == nil && user != nil {
+		todo, _ = b.db.GetTodoByID(todoID)
+	}
+	if todo == nil || !isAssignee(todo, user) || todo.Status != "pending" {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            c.T("task.not_found_short"),
+		})
+		return err
+	}
+
+	var result string
+	switch answer {
+	case "accept":
+		status := assignmentAccepted
+		_, err = b.db.AssignTodo(todo.ID, todo.AssigneeID, &status)
+		result = activityAccepted
+	case "decline":
+		_, err = b.db.AssignTodo(todo.ID, nil, nil)
+		result = activityDeclined
+	default:
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+		})
+		return err
+	}
+	if err != nil {
+		log.Printf("Failed to answer assignment of todo %s: %v", todo.ID, err)
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            c.T("task.update_failed"),
+		})
+		return err
+	}
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID,
+		c.T("assign.you_"+result, "title", html.EscapeString(todo.Title)))
+	edit.ParseMode = "HTML"
+	if _, err := b.api.Send(edit); err != nil {
+		log.Printf("Failed to update assignment message: %v", err)
+	}
+
+	b.notifyOwner(todo, user, "assign."+result)
+	b.recordActivity(todo, user, result, nil, todo.UserID)
+
+	_, err = b.api.Request(tgbotapi.CallbackConfig{
+		CallbackQueryID: callback.ID,
+	})
+	return err
+}
+
+// notifyOwner tells a task's owner what its assignee did with it
+func (b *Bot) notifyOwner(todo *Todo, assignee *User, key string) {
+	if ownsTodo(todo, assignee) || todo.UserID == uuid.Nil {
+		return
+	}
+	owner, err := b.db.GetUserByID(todo.UserID)
+	if err != nil || owner == nil {
+		log.Printf("Failed to get owner of todo %s: %v", todo.ID, err)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(owner.TelegramID, userCatalog(owner).T(key,
+		"name", html.EscapeString(userDisplayName(assignee)),
+		"title", html.EscapeString(todo.Title)))
+	msg.ParseMode = "HTML"
+	if _, err := b.api.Send(msg); err != nil {
+		log.Printf("Failed to notify owner of todo %s: %v", todo.ID, err)
+	}
+}
@@ -1,13 +1,14 @@
 package main
 
 import (
-	"context"
 	"fmt"
+	"html"
 	"log"
 	"os"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,212 +19,13 @@ import (
 	"github.com/shirou/gopsutil/mem"
 )
 
-// Language constants
-const (
-	LangEN = "en"
-	LangTH = "th"
-)
-
-// Translation structure
-type Translation struct {
-	Welcome          string
-	MainMenu         string
-	MyTasks          string
-	Statistics       string
-	AddTask          string
-	Reminders        string
-	ServerStats      string
-	Help             string
-	Settings         string
-	Snooze           string
-	YourTodos        string
-	TaskCompleted    string
-	TaskDeleted      string
-	ReminderSet      string
-	InvalidTaskID    string
-	TaskNotFound     string
-	Language         string
-	CurrentLanguage  string
-	LanguageChanged  string
-	HelpText         string
-	NoTasks          string
-	ReminderOptions  string
-	TimeFormats      string
-	Examples         string
-	ProTips          string
-	CurrentReminders string
-}
-
-// Translations map
-var translations = map[string]Translation{
-	LangEN: {
-		Welcome:         "👋 Welcome to Todo Bot!\n\nI'll help you manage your tasks efficiently.",
-		MainMenu:        "🏠 <b>Main Menu</b>\n\nChoose an option below:",
-		MyTasks:         "📋 My Tasks",
-		Statistics:       "📊 Statistics",
-		AddTask:         "➕ Add Task",
-		Reminders:        "⏰ Reminders",
-		ServerStats:      "🖥️ Server Stats",
-		Help:            "❓ Help",
-		Settings:        "⚙️ Settings",
-		Snooze:          "😴 Snooze",
-		YourTodos:       "📋 <b>Your Todos:</b>",
-		TaskCompleted:   "✅ Task completed successfully!\n\n<b>%s</b>",
-		TaskDeleted:     "🗑️ Task deleted successfully!",
-		ReminderSet:     "⏰ Reminder set successfully!\n\nI'll remind you in %s\n\n📅 %s",
-		InvalidTaskID:    "Invalid task ID. Please use a number like 1, 2, 3...",
-		TaskNotFound:     "Task not found. Please use a number between 1 and %d",
-		Language:         "🌐 Language",
-		CurrentLanguage:  "Current language: %s",
-		LanguageChanged:  "Language changed to %s!",
-		HelpText:         `🤖 <b>Todo Bot Help</b>
-
-📝 <b>Task Management:</b>
-• /add &lt;title&gt; [description] - Create a new task
-• /list - View all your tasks
-• /stats - View your task statistics
-
-🔧 <b>Task Actions:</b>
-• /complete &lt;id&gt; - Mark a task as completed
-• /delete &lt;id&gt; - Delete a task
-
-⏰ <b>Reminders:</b>
-• /remind &lt;id&gt; &lt;time&gt; - Set a reminder for a task
-• /snooze &lt;id&gt; &lt;time&gt; - Snooze a reminder
-• /reminders - View all reminder options
-
-📊 <b>Examples:</b>
-• /add Buy groceries
-• /add Meeting with John at 3pm
-• /complete 1
-• /remind 1 2h
-• /remind 1 1d (every day)
-• /remind 1 1h (every hour)
-• /snooze 1 30m
-
-⚙️ <b>Settings:</b>
-• /start - Main menu
-• /help - Show this help message`,
-		NoTasks:         "You don't have any todos yet. Use /add to create one!",
-		ReminderOptions:  `⏰ <b>Reminder Options</b>
-
-<b>Time Formats:</b>
-• 1h - 1 hour
-• 2h - 2 hours
-• 30m - 30 minutes
-• 1d - 1 day (repeats daily)
-• 1w - 1 week (repeats weekly)
-• 1h - 1 hour (repeats hourly)
-
-<b>Examples:</b>
-• /remind 1 30m - Remind in 30 minutes
-• /remind 2 1h - Remind every hour
-• /remind 3 1d - Remind every day
-• /remind 4 1w - Remind every week
-
-<b>Pro Tips:</b>
-• Use daily reminders for habits
-• Use hourly reminders for urgent tasks
-• Use weekly reminders for goals
-
-<b>Your Current Reminders:</b>
-Use /list to see all tasks and their reminders`,
-		TimeFormats:      `<b>Time Formats:</b>`,
-		Examples:         `<b>Examples:</b>`,
-		ProTips:          `<b>Pro Tips:</b>`,
-		CurrentReminders: `<b>Your Current Reminders:</b>`,
-	},
-	LangTH: {
-		Welcome:         "👋 ยินดีต้อนรับสู่ Todo Bot!\n\nฉันจะช่วยคุณจัดการงานของคุณอย่างมีประสิทธิภาพ",
-		MainMenu:        "🏠 <b>เมนูหลัก</b>\n\nเลือกตัวเลือกด้านล่าง:",
-		MyTasks:         "📋 งานของฉัน",
-		Statistics:       "📊 สถิติ",
-		AddTask:         "➕ เพิ่มงาน",
-		Reminders:        "⏰ การแจ้งเตือน",
-		ServerStats:      "🖥️ สถิติเซิร์ฟเวอร์",
-		Help:            "❓ ความช่วยเหลือ",
-		Settings:        "⚙️ การตั้งค่า",
-		Snooze:          "😴 พักการแจ้งเตือน",
-		YourTodos:       "📋 <b>งานของคุณ:</b>",
-		TaskCompleted:   "✅ ทำงานเสร็จสิ้นแล้ว!\n\n<b>%s</b>",
-		TaskDeleted:     "🗑️ ลบงานเรียบร้อยแล้ว!",
-		ReminderSet:     "⏰ ตั้งการแจ้งเตือนเรียบร้อยแล้ว!\n\nฉันจะแจ้งเตือนในอีก %s\n\n📅 %s",
-		InvalidTaskID:    "รหัสงานไม่ถูกต้อง กรุณาใช้ตัวเลขเช่น 1, 2, 3...",
-		TaskNotFound:     "ไม่พบงาน กรุณาใช้ตัวเลขระหว่าง 1 ถึง %d",
-		Language:         "🌐 ภาษา",
-		CurrentLanguage:  "ภาษาปัจจุบัน: %s",
-		LanguageChanged:  "เปลี่ยนภาษาเป็น %s เรียบร้อยแล้ว!",
-		HelpText:         `🤖 <b>ความช่วยเหลือ Todo Bot</b>
-
-📝 <b>การจัดการงาน:</b>
-• /add &lt;ชื่องาน&gt; [คำอธิบาย] - สร้างงานใหม่
-• /list - ดูงานทั้งหมดของคุณ
-• /stats - ดูสถิติงานของคุณ
-
-🔧 <b>การกระทำงาน:</b>
-• /complete &lt;id&gt; - ทำเครื่องหมายว่างานเสร็จสิ้น
-• /delete &lt;id&gt; - ลบงาน
-
-⏰ <b>การแจ้งเตือน:</b>
-• /remind &lt;id&gt; &lt;เวลา&gt; - ตั้งการแจ้งเตือนสำหรับงาน
-• /snooze &lt;id&gt; &lt;เวลา&gt; - พักการแจ้งเตือน
-• /reminders - ดูตัวเลือกการแจ้งเตือนทั้งหมด
-
-📊 <b>ตัวอย่าง:</b>
-• /add ซื้อของ
-• /add นัดกับจอห์น 3โมงเย็น
-• /complete 1
-• /remind 1 2h
-• /remind 1 1d (ทุกวัน)
-• /remind 1 1h (ทุกชั่วโมง)
-• /snooze 1 30m
-
-⚙️ <b>การตั้งค่า:</b>
-• /start - เมนูหลัก
-• /help - แสดงข้อความช่วยเหลือนี้`,
-		NoTasks:         "คุณยังไม่มีงานใดๆ เลย ใช้ /add เพื่อสร้างงานแรกของคุณ!",
-		ReminderOptions:  `⏰ <b>ตัวเลือกการแจ้งเตือน</b>
-
-<b>รูปแบบเวลา:</b>
-• 1h - 1 ชั่วโมง
-• 2h - 2 ชั่วโมง
-• 30m - 30 นาที
-• 1d - 1 วัน (ทำซ้ำทุกวัน)
-• 1w - 1 สัปดาห์ (ทำซ้ำทุกสัปดาห์)
-• 1h - 1 ชั่วโมง (ทำซ้ำทุกชั่วโมง)
-
-<b>ตัวอย่าง:</b>
-• /remind 1 30m - แจ้งเตือนใน 30 นาที
-• /remind 2 1h - แจ้งเตือนทุกชั่วโมง
-• /remind 3 1d - แจ้งเตือนทุกวัน
-• /remind 4 1w - แจ้งเตือนทุกสัปดาห์
-
-<b>เคล็ดลับ:</b>
-• ใช้การแจ้งเตือนรายวันสำหรับนิสัย
-• ใช้การแจ้งเตือนทุกชั่วโมงสำหรับงานเร่งด่วน
-• ใช้การแจ้งเตือนรายสัปดาห์สำหรับเป้าหมาย
-
-<b>การแจ้งเตือนปัจจุบันของคุณ:</b>
-ใช้ /list เพื่อดูงานทั้งหมดและการแจ้งเตือน`,
-		TimeFormats:      `<b>รูปแบบเวลา:</b>`,
-		Examples:         `<b>ตัวอย่าง:</b>`,
-		ProTips:          `<b>เคล็ดลับ:</b>`,
-		CurrentReminders: `<b>การแจ้งเตือนปัจจุบันของคุณ:</b>`,
-	},
-}
-
-// getTranslation gets the translation for a user
-func (b *Bot) getTranslation(userID int64) Translation {
-	// Get user from database to check language preference
-	user, err := b.db.GetUserByTelegramID(userID)
-	if err != nil || user == nil || user.Language == "" {
-		return translations[LangEN] // Default to English
-	}
-	
-	if trans, exists := translations[user.Language]; exists {
-		return trans
+// catalog returns the message catalog for a Telegram user's language
+func (b *Bot) catalog(telegramID int64) *Catalog {
+	user, err := b.db.GetUserByTelegramID(telegramID)
+	if err != nil {
+		return catalogFor(defaultLanguage)
 	}
-	return translations[LangEN] // Default to English
+	return userCatalog(user)
 }
 
 // setUserLanguage sets the user's language preference
@@ -236,43 +38,41 @@ func (b *Bot) setUserLanguage(userID int64, language string) error {
 	return b.db.UpdateUserLanguage(user.ID, language)
 }
 
-// getUserTimezone gets the user's timezone and forces UTC to Asia/Bangkok
+// getUserTimezone gets the user's timezone, falling back to the default
+// when it is missing or invalid
 func (b *Bot) getUserTimezone(userID int64) string {
 	user, err := b.db.GetUserByTelegramID(userID)
-	if err != nil || user == nil || user.Timezone == "" || user.Timezone == "UTC" {
-		// Force update to Bangkok timezone if it's empty or UTC
-		if user != nil && user.Timezone == "UTC" {
-			b.db.UpdateUserSettings(user.ID, stringPtr("Asia/Bangkok"), nil, nil)
-		}
-		return "Asia/Bangkok"
+	if err != nil || user == nil || !validTimezone(user.Timezone) {
+		return defaultTimezone
 	}
 	return user.Timezone
 }
 
-// formatTimeForUser formats a time in the user's timezone
-func (b *Bot) formatTimeForUser(t time.Time, userID int64) string {
+// userLocation gets the time.Location for the user's timezone
+func (b *Bot) userLocation(userID int64) *time.Location {
 	timezone := b.getUserTimezone(userID)
-	
+
 	loc, err := time.LoadLocation(timezone)
 	if err != nil {
-		// Fallback to Bangkok if timezone is invalid
-		loc, _ = time.LoadLocation("Asia/Bangkok")
+		// Fallback to the default if timezone is invalid
+		loc, _ = time.LoadLocation(defaultTimezone)
 	}
-	
-	return t.In(loc).Format("2006-01-02 15:04")
+
+	return loc
+}
+
+// locationOf gets the time.Location for a loaded user's timezone, falling
+// back to the default when it is missing or invalid
+func locationOf(user *User) *time.Location {
+	if user == nil || !validTimezone(user.Timezone) {
+		return mustLoadLocation(defaultTimezone)
+	}
+	return mustLoadLocation(user.Timezone)
 }
 
 // nowInUserTimezone gets current time in user's timezone
 func (b *Bot) nowInUserTimezone(userID int64) time.Time {
-	timezone := b.getUserTimezone(userID)
-	
-	loc, err := time.LoadLocation(timezone)
-	if err != nil {
-		// Fallback to Bangkok if timezone is invalid
-		loc, _ = time.LoadLocation("Asia/Bangkok")
-	}
-	
-	return time.Now().In(loc)
+	return time.Now().In(b.userLocation(userID))
 }
 
 // stringPtr returns a pointer to a string
@@ -285,6 +85,21 @@ type Bot struct {
 	api      *tgbotapi.BotAPI
 	db       *Database
 	commands map[string]func(*tgbotapi.Message) error
+
+	// pending holds prompts waiting for a free-text answer, keyed by Telegram user ID
+	pending   map[int64]pendingInput
+	pendingMu sync.Mutex
+
+	// instanceID identifies this replica when claiming due reminders
+	instanceID string
+
+	// scheduler tracks when upcoming reminders fire
+	scheduler *reminderScheduler
+
+	// failedAttempts counts failed sends per occurrence, so retries back
+	// off and give up even while the delivery log cannot be written
+	failedAttempts   map[deliveryKey]int
+	failedAttemptsMu sync.Mutex
 }
 
 // NewBot creates a new bot instance
@@ -297,10 +112,18 @@ func NewBot(token string, db *Database) (*Bot, error) {
 	api.Debug = false
 	log.Printf("Authorized on account %s", api.Self.UserName)
 
+	hostname, _ := os.Hostname()
+
 	bot := &Bot{
-		api: api,
-		db:  db,
+		api:        api,
+		db:         db,
+		pending:    make(map[int64]pendingInput),
+		instanceID: fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.New().String()[:8]),
+		scheduler:  newReminderScheduler(),
+
+		failedAttempts: make(map[deliveryKey]int),
 	}
+	log.Printf("Reminder delivery instance ID: %s", bot.instanceID)
 
 	bot.setupCommands()
 	return bot, nil
@@ -309,17 +132,40 @@ func NewBot(token string, db *Database) (*Bot, error) {
 // setupCommands sets up the command handlers
 func (b *Bot) setupCommands() {
 	b.commands = map[string]func(*tgbotapi.Message) error{
-		"start":       b.handleStart,
-		"add":         b.handleAdd,
-		"list":        b.handleList,
-		"help":        b.handleHelp,
-		"stats":       b.handleStats,
-		"reminders":   b.handleReminders,
-		"serverstats": b.handleServerStats,
-		"delete":      b.handleDelete,
-		"complete":    b.handleComplete,
-		"remind":      b.handleRemind,
-		"snooze":      b.handleSnooze,
+		"start":         b.handleStart,
+		"add":           b.handleAdd,
+		"list":          b.handleList,
+		"help":          b.handleHelp,
+		"stats":         b.handleStats,
+		"reminders":     b.handleReminders,
+		"serverstats":   b.handleServerStats,
+		"delete":        b.handleDelete,
+		"complete":      b.handleComplete,
+		"remind":        b.handleRemind,
+		"snooze":        b.handleSnooze,
+		"snoozeoptions": b.handleSnoozeOptions,
+		"quiet":         b.handleQuiet,
+		"dnd":           b.handleDND,
+		"due":           b.handleDue,
+		"priority":      b.handlePriority,
+		"defaults":      b.handleDefaults,
+		"digest":        b.handleDigest,
+		"briefing":      b.handleBriefing,
+		"review":        b.handleReview,
+		"escalate":      b.handleEscalate,
+		"reopen":        b.handleReopen,
+		"recur":         b.handleRecur,
+		"timezone":      b.handleTimezone,
+		"settings":      b.handleSettingsCommand,
+		"exportme":      b.handleExportMe,
+		"deleteme":      b.handleDeleteMe,
+		"assign":        b.handleAssign,
+		"share":         b.handleShare,
+		"shared":        b.handleShared,
+		"task":          b.handleTask,
+		"comment":       b.handleComment,
+		"watch":         b.handleWatch,
+		"unwatch":       b.handleUnwatch,
 	}
 }
 
@@ -332,6 +178,7 @@ func (b *Bot) Start() error {
 
 	// Start reminder checker in background
 	go b.reminderChecker()
+	go b.dailyChecker()
 
 	for update := range updates {
 		if update.Message != nil {
@@ -352,6 +199,14 @@ func (b *Bot) Start() error {
 
 // handleMessage handles incoming messages
 func (b *Bot) handleMessage(message *tgbotapi.Message) error {
+	// Afterwards, so people registered by this message are recorded too
+	defer b.rememberSender(message)
+
+	// Groups share one task list and support only a few commands
+	if isGroupChat(message.Chat) {
+		return b.handleGroupMessage(message)
+	}
+
 	if message.IsCommand() {
 		command := message.Command()
 		if handler, exists := b.commands[command]; exists {
@@ -360,6 +215,11 @@ func (b *Bot) handleMessage(message *tgbotapi.Message) error {
 		return b.handleUnknownCommand(message)
 	}
 
+	// A shared location sets the timezone
+	if message.Location != nil {
+		return b.handleLocation(message)
+	}
+
 	// Handle non-command messages
 	return b.handleTextMessage(message)
 }
@@ -377,20 +237,27 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) error {
 
 	// Handle different callback actions
 	parts := strings.Split(data, ":")
-	if len(parts) < 2 && data != "main_menu" && data != "list" && data != "stats" && data != "help" && data != "add" && data != "settings" && data != "reminders" && data != "serverstats" && data != "lang_en" && data != "lang_th" {
+	if len(parts) < 2 && data != "main_menu" && data != "list" && data != "stats" && data != "help" && data != "add" && data != "settings" && data != "reminders" && data != "reminder_help" && data != "serverstats" && !strings.HasPrefix(data, "lang_") {
 		_, err := b.api.Request(tgbotapi.CallbackConfig{
 			CallbackQueryID: callback.ID,
 		})
 		return err
 	}
 
-	var action, id string
+	var action, id, arg string
 	if len(parts) >= 2 {
 		action = parts[0]
 		id = parts[1]
 	} else {
 		action = data
 	}
+	if len(parts) >= 3 {
+		arg = parts[2]
+	}
+
+	if callback.Message != nil && isGroupChat(callback.Message.Chat) {
+		return b.handleGroupCallback(callback, action, id, arg)
+	}
 
 	switch action {
 	case "complete":
@@ -398,7 +265,13 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) error {
 	case "delete":
 		return b.handleDeleteCallback(callback, id)
 	case "snooze":
-		return b.handleSnoozeCallback(callback, id)
+		return b.handleSnoozeCallback(callback, id, arg)
+	case "rdone":
+		return b.handleReminderDoneCallback(callback, id)
+	case "rstop":
+		return b.handleReminderStopCallback(callback, id)
+	case "resched":
+		return b.handleRescheduleCallback(callback, id)
 	case "main_menu":
 		return b.handleMainMenu(callback)
 	case "list":
@@ -408,21 +281,53 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) error {
 	case "help":
 		return b.handleHelpFromCallback(callback)
 	case "add":
-		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, "Please use: /add &lt;task title&gt; to create a new task")
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.catalog(callback.From.ID).T("add.usage"))
 		msg.ParseMode = "HTML"
 		_, err := b.api.Send(msg)
 		return err
 	case "reminders":
 		return b.handleRemindersFromCallback(callback)
+	case "reminder_help":
+		return b.handleReminderHelpFromCallback(callback)
+	case "rcancel":
+		return b.handleReminderCancelCallback(callback, id)
+	case "rpause":
+		return b.handleReminderPauseCallback(callback, id, true)
+	case "rresume":
+		return b.handleReminderPauseCallback(callback, id, false)
+	case "dgdone", "dgtomorrow", "dgdrop":
+		return b.handleDigestCallback(callback, action, id)
+	case "rollover":
+		return b.handleRolloverCallback(callback)
+	case "restore":
+		return b.handleRestoreCallback(callback, id)
+	case "tz":
+		return b.handleTimezoneCallback(callback, id, arg)
+	case "onb":
+		return b.handleOnboardingCallback(callback, id, arg)
+	case "asg":
+		return b.handleAssignmentCallback(callback, id, arg)
+	case "asgto":
+		return b.handleAssignToCallback(callback, id, arg)
+	case "shrevoke", "shrole", "shrm":
+		return b.handleShareCallback(callback, action, id, arg)
+	case "shadd", "shleave":
+		return b.handleSharedListCallback(callback, action, id)
+	case "task", "watch", "unwatch":
+		return b.handleTaskCallback(callback, action, id, arg)
+	case "style":
+		return b.handleStyleCallback(callback, id)
 	case "settings":
 		return b.handleSettings(callback)
+	case "set":
+		return b.handleSettingsCallback(callback, id, arg)
 	case "serverstats":
 		return b.handleServerStatsFromCallback(callback)
-	case "lang_en":
-		return b.handleLanguageChange(callback, LangEN)
-	case "lang_th":
-		return b.handleLanguageChange(callback, LangTH)
 	default:
+		// Language buttons of older settings messages
+		if language, ok := strings.CutPrefix(action, "lang_"); ok {
+			return b.handleLanguageChange(callback, language)
+		}
 		_, err := b.api.Request(tgbotapi.CallbackConfig{
 			CallbackQueryID: callback.ID,
 		})
@@ -444,23 +349,36 @@ func (b *Bot) handleListFromCallback(callback *tgbotapi.CallbackQuery) error {
 		return fmt.Errorf("failed to get todos: %w", err)
 	}
 
-	trans := b.getTranslation(callback.From.ID)
+	c := userCatalog(user)
 
 	if len(todos) == 0 {
-		noTasksText := trans.NoTasks
-		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, noTasksText)
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, c.T("list.empty"))
 		_, err := b.api.Send(msg)
 		return err
 	}
 
+	view := listView(user, "")
+	show := b.listViewFilter(user, view, time.Now())
+
 	// Build todo list
 	var listText strings.Builder
-	listText.WriteString(fmt.Sprintf("%s\n\n", trans.YourTodos))
+	listText.WriteString(listTitle(c, view) + "\n\n")
 
+	shown := 0
+	names := map[uuid.UUID]string{}
+	tf := userTimeFormat(user)
 	for i, todo := range todos {
+		// Keep the numbering of the full list so /complete and friends match
+		if !show(todo) {
+			continue
+		}
+		shown++
+
 		status := "🔴"
 		if todo.Status == "completed" {
 			status = "✅"
+		} else if todo.Status == "cancelled" {
+			status = "🗑️"
 		}
 		
 		priority := ""
@@ -470,36 +388,53 @@ func (b *Bot) handleListFromCallback(callback *tgbotapi.CallbackQuery) error {
 			priority = "� "
 		}
 		
-		listText.WriteString(fmt.Sprintf("%d. %s %s%s\n", i+1, status, priority, todo.Title))
+		listText.WriteString(fmt.Sprintf("%d. %s %s%s\n", i+1, status, priority, html.EscapeString(todo.Title)))
 		
 		if todo.DueTime != nil {
-			listText.WriteString(fmt.Sprintf("   📅 Due: %s\n", b.formatTimeForUser(*todo.DueTime, callback.From.ID)))
+			listText.WriteString("   " + c.T("list.due", "time", tf.Time(*todo.DueTime)) + "\n")
 		}
 		
 		if todo.Description != nil && *todo.Description != "" {
-			listText.WriteString(fmt.Sprintf("   📝 %s\n", *todo.Description))
+			listText.WriteString(fmt.Sprintf("   📝 %s\n", html.EscapeString(*todo.Description)))
+		}
+
+		if note := b.sharingNote(c, &todo, user, names); note != "" {
+			listText.WriteString("   " + note + "\n")
+		}
+
+		if todo.Status == "pending" {
+			listText.WriteString(b.reminderSummary(todo.ID, tf))
 		}
 		
 		listText.WriteString("\n")
 	}
 
+	if shown == 0 {
+		listText.WriteString(c.T("list.view_empty") + "\n")
+	}
+
 	// Create inline keyboard for each todo
 	var keyboardRows [][]tgbotapi.InlineKeyboardButton
 	
 	for _, todo := range todos {
-		if todo.Status == "pending" {
+		// Viewers of a shared list get no buttons, assignees only complete
+		if todo.Status == "pending" && show(todo) && b.canCompleteTodo(&todo, user) {
 			row := tgbotapi.NewInlineKeyboardRow(
 				tgbotapi.NewInlineKeyboardButtonData("✅", fmt.Sprintf("complete:%s", todo.ID)),
-				tgbotapi.NewInlineKeyboardButtonData("🗑️", fmt.Sprintf("delete:%s", todo.ID)),
-				tgbotapi.NewInlineKeyboardButtonData("⏰", fmt.Sprintf("remind:%s", todo.ID)),
 			)
+			if b.canEditTodo(&todo, user) {
+				row = append(row, tgbotapi.NewInlineKeyboardButtonData("🗑️", fmt.Sprintf("delete:%s", todo.ID)))
+			}
+			if ownsTodo(&todo, user) {
+				row = append(row, tgbotapi.NewInlineKeyboardButtonData("⏰", fmt.Sprintf("remind:%s", todo.ID)))
+			}
 			keyboardRows = append(keyboardRows, row)
 		}
 	}
 	
 	// Add navigation buttons
 	navRow := tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("🏠 Main Menu", "main_menu"),
+		tgbotapi.NewInlineKeyboardButtonData(c.T("menu.main"), "main_menu"),
 	)
 	keyboardRows = append(keyboardRows, navRow)
 
@@ -521,7 +456,7 @@ func (b *Bot) handleStatsFromCallback(callback *tgbotapi.CallbackQuery) error {
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 	if user == nil {
-		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, "Please start with /start first")
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, catalogFor(callback.From.LanguageCode).T("error.start_first"))
 		_, err := b.api.Send(msg)
 		return err
 	}
@@ -532,35 +467,13 @@ func (b *Bot) handleStatsFromCallback(callback *tgbotapi.CallbackQuery) error {
 		return fmt.Errorf("failed to get todo stats: %w", err)
 	}
 
-	statsText := fmt.Sprintf(`📊 <b>Your Todo Statistics</b>
-
-📈 <b>Overview:</b>
-• Total tasks: %d
-• Completed: %d
-• Pending: %d
-• Overdue: %d
-
-🎯 <b>Priority Breakdown:</b>
-• High priority: %d
-• Medium priority: %d
-• Low priority: %d
-
-📈 <b>Completion Rate:</b>
-• %.1f%% completed`,
-		stats.Total,
-		stats.Completed,
-		stats.Pending,
-		stats.Overdue,
-		stats.HighPriority,
-		stats.MediumPriority,
-		stats.LowPriority,
-		float64(stats.Completed)/float64(stats.Total)*100,
-	)
+	c := userCatalog(user)
+	statsText := statsMessage(c, stats)
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Main Menu", "main_menu"),
-			tgbotapi.NewInlineKeyboardButtonData("📋 My Tasks", "list"),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("menu.main"), "main_menu"),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("menu.my_tasks"), "list"),
 		),
 	)
 
@@ -574,15 +487,15 @@ func (b *Bot) handleStatsFromCallback(callback *tgbotapi.CallbackQuery) error {
 
 // handleHelpFromCallback handles the help command from a callback
 func (b *Bot) handleHelpFromCallback(callback *tgbotapi.CallbackQuery) error {
-	trans := b.getTranslation(callback.From.ID)
+	c := b.catalog(callback.From.ID)
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Main Menu", "main_menu"),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("menu.main"), "main_menu"),
 		),
 	)
 
-	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, trans.HelpText)
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, c.T("help.text"))
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
 
@@ -592,38 +505,70 @@ func (b *Bot) handleHelpFromCallback(callback *tgbotapi.CallbackQuery) error {
 
 // handleReminders handles the /reminders command
 func (b *Bot) handleReminders(message *tgbotapi.Message) error {
-	trans := b.getTranslation(message.From.ID)
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Main Menu", "main_menu"),
-		),
-	)
+	text, keyboard, err := b.renderReminderList(user)
+	if err != nil {
+		return err
+	}
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, trans.ReminderOptions)
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
 
-	_, err := b.api.Send(msg)
+	_, err = b.api.Send(msg)
 	return err
 }
 
 // handleRemindersFromCallback handles the reminders command from a callback
 func (b *Bot) handleRemindersFromCallback(callback *tgbotapi.CallbackQuery) error {
-	trans := b.getTranslation(callback.From.ID)
+	user, err := b.db.GetUserByTelegramID(callback.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, catalogFor(callback.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	text, keyboard, err := b.renderReminderList(user)
+	if err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = keyboard
+
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleReminderHelpFromCallback shows the reminder time formats
+func (b *Bot) handleReminderHelpFromCallback(callback *tgbotapi.CallbackQuery) error {
+	c := b.catalog(callback.From.ID)
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(trans.MyTasks, "list"),
-			tgbotapi.NewInlineKeyboardButtonData(trans.AddTask, "add"),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("menu.my_tasks"), "list"),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("menu.add_task"), "add"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Main Menu", "main_menu"),
-			tgbotapi.NewInlineKeyboardButtonData(trans.Help, "help"),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("menu.main"), "main_menu"),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("menu.help"), "help"),
 		),
 	)
 
-	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, trans.ReminderOptions)
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, c.T("reminders.help"))
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
 
@@ -633,6 +578,12 @@ func (b *Bot) handleRemindersFromCallback(callback *tgbotapi.CallbackQuery) erro
 
 // handleServerStats handles the /serverstats command
 func (b *Bot) handleServerStats(message *tgbotapi.Message) error {
+	_, err := b.api.Send(b.serverStatsMessage(message.Chat.ID, b.catalog(message.From.ID)))
+	return err
+}
+
+// serverStatsMessage builds the server statistics message
+func (b *Bot) serverStatsMessage(chatID int64, c *Catalog) tgbotapi.MessageConfig {
 	// Get system information
 	hostInfo, _ := host.Info()
 
@@ -654,117 +605,55 @@ func (b *Bot) handleServerStats(message *tgbotapi.Message) error {
 	// Get bot version (hardcoded for now)
 	botVersion := "1.0.0"
 
+	unknown := c.T("serverstats.unknown")
+	osName, platform, arch, hostname := unknown, unknown, runtime.GOARCH, unknown
+	if hostInfo != nil {
+		osName = hostInfo.OS + " " + hostInfo.Platform
+		platform = hostInfo.PlatformFamily + " " + hostInfo.PlatformVersion
+		arch = hostInfo.KernelArch
+		hostname = hostInfo.Hostname
+	}
+
+	cpuUsage := 0.0
+	if len(cpuPercent) > 0 {
+		cpuUsage = cpuPercent[0]
+	}
+
+	memoryUsage, diskUsage := unknown, unknown
+	if memInfo != nil {
+		memoryUsage = fmt.Sprintf("%s / %s (%s)", formatBytes(memInfo.Used), formatBytes(memInfo.Total), c.Percent(memInfo.UsedPercent))
+	}
+	if diskInfo != nil {
+		diskUsage = fmt.Sprintf("%s / %s (%s)", formatBytes(diskInfo.Used), formatBytes(diskInfo.Total), c.Percent(diskInfo.UsedPercent))
+	}
+
 	// Build stats message
-	statsText := fmt.Sprintf(`🖥️ <b>Server Statistics</b>
-
-📊 <b>System Info:</b>
-• <b>OS:</b> %s %s
-• <b>Platform:</b> %s
-• <b>Architecture:</b> %s
-• <b>Hostname:</b> %s
-• <b>Uptime:</b> %s
-
-💻 <b>Hardware:</b>
-• <b>CPU Usage:</b> %.1f%%
-• <b>CPU Cores:</b> %d
-• <b>Memory:</b> %s / %s (%.1f%%)
-• <b>Disk:</b> %s / %s (%.1f%%)
-
-🤖 <b>Bot Info:</b>
-• <b>Version:</b> %s
-• <b>Go Version:</b> %s
-• <b>Process ID:</b> %d`,
-		func() string {
-			if hostInfo != nil {
-				return hostInfo.OS + " " + hostInfo.Platform
-			}
-			return "Unknown"
-		}(),
-		func() string {
-			if hostInfo != nil {
-				return hostInfo.PlatformFamily
-			}
-			return "Unknown"
-		}(),
-		func() string {
-			if hostInfo != nil {
-				return hostInfo.PlatformVersion
-			}
-			return "Unknown"
-		}(),
-		func() string {
-			if hostInfo != nil {
-				return hostInfo.KernelArch
-			}
-			return runtime.GOARCH
-		}(),
-		func() string {
-			if hostInfo != nil {
-				return hostInfo.Hostname
-			}
-			return "Unknown"
-		}(),
-		uptime,
-		func() float64 {
-			if len(cpuPercent) > 0 {
-				return cpuPercent[0]
-			}
-			return 0
-		}(),
-		runtime.NumCPU(),
-		func() string {
-			if memInfo != nil {
-				return formatBytes(memInfo.Used)
-			}
-			return "Unknown"
-		}(),
-		func() string {
-			if memInfo != nil {
-				return formatBytes(memInfo.Total)
-			}
-			return "Unknown"
-		}(),
-		func() float64 {
-			if memInfo != nil {
-				return memInfo.UsedPercent
-			}
-			return 0
-		}(),
-		func() string {
-			if diskInfo != nil {
-				return formatBytes(diskInfo.Used)
-			}
-			return "Unknown"
-		}(),
-		func() string {
-			if diskInfo != nil {
-				return formatBytes(diskInfo.Total)
-			}
-			return "Unknown"
-		}(),
-		func() float64 {
-			if diskInfo != nil {
-				return diskInfo.UsedPercent
-			}
-			return 0
-		}(),
-		botVersion,
-		runtime.Version(),
-		os.Getpid())
+	statsText := c.T("serverstats.text",
+		"os", osName,
+		"platform", platform,
+		"arch", arch,
+		"hostname", hostname,
+		"uptime", uptime,
+		"cpu", c.Percent(cpuUsage),
+		"cores", runtime.NumCPU(),
+		"memory", memoryUsage,
+		"disk", diskUsage,
+		"version", botVersion,
+		"go_version", runtime.Version(),
+		"pid", os.Getpid())
+	statsText += "\n\n" + b.scheduler.statsText(c)
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔄 Refresh", "serverstats"),
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Main Menu", "main_menu"),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("serverstats.refresh"), "serverstats"),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("menu.main"), "main_menu"),
 		),
 	)
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, statsText)
+	msg := tgbotapi.NewMessage(chatID, statsText)
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
-
-	_, err := b.api.Send(msg)
-	return err
+	return msg
 }
 
 // formatBytes formats bytes into human readable format
@@ -798,137 +687,7 @@ func formatUptime(seconds uint64) string {
 
 // handleServerStatsFromCallback handles the serverstats command from a callback
 func (b *Bot) handleServerStatsFromCallback(callback *tgbotapi.CallbackQuery) error {
-	// Get system information
-	hostInfo, _ := host.Info()
-
-	// Get CPU info
-	cpuPercent, _ := cpu.Percent(time.Second, false)
-
-	// Get memory info
-	memInfo, _ := mem.VirtualMemory()
-
-	// Get disk info
-	diskInfo, _ := disk.Usage("/")
-
-	// Get uptime
-	var uptime string
-	if hostInfo != nil {
-		uptime = formatUptime(hostInfo.Uptime)
-	}
-
-	// Get bot version (hardcoded for now)
-	botVersion := "1.0.0"
-
-	// Build stats message
-	statsText := fmt.Sprintf(`🖥️ <b>Server Statistics</b>
-
-📊 <b>System Info:</b>
-• <b>OS:</b> %s %s
-• <b>Platform:</b> %s
-• <b>Architecture:</b> %s
-• <b>Hostname:</b> %s
-• <b>Uptime:</b> %s
-
-💻 <b>Hardware:</b>
-• <b>CPU Usage:</b> %.1f%%
-• <b>CPU Cores:</b> %d
-• <b>Memory:</b> %s / %s (%.1f%%)
-• <b>Disk:</b> %s / %s (%.1f%%)
-
-🤖 <b>Bot Info:</b>
-• <b>Version:</b> %s
-• <b>Go Version:</b> %s
-• <b>Process ID:</b> %d`,
-		func() string {
-			if hostInfo != nil {
-				return hostInfo.OS + " " + hostInfo.Platform
-			}
-			return "Unknown"
-		}(),
-		func() string {
-			if hostInfo != nil {
-				return hostInfo.PlatformFamily
-			}
-			return "Unknown"
-		}(),
-		func() string {
-			if hostInfo != nil {
-				return hostInfo.PlatformVersion
-			}
-			return "Unknown"
-		}(),
-		func() string {
-			if hostInfo != nil {
-				return hostInfo.KernelArch
-			}
-			return runtime.GOARCH
-		}(),
-		func() string {
-			if hostInfo != nil {
-				return hostInfo.Hostname
-			}
-			return "Unknown"
-		}(),
-		uptime,
-		func() float64 {
-			if len(cpuPercent) > 0 {
-				return cpuPercent[0]
-			}
-			return 0
-		}(),
-		runtime.NumCPU(),
-		func() string {
-			if memInfo != nil {
-				return formatBytes(memInfo.Used)
-			}
-			return "Unknown"
-		}(),
-		func() string {
-			if memInfo != nil {
-				return formatBytes(memInfo.Total)
-			}
-			return "Unknown"
-		}(),
-		func() float64 {
-			if memInfo != nil {
-				return memInfo.UsedPercent
-			}
-			return 0
-		}(),
-		func() string {
-			if diskInfo != nil {
-				return formatBytes(diskInfo.Used)
-			}
-			return "Unknown"
-		}(),
-		func() string {
-			if diskInfo != nil {
-				return formatBytes(diskInfo.Total)
-			}
-			return "Unknown"
-		}(),
-		func() float64 {
-			if diskInfo != nil {
-				return diskInfo.UsedPercent
-			}
-			return 0
-		}(),
-		botVersion,
-		runtime.Version(),
-		os.Getpid())
-
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔄 Refresh", "serverstats"),
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Main Menu", "main_menu"),
-		),
-	)
-
-	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, statsText)
-	msg.ParseMode = "HTML"
-	msg.ReplyMarkup = keyboard
-
-	_, err := b.api.Send(msg)
+	_, err := b.api.Send(b.serverStatsMessage(callback.Message.Chat.ID, b.catalog(callback.From.ID)))
 	return err
 }
 
@@ -937,6 +696,11 @@ func (b *Bot) handleStart(message *tgbotapi.Message) error {
 	userID := message.From.ID
 	userName := message.From.FirstName + " " + message.From.LastName
 
+	// Invite links open the bot with /start join_<token>, escalation
+	// contact links with /start esc_<token>
+	token, joining := strings.CutPrefix(message.CommandArguments(), joinPayloadPrefix)
+	contactToken, escalating := strings.CutPrefix(message.CommandArguments(), escalationPayloadPrefix)
+
 	// Check if user exists
 	user, err := b.db.GetUserByTelegramID(userID)
 	if err != nil {
@@ -944,25 +708,43 @@ func (b *Bot) handleStart(message *tgbotapi.Message) error {
 	}
 
 	if user == nil {
-		// Create new user
+		// Speak the language of the user's Telegram app when we have it
+		language := defaultLanguage
+		if catalog := matchCatalog(message.From.LanguageCode); catalog != nil {
+			language = catalog.Language
+		}
+
+		// Create new user; people who start the bot get the overdue digest
 		newUser := NewUser{
-			TelegramID: userID,
-			Name:       userName,
-			Timezone:   "Asia/Bangkok",
-			Language:   "en",
+			TelegramID:    userID,
+			Name:          userName,
+			Timezone:      defaultTimezone,
+			Language:      language,
+			DigestEnabled: true,
 		}
 		user, err = b.db.CreateUser(newUser)
 		if err != nil {
 			return fmt.Errorf("failed to create user: %w", err)
 		}
-	} else {
-		// Force update existing user's timezone if it's UTC
-		if user.Timezone == "UTC" {
-			user, err = b.db.UpdateUserSettings(user.ID, stringPtr("Asia/Bangkok"), nil, nil)
-			if err != nil {
-				log.Printf("Failed to update user timezone in start: %v", err)
+
+		if joining {
+			if err := b.joinList(message.Chat.ID, user, token); err != nil {
+				log.Printf("Failed to join list for new user %s: %v", user.ID, err)
+			}
+		}
+		if escalating {
+			if err := b.acceptEscalationInvite(message.Chat.ID, user, contactToken); err != nil {
+				log.Printf("Failed to accept escalation invite for new user %s: %v", user.ID, err)
 			}
 		}
+		return b.startOnboarding(message.Chat.ID, user)
+	}
+
+	if joining {
+		return b.joinList(message.Chat.ID, user, token)
+	}
+	if escalating {
+		return b.acceptEscalationInvite(message.Chat.ID, user, contactToken)
 	}
 
 	// Show main menu directly
@@ -971,12 +753,23 @@ func (b *Bot) handleStart(message *tgbotapi.Message) error {
 
 // handleMainMenuFromMessage shows main menu from a regular message
 func (b *Bot) handleMainMenuFromMessage(message *tgbotapi.Message, user *User) error {
-	trans := b.getTranslation(message.From.ID)
-	
+	msg, err := b.mainMenuMessage(message.Chat.ID, user)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// mainMenuMessage builds the main menu with the user's statistics
+func (b *Bot) mainMenuMessage(chatID int64, user *User) (tgbotapi.MessageConfig, error) {
+	c := userCatalog(user)
+
 	// Get user statistics
 	todos, err := b.db.GetUserTodos(user.ID)
 	if err != nil {
-		return err
+		return tgbotapi.MessageConfig{}, err
 	}
 	
 	totalTasks := len(todos)
@@ -996,45 +789,39 @@ func (b *Bot) handleMainMenuFromMessage(message *tgbotapi.Message, user *User) e
 		completionRate = float64(completedTasks) / float64(totalTasks) * 100
 	}
 	
-	menuText := fmt.Sprintf(`🏠 <b>Main Menu</b>
-
-👋 Welcome back, <b>%s</b>!
-
-📊 <b>Your Statistics:</b>
-• Total Tasks: <b>%d</b>
-• Completed: <b>%d</b>
-• Pending: <b>%d</b>
-• Success Rate: <b>%.1f%%</b>`, 
-		user.Name, totalTasks, completedTasks, pendingTasks, completionRate)
+	menuText := c.T("menu.text",
+		"name", user.Name,
+		"total", totalTasks,
+		"completed", completedTasks,
+		"pending", pendingTasks,
+		"rate", c.Percent(completionRate))
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(trans.MyTasks, "list"),
-			tgbotapi.NewInlineKeyboardButtonData(trans.Statistics, "stats"),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("menu.my_tasks"), "list"),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("menu.statistics"), "stats"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(trans.AddTask, "add"),
-			tgbotapi.NewInlineKeyboardButtonData(trans.Reminders, "reminders"),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("menu.add_task"), "add"),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("menu.reminders"), "reminders"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(trans.ServerStats, "serverstats"),
-			tgbotapi.NewInlineKeyboardButtonData(trans.Settings, "settings"),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("menu.server_stats"), "serverstats"),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("menu.settings"), "settings"),
 		),
 	)
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, menuText)
+	msg := tgbotapi.NewMessage(chatID, menuText)
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
-
-	_, err = b.api.Send(msg)
-	return err
+	return msg, nil
 }
 
 // handleAdd handles the /add command
 func (b *Bot) handleAdd(message *tgbotapi.Message) error {
 	args := message.CommandArguments()
 	if args == "" {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Please provide a task title. Example: /add Buy groceries")
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.catalog(message.From.ID).T("add.missing_title"))
 		_, err := b.api.Send(msg)
 		return err
 	}
@@ -1045,32 +832,29 @@ func (b *Bot) handleAdd(message *tgbotapi.Message) error {
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 	if user == nil {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Please start with /start first")
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
 		_, err := b.api.Send(msg)
 		return err
 	}
 
-	// Parse the task (simple implementation for now)
-	parts := strings.SplitN(args, " ", 2)
-	if len(parts) < 1 {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Please provide a task title")
-		_, err := b.api.Send(msg)
-		return err
-	}
+	c := userCatalog(user)
 
-	title := parts[0]
-	var description *string
-	if len(parts) > 1 {
-		desc := strings.Join(parts[1:], " ")
-		description = &desc
+	// A trailing "due <when>" sets the due time
+	args, dueTime := splitDueSuffix(args, b.nowInUserTimezone(message.From.ID), b.userLocation(message.From.ID))
+	if dueTime != nil {
+		due := dueTime.UTC()
+		dueTime = &due
 	}
 
+	title, description := splitTitle(args)
+
 	// Create todo
 	newTodo := NewTodo{
 		UserID:      user.ID,
 		Title:       title,
 		Description: description,
-		Priority:    "medium",
+		DueTime:     dueTime,
+		Priority:    defaultPriority(user),
 	}
 
 	todo, err := b.db.CreateTodo(newTodo)
@@ -1078,18 +862,39 @@ func (b *Bot) handleAdd(message *tgbotapi.Message) error {
 		return fmt.Errorf("failed to create todo: %w", err)
 	}
 
-	msgText := fmt.Sprintf("✅ Task created successfully!\n\n<b>%s</b>", todo.Title)
+	msgText := c.T("add.created", "title", html.EscapeString(todo.Title))
 	if description != nil {
-		msgText += fmt.Sprintf("\n\n%s", *description)
+		msgText += fmt.Sprintf("\n\n%s", html.EscapeString(*description))
+	}
+	if todo.DueTime != nil {
+		msgText += "\n\n" + c.T("add.due", "time", userTimeFormat(user).When(*todo.DueTime))
+
+		scheduled, err := b.syncDueReminders(todo, user)
+		if err != nil {
+			log.Printf("Failed to schedule due reminders for todo %s: %v", todo.ID, err)
+		} else if scheduled > 0 {
+			msgText += "\n" + c.T("add.reminders_scheduled", "count", scheduled)
+		}
 	}
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
+	msg := tgbotapi.NewMessage(message.Chat.ID, styled(user, msgText,
+		c.T("add.created_compact", "title", html.EscapeString(todo.Title)), c.T("add.created_minimal", "title", html.EscapeString(todo.Title))))
 	msg.ParseMode = "HTML"
 
 	_, err = b.api.Send(msg)
 	return err
 }
 
+// splitTitle splits /add arguments into the title, the first word, and
+// an optional description made of the rest
+func splitTitle(args string) (string, *string) {
+	parts := strings.SplitN(args, " ", 2)
+	if len(parts) < 2 {
+		return parts[0], nil
+	}
+	return parts[0], &parts[1]
+}
+
 // handleList handles the /list command
 func (b *Bot) handleList(message *tgbotapi.Message) error {
 	// Get user
@@ -1098,7 +903,7 @@ func (b *Bot) handleList(message *tgbotapi.Message) error {
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 	if user == nil {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Please start with /start first")
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
 		_, err := b.api.Send(msg)
 		return err
 	}
@@ -1109,20 +914,37 @@ func (b *Bot) handleList(message *tgbotapi.Message) error {
 		return fmt.Errorf("failed to get todos: %w", err)
 	}
 
+	c := userCatalog(user)
+
 	if len(todos) == 0 {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "You don't have any todos yet. Use /add to create one!")
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("list.empty"))
 		_, err := b.api.Send(msg)
 		return err
 	}
 
+	// An argument such as "today" overrides the default list view
+	view := listView(user, strings.ToLower(strings.TrimSpace(message.CommandArguments())))
+	show := b.listViewFilter(user, view, time.Now())
+
 	// Build message with todos
 	var msgText strings.Builder
-	msgText.WriteString("📋 <b>Your Todos:</b>\n\n")
+	msgText.WriteString(listTitle(c, view) + "\n\n")
 
+	shown := 0
+	names := map[uuid.UUID]string{}
+	tf := userTimeFormat(user)
 	for i, todo := range todos {
+		// Keep the numbering of the full list so /complete and friends match
+		if !show(todo) {
+			continue
+		}
+		shown++
+
 		status := "⏳"
 		if todo.Status == "completed" {
 			status = "✅"
+		} else if todo.Status == "cancelled" {
+			status = "🗑️"
 		}
 
 		priority := ""
@@ -1137,31 +959,46 @@ func (b *Bot) handleList(message *tgbotapi.Message) error {
 
 		dueTime := ""
 		if todo.DueTime != nil {
-			dueTime = fmt.Sprintf(" 📅 %s", b.formatTimeForUser(*todo.DueTime, message.From.ID))
+			dueTime = fmt.Sprintf(" 📅 %s", tf.Time(*todo.DueTime))
 		}
 
-		msgText.WriteString(fmt.Sprintf("%d\\. %s %s *%s*%s\n", i+1, status, priority, escapeMarkdown(todo.Title), escapeMarkdown(dueTime)))
+		msgText.WriteString(fmt.Sprintf("%d. %s %s <b>%s</b>%s\n", i+1, status, priority, html.EscapeString(todo.Title), dueTime))
 
 		if todo.Description != nil {
-			msgText.WriteString(fmt.Sprintf("   %s\n", escapeMarkdown(*todo.Description)))
+			msgText.WriteString(fmt.Sprintf("   %s\n", html.EscapeString(*todo.Description)))
+		}
+
+		if note := b.sharingNote(c, &todo, user, names); note != "" {
+			msgText.WriteString("   " + note + "\n")
+		}
+
+		if todo.Status == "pending" {
+			msgText.WriteString(b.reminderSummary(todo.ID, tf))
 		}
 	}
 
+	if shown == 0 {
+		msgText.WriteString(c.T("list.view_empty") + "\n")
+	}
+
 	// Add action buttons
 	keyboard := tgbotapi.NewInlineKeyboardMarkup()
 	for _, todo := range todos {
-		if todo.Status != "completed" {
+		// Viewers of a shared list get no buttons, assignees only complete
+		if todo.Status == "pending" && show(todo) && b.canCompleteTodo(&todo, user) {
 			row := tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("✅ Complete", fmt.Sprintf("complete:%s", todo.ID)),
-				tgbotapi.NewInlineKeyboardButtonData("🗑️ Delete", fmt.Sprintf("delete:%s", todo.ID)),
+				tgbotapi.NewInlineKeyboardButtonData(c.T("list.complete"), fmt.Sprintf("complete:%s", todo.ID)),
 			)
+			if b.canEditTodo(&todo, user) {
+				row = append(row, tgbotapi.NewInlineKeyboardButtonData(c.T("list.delete"), fmt.Sprintf("delete:%s", todo.ID)))
+			}
 			keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
 		}
 	}
 
 	// Add main menu button at the bottom
 	menuRow := tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("🏠 Main Menu", "main_menu"),
+		tgbotapi.NewInlineKeyboardButtonData(c.T("menu.main"), "main_menu"),
 	)
 	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, menuRow)
 
@@ -1175,33 +1012,7 @@ func (b *Bot) handleList(message *tgbotapi.Message) error {
 
 // handleHelp handles the /help command
 func (b *Bot) handleHelp(message *tgbotapi.Message) error {
-	helpText := `🤖 <b>Todo Bot Help</b>
-
-📝 <b>Task Management:</b>
-• /add &lt;title&gt; [description] - Create a new task
-• /list - View all your tasks
-• /stats - View your task statistics
-
-🔧 <b>Task Actions:</b>
-• /complete &lt;id&gt; - Mark a task as completed
-• /delete &lt;id&gt; - Delete a task
-
-⏰ <b>Reminders:</b>
-• /remind &lt;id&gt; &lt;time&gt; - Set a reminder for a task
-• /snooze &lt;id&gt; &lt;time&gt; - Snooze a reminder
-
-📊 <b>Examples:</b>
-• /add Buy groceries
-• /add Meeting with John at 3pm
-• /complete 1
-• /remind 1 2h
-• /snooze 1 30m
-
-⚙️ <b>Settings:</b>
-• /start - Register or welcome message
-• /help - Show this help message`
-
-	msg := tgbotapi.NewMessage(message.Chat.ID, helpText)
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.catalog(message.From.ID).T("help.text"))
 	msg.ParseMode = "HTML"
 
 	_, err := b.api.Send(msg)
@@ -1216,7 +1027,7 @@ func (b *Bot) handleStats(message *tgbotapi.Message) error {
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 	if user == nil {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Please start with /start first")
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
 		_, err := b.api.Send(msg)
 		return err
 	}
@@ -1227,43 +1038,32 @@ func (b *Bot) handleStats(message *tgbotapi.Message) error {
 		return fmt.Errorf("failed to get todo stats: %w", err)
 	}
 
-	statsText := fmt.Sprintf(`📊 <b>Your Todo Statistics</b>
-
-📈 <b>Overview:</b>
-• Total tasks: %d
-• Completed: %d
-• Pending: %d
-• Overdue: %d
-
-🎯 <b>Priority Breakdown:</b>
-• High priority: %d
-• Medium priority: %d
-• Low priority: %d
-
-📈 <b>Completion Rate:</b>
-• %.1f%% completed`,
-		stats.Total,
-		stats.Completed,
-		stats.Pending,
-		stats.Overdue,
-		stats.HighPriority,
-		stats.MediumPriority,
-		stats.LowPriority,
-		float64(stats.Completed)/float64(stats.Total)*100,
-	)
-
-	msg := tgbotapi.NewMessage(message.Chat.ID, statsText)
+	msg := tgbotapi.NewMessage(message.Chat.ID, statsMessage(userCatalog(user), stats))
 	msg.ParseMode = "HTML"
 
 	_, err = b.api.Send(msg)
 	return err
 }
 
+// statsMessage renders a user's todo statistics
+func statsMessage(c *Catalog, stats *TodoStats) string {
+	return c.T("stats.text",
+		"total", stats.Total,
+		"completed", stats.Completed,
+		"pending", stats.Pending,
+		"overdue", stats.Overdue,
+		"high", stats.HighPriority,
+		"medium", stats.MediumPriority,
+		"low", stats.LowPriority,
+		"rate", c.Percent(float64(stats.Completed)/float64(stats.Total)*100))
+}
+
 // handleDelete handles the /delete command
 func (b *Bot) handleDelete(message *tgbotapi.Message) error {
+	c := b.catalog(message.From.ID)
 	args := message.CommandArguments()
 	if args == "" {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Please provide a task ID. Example: /delete 1")
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("delete.usage"))
 		_, err := b.api.Send(msg)
 		return err
 	}
@@ -1271,29 +1071,40 @@ func (b *Bot) handleDelete(message *tgbotapi.Message) error {
 	// Convert args to UUID (simplified - in real app you'd use task numbers)
 	todoID, err := uuid.Parse(args)
 	if err != nil {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Invalid task ID")
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.invalid_id"))
 		_, err := b.api.Send(msg)
 		return err
 	}
 
+	todo, user, err := b.userTodo(message.From.ID, todoID.String())
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("delete.failed"))
+		_, err2 := b.api.Send(msg)
+		return err2
+	}
+	if !b.canEditTodo(todo, user) {
+		return b.sendNotAllowed(message.Chat.ID, c, todo)
+	}
+
 	// Delete todo
 	err = b.db.DeleteTodo(todoID)
 	if err != nil {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Failed to delete task")
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("delete.failed"))
 		_, err2 := b.api.Send(msg)
 		return err2
 	}
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, "🗑️ Task deleted successfully!")
+	msg := tgbotapi.NewMessage(message.Chat.ID, c.T("delete.done"))
 	_, err = b.api.Send(msg)
 	return err
 }
 
 // handleComplete handles the /complete command
 func (b *Bot) handleComplete(message *tgbotapi.Message) error {
+	c := b.catalog(message.From.ID)
 	args := message.CommandArguments()
 	if args == "" {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Please provide a task ID. Example: /complete 1")
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("complete.usage"))
 		_, err := b.api.Send(msg)
 		return err
 	}
@@ -1301,7 +1112,7 @@ func (b *Bot) handleComplete(message *tgbotapi.Message) error {
 	// Parse task number
 	taskNum, err := strconv.Atoi(args)
 	if err != nil {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Invalid task ID. Please use a number like 1, 2, 3...")
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.invalid_number"))
 		_, err := b.api.Send(msg)
 		return err
 	}
@@ -1312,7 +1123,7 @@ func (b *Bot) handleComplete(message *tgbotapi.Message) error {
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 	if user == nil {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Please start with /start first")
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
 		_, err := b.api.Send(msg)
 		return err
 	}
@@ -1324,64 +1135,129 @@ func (b *Bot) handleComplete(message *tgbotapi.Message) error {
 	}
 
 	if taskNum < 1 || taskNum > len(todos) {
-		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("Task not found. Please use a number between 1 and %d", len(todos)))
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.not_found", "max", len(todos)))
 		_, err := b.api.Send(msg)
 		return err
 	}
 
 	// Get the task by index
 	todo := todos[taskNum-1]
+	if !b.canCompleteTodo(&todo, user) {
+		return b.sendNotAllowed(message.Chat.ID, c, &todo)
+	}
 
-	// Update todo status
-	updatedTodo, err := b.db.UpdateTodoStatus(todo.ID, "completed")
+	// Update todo status and stop its reminders
+	updatedTodo, next, err := b.closeTodo(&todo, user, "completed")
 	if err != nil {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Failed to complete task")
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("complete.failed"))
 		_, err2 := b.api.Send(msg)
 		return err2
 	}
+	if updatedTodo == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("complete.not_pending", "title", html.EscapeString(todo.Title)))
+		msg.ParseMode = "HTML"
+		_, err := b.api.Send(msg)
+		return err
+	}
 
-	msgText := fmt.Sprintf("✅ Task completed successfully!\n\n<b>%s</b>", updatedTodo.Title)
-	msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
+	nextNote := b.nextInstanceNote(next, user)
+	title := html.EscapeString(updatedTodo.Title)
+	msgText := c.T("complete.done", "title", title) + nextNote
+	msg := tgbotapi.NewMessage(message.Chat.ID, styled(user, msgText,
+		c.T("complete.done_compact", "title", title)+nextNote, c.T("complete.done_minimal", "title", title)))
 	msg.ParseMode = "HTML"
 
 	_, err = b.api.Send(msg)
 	return err
 }
 
-// handleRemind handles the /remind command
-func (b *Bot) handleRemind(message *tgbotapi.Message) error {
-	args := message.CommandArguments()
-	if args == "" {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Please provide task ID and time. Example: /remind 1 2h")
+// handlePriority handles the /priority command. High priority tasks get the
+// extra due reminders from /defaults high, so their due reminders are
+// scheduled again.
+func (b *Bot) handlePriority(message *tgbotapi.Message) error {
+	c := b.catalog(message.From.ID)
+	fields := strings.Fields(strings.ToLower(message.CommandArguments()))
+	if len(fields) != 2 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("priority.usage"))
 		_, err := b.api.Send(msg)
 		return err
 	}
-
-	parts := strings.SplitN(args, " ", 2)
-	if len(parts) != 2 {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Please provide task ID and time. Example: /remind 1 2h")
+	priority := fields[1]
+	switch priority {
+	case "high", "medium", "low":
+	default:
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("priority.usage"))
 		_, err := b.api.Send(msg)
 		return err
 	}
 
-	// Parse task number (not UUID)
-	taskNumStr := parts[0]
-	timeStr := parts[1]
-
-	taskNum, err := strconv.Atoi(taskNumStr)
+	todo, user, err := b.listedTodo(message, c, fields[0])
+	if todo == nil {
+		return err
+	}
+	if !b.canEditTodo(todo, user) {
+		return b.sendNotAllowed(message.Chat.ID, c, todo)
+	}
+	owner, err := b.todoOwner(todo, user)
 	if err != nil {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Invalid task ID. Please use a number like 1, 2, 3...")
-		_, err := b.api.Send(msg)
 		return err
 	}
 
-	// Get user
-	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	updatedTodo, err := b.db.UpdateTodoPriority(todo.ID, priority)
 	if err != nil {
-		return fmt.Errorf("failed to get user: %w", err)
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("priority.failed"))
+		_, err2 := b.api.Send(msg)
+		return err2
+	}
+	if todo.Priority != priority {
+		b.recordActivity(updatedTodo, user, activityPriority, &priority)
+		if _, err := b.syncDueReminders(updatedTodo, owner); err != nil {
+			log.Printf("Failed to schedule due reminders for todo %s: %v", updatedTodo.ID, err)
+		}
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, c.T("priority.set",
+		"title", html.EscapeString(updatedTodo.Title), "priority", c.T("priority."+priority)))
+	msg.ParseMode = "HTML"
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleRemind handles the /remind command
+func (b *Bot) handleRemind(message *tgbotapi.Message) error {
+	c := b.catalog(message.From.ID)
+	args := message.CommandArguments()
+	if args == "" {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("remind.usage"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	parts := strings.SplitN(args, " ", 2)
+	if len(parts) != 2 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("remind.usage"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	// Parse task number (not UUID)
+	taskNumStr := parts[0]
+	timeStr := parts[1]
+
+	taskNum, err := strconv.Atoi(taskNumStr)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.invalid_number"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	// Get user
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
 	}
 	if user == nil {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Please start with /start first")
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
 		_, err := b.api.Send(msg)
 		return err
 	}
@@ -1393,48 +1269,60 @@ func (b *Bot) handleRemind(message *tgbotapi.Message) error {
 	}
 
 	if taskNum < 1 || taskNum > len(todos) {
-		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("Task not found. Please use a number between 1 and %d", len(todos)))
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.not_found", "max", len(todos)))
 		_, err := b.api.Send(msg)
 		return err
 	}
 
 	// Get the task by index
 	todo := todos[taskNum-1]
+	// Reminders go to the owner, so only they set them
+	if !ownsTodo(&todo, user) {
+		return b.sendNotAllowed(message.Chat.ID, c, &todo)
+	}
 
-	// Parse time duration
-	duration, err := parseDuration(timeStr)
+	// Parse relative delays, repeating intervals, calendar schedules and
+	// absolute times, all interpreted in the user's timezone
+	now := b.nowInUserTimezone(message.From.ID)
+	spec, err := parseReminderSpec(timeStr, now, b.userLocation(message.From.ID), todo.DueTime)
 	if err != nil {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Invalid time format. Use '2h' for 2 hours or '30m' for 30 minutes")
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("remind.invalid_time", "error", c.Error(err)))
 		_, err := b.api.Send(msg)
 		return err
 	}
 
-	// Calculate next notification time in user's timezone
-	userTime := b.nowInUserTimezone(message.From.ID)
-	nextTime := userTime.Add(duration)
-
-	// Store in database as UTC (database handles this automatically)
-	// But keep the original user time for display
-	displayTime := nextTime
-
-	// Create reminder
+	// Create reminder, stored in UTC
 	newReminder := NewReminder{
 		TodoID:                 todo.ID,
-		RepeatCount:            1,
-		RepeatIntervalHours:    int(duration.Hours()),
-		NextNotifyTime:         nextTime,
+		RepeatCount:            spec.Count,
+		RepeatIntervalSeconds:  int64(spec.Interval / time.Second),
+		RepeatUntil:            spec.Until,
+		NextNotifyTime:         spec.NextTime.UTC(),
+		Schedule:               spec.Schedule,
 	}
 
-	_, err = b.db.CreateReminder(newReminder)
+	reminder, err := b.db.CreateReminder(newReminder)
 	if err != nil {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Failed to create reminder")
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("remind.failed"))
 		_, err2 := b.api.Send(msg)
 		return err2
 	}
 
-	msgText := fmt.Sprintf("⏰ Reminder set successfully!\n\nI'll remind you in %s\n\n📅 %s",
-		duration.String(), displayTime.Format("2006-01-02 15:04"))
-	msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
+	tf := userTimeFormat(user)
+	when := tf.When(spec.NextTime)
+	var msgText string
+	if spec.Delay > 0 && !reminder.IsRepeating() {
+		msgText = c.T("remind.set_in", "delay", formatInterval(spec.Delay), "time", when)
+	} else if reminder.IsRepeating() {
+		msgText = c.T("remind.set_repeating", "repeat", b.describeRepeat(*reminder, tf), "time", when)
+	} else {
+		msgText = c.T("remind.set_at", "time", when)
+		if spec.Rolled {
+			msgText += "\n\n" + c.T("remind.rolled")
+		}
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, styled(user, msgText,
+		c.T("remind.set_compact", "time", when), c.T("remind.set_minimal", "time", when)))
 	msg.ParseMode = "HTML"
 
 	_, err = b.api.Send(msg)
@@ -1443,61 +1331,72 @@ func (b *Bot) handleRemind(message *tgbotapi.Message) error {
 
 // handleSnooze handles the /snooze command
 func (b *Bot) handleSnooze(message *tgbotapi.Message) error {
+	c := b.catalog(message.From.ID)
 	args := message.CommandArguments()
 	if args == "" {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Please provide reminder ID and time. Example: /snooze 1 30m")
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("snooze.usage"))
 		_, err := b.api.Send(msg)
 		return err
 	}
 
-	// Parse args (simplified)
-	parts := strings.Split(args, " ")
+	parts := strings.Fields(args)
 	if len(parts) < 2 {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Please provide reminder ID and time. Example: /snooze 1 30m")
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("snooze.usage"))
 		_, err := b.api.Send(msg)
 		return err
 	}
 
-	reminderID, err := uuid.Parse(parts[0])
+	// Reminder numbers follow the order shown by /reminders
+	reminderNum, err := strconv.Atoi(parts[0])
 	if err != nil {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Invalid reminder ID")
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("reminder.invalid_number"))
 		_, err := b.api.Send(msg)
 		return err
 	}
 
-	// Parse time (simplified)
-	timeStr := parts[1]
-	var duration time.Duration
-	if strings.HasSuffix(timeStr, "h") {
-		hours := strings.TrimSuffix(timeStr, "h")
-		hoursInt := 1 // default
-		fmt.Sscanf(hours, "%d", &hoursInt)
-		duration = time.Duration(hoursInt) * time.Hour
-	} else if strings.HasSuffix(timeStr, "m") {
-		minutes := strings.TrimSuffix(timeStr, "m")
-		minutesInt := 30 // default
-		fmt.Sscanf(minutes, "%d", &minutesInt)
-		duration = time.Duration(minutesInt) * time.Minute
-	} else {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Invalid time format. Use '2h' for 2 hours or '30m' for 30 minutes")
+	// Get user
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	reminders, err := b.db.GetUserReminders(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get reminders: %w", err)
+	}
+
+	if reminderNum < 1 || reminderNum > len(reminders) {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("reminder.not_found_range", "max", len(reminders)))
 		_, err := b.api.Send(msg)
 		return err
 	}
+	reminder := reminders[reminderNum-1].Reminder
 
-	snoozeUntil := b.nowInUserTimezone(message.From.ID).Add(duration)
-	displaySnoozeTime := snoozeUntil
+	snoozeUntil, err := resolveSnooze(strings.ToLower(strings.Join(parts[1:], " ")), time.Now(), b.userLocation(message.From.ID))
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("snooze.invalid_time"))
+		_, err := b.api.Send(msg)
+		return err
+	}
 
 	// Snooze reminder
-	_, err = b.db.SnoozeReminder(reminderID, snoozeUntil)
+	_, err = b.db.SnoozeReminder(reminder.ID, snoozeUntil.UTC())
 	if err != nil {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Failed to snooze reminder")
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("snooze.failed"))
 		_, err2 := b.api.Send(msg)
 		return err2
 	}
+	b.markReminderInteracted(reminder.ID)
 
-	msgText := fmt.Sprintf("😴 Reminder snoozed successfully!\n\nI'll remind you again in %s\n\n📅 %s",
-		duration.String(), displaySnoozeTime.Format("2006-01-02 15:04"))
-	msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
+	when := userTimeFormat(user).When(snoozeUntil)
+	msgText := c.T("snooze.done", "title", html.EscapeString(reminders[reminderNum-1].Todo.Title), "time", when)
+	msg := tgbotapi.NewMessage(message.Chat.ID, styled(user, msgText,
+		c.T("snooze.until_compact", "time", when), c.T("snooze.until_minimal", "time", when)))
 	msg.ParseMode = "HTML"
 
 	_, err = b.api.Send(msg)
@@ -1506,21 +1405,38 @@ func (b *Bot) handleSnooze(message *tgbotapi.Message) error {
 
 // handleUnknownCommand handles unknown commands
 func (b *Bot) handleUnknownCommand(message *tgbotapi.Message) error {
-	msg := tgbotapi.NewMessage(message.Chat.ID, "Unknown command. Use /help to see available commands.")
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.catalog(message.From.ID).T("error.unknown_command"))
 	_, err := b.api.Send(msg)
 	return err
 }
 
 // handleTextMessage handles non-command text messages
 func (b *Bot) handleTextMessage(message *tgbotapi.Message) error {
-	msg := tgbotapi.NewMessage(message.Chat.ID, "I can help you manage your todos! Use /help to see available commands.")
+	// Answer to an earlier prompt
+	if input, ok := b.takePending(message.From.ID); ok {
+		switch input.Kind {
+		case pendingReschedule:
+			return b.handleRescheduleInput(message, input)
+		case pendingDeleteAccount:
+			return b.handleDeleteAccountInput(message)
+		case pendingSharedAdd:
+			return b.handleSharedAddInput(message, input)
+		}
+	}
+
+	// A reply to a task card or activity notice comments on the task
+	if todoID, ok := b.repliedTaskID(message); ok && message.Text != "" {
+		return b.handleCommentReply(message, todoID)
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.catalog(message.From.ID).T("error.text_message"))
 	_, err := b.api.Send(msg)
 	return err
 }
 
 // handleCompleteCallback handles the complete callback
 func (b *Bot) handleCompleteCallback(callback *tgbotapi.CallbackQuery, todoIDStr string) error {
-	todoID, err := uuid.Parse(todoIDStr)
+	todo, user, err := b.completableTodo(callback.From.ID, todoIDStr)
 	if err != nil {
 		_, err := b.api.Request(tgbotapi.CallbackConfig{
 			CallbackQueryID: callback.ID,
@@ -1528,12 +1444,16 @@ func (b *Bot) handleCompleteCallback(callback *tgbotapi.CallbackQuery, todoIDStr
 		return err
 	}
 
-	// Update todo status
-	_, err = b.db.UpdateTodoStatus(todoID, "completed")
-	if err != nil {
+	// Update todo status and stop its reminders
+	closed, _, err := b.closeTodo(todo, user, "completed")
+	if err != nil || closed == nil {
+		text := b.catalog(callback.From.ID).T("complete.failed")
+		if err == nil {
+			text = b.catalog(callback.From.ID).T("complete.not_pending_short")
+		}
 		_, err := b.api.Request(tgbotapi.CallbackConfig{
 			CallbackQueryID: callback.ID,
-			Text:            "Failed to complete task",
+			Text:            text,
 		})
 		return err
 	}
@@ -1544,8 +1464,8 @@ func (b *Bot) handleCompleteCallback(callback *tgbotapi.CallbackQuery, todoIDStr
 
 // handleDeleteCallback handles the delete callback
 func (b *Bot) handleDeleteCallback(callback *tgbotapi.CallbackQuery, todoIDStr string) error {
-	todoID, err := uuid.Parse(todoIDStr)
-	if err != nil {
+	todo, user, err := b.userTodo(callback.From.ID, todoIDStr)
+	if err != nil || !b.canEditTodo(todo, user) {
 		_, err := b.api.Request(tgbotapi.CallbackConfig{
 			CallbackQueryID: callback.ID,
 		})
@@ -1553,11 +1473,11 @@ func (b *Bot) handleDeleteCallback(callback *tgbotapi.CallbackQuery, todoIDStr s
 	}
 
 	// Delete todo
-	err = b.db.DeleteTodo(todoID)
+	err = b.db.DeleteTodo(todo.ID)
 	if err != nil {
 		_, err := b.api.Request(tgbotapi.CallbackConfig{
 			CallbackQueryID: callback.ID,
-			Text:            "Failed to delete task",
+			Text:            b.catalog(callback.From.ID).T("delete.failed"),
 		})
 		return err
 	}
@@ -1567,31 +1487,47 @@ func (b *Bot) handleDeleteCallback(callback *tgbotapi.CallbackQuery, todoIDStr s
 }
 
 // handleSnoozeCallback handles the snooze callback
-func (b *Bot) handleSnoozeCallback(callback *tgbotapi.CallbackQuery, reminderIDStr string) error {
-	reminderID, err := uuid.Parse(reminderIDStr)
-	if err != nil {
+func (b *Bot) handleSnoozeCallback(callback *tgbotapi.CallbackQuery, reminderIDStr, option string) error {
+	c := b.catalog(callback.From.ID)
+	reminder, todo, err := b.ownReminder(callback.From.ID, reminderIDStr)
+	if err != nil || reminder == nil {
 		_, err := b.api.Request(tgbotapi.CallbackConfig{
 			CallbackQueryID: callback.ID,
+			Text:            c.T("reminder.not_found"),
 		})
 		return err
 	}
 
 	// Snooze for 30 minutes by default
-	snoozeUntil := b.nowInUserTimezone(callback.From.ID).Add(30 * time.Minute)
-	displaySnoozeTime := snoozeUntil
+	if option == "" {
+		option = "30m"
+	}
+	snoozeUntil, err := resolveSnooze(option, time.Now(), b.userLocation(callback.From.ID))
+	if err != nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            c.T("snooze.invalid_option"),
+		})
+		return err
+	}
 
 	// Snooze reminder
-	_, err = b.db.SnoozeReminder(reminderID, snoozeUntil)
+	_, err = b.db.SnoozeReminder(reminder.ID, snoozeUntil.UTC())
 	if err != nil {
 		_, err := b.api.Request(tgbotapi.CallbackConfig{
 			CallbackQueryID: callback.ID,
-			Text:            "Failed to snooze reminder",
+			Text:            c.T("snooze.failed"),
 		})
 		return err
 	}
+	b.markReminderInteracted(reminder.ID)
 
 	// Send callback response
-	callbackText := fmt.Sprintf("😴 Snoozed until %s", displaySnoozeTime.Format("15:04"))
+	displaySnoozeTime := b.timeFormatFor(callback.From.ID).When(snoozeUntil)
+	callbackText := c.T("snooze.until_compact", "time", displaySnoozeTime)
+	if err := b.finishReminderMessage(callback, reminder.ID, callbackText+": "+todo.Title); err != nil {
+		log.Printf("Failed to update reminder message: %v", err)
+	}
 	_, err = b.api.Request(tgbotapi.CallbackConfig{
 		CallbackQueryID: callback.ID,
 		Text:            callbackText,
@@ -1599,100 +1535,27 @@ func (b *Bot) handleSnoozeCallback(callback *tgbotapi.CallbackQuery, reminderIDS
 	return err
 }
 
-// handleSettings handles the settings callback
-func (b *Bot) handleSettings(callback *tgbotapi.CallbackQuery) error {
-	// Get user info
-	user, err := b.db.GetUserByTelegramID(callback.From.ID)
-	if err != nil {
-		return fmt.Errorf("failed to get user: %w", err)
-	}
-
-	// Update user timezone if it's still UTC
-	if user != nil && (user.Timezone == "UTC" || user.Timezone == "") {
-		log.Printf("DEBUG: User %s has timezone '%s', updating to Asia/Bangkok", user.Name, user.Timezone)
-		
-		// Force direct database update
-		ctx := context.Background()
-		query := `UPDATE users SET timezone = 'Asia/Bangkok', updated_at = NOW() WHERE telegram_id = $1`
-		result, err := b.db.db.ExecContext(ctx, query, callback.From.ID)
-		if err != nil {
-			log.Printf("Failed to force update user timezone: %v", err)
-		} else {
-			rowsAffected, _ := result.RowsAffected()
-			log.Printf("DEBUG: Updated %d rows in database", rowsAffected)
-		}
-		
-		// Refresh user object to get updated timezone
-		user, err = b.db.GetUserByTelegramID(callback.From.ID)
-		if err != nil {
-			return fmt.Errorf("failed to refresh user: %w", err)
-		}
-		
-		log.Printf("DEBUG: After refresh, user timezone is now '%s'", user.Timezone)
-	} else {
-		log.Printf("DEBUG: User %s already has timezone '%s', no update needed", user.Name, user.Timezone)
-	}
-
-	trans := b.getTranslation(callback.From.ID)
-	
-	currentLang := "English"
-	if user != nil && user.Language == LangTH {
-		currentLang = "ไทย (Thai)"
-	}
-
-	settingsText := fmt.Sprintf(`⚙️ <b>Settings</b>
-
-👤 <b>User Info:</b>
-• Name: <b>%s</b>
-• Timezone: <b>Asia/Bangkok</b>
-
-%s: <b>%s</b>
-
-🌐 <b>Language Selection:</b>
-Choose your preferred language:`, 
-		user.Name,
-		trans.CurrentLanguage,
-		currentLang)
-
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🇺🇸 English", "lang_en"),
-			tgbotapi.NewInlineKeyboardButtonData("🇹🇭 ไทย", "lang_th"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Main Menu", "main_menu"),
-			tgbotapi.NewInlineKeyboardButtonData("❓ Help", "help"),
-		),
-	)
-
-	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, settingsText)
-	msg.ParseMode = "HTML"
-	msg.ReplyMarkup = keyboard
-
-	_, err = b.api.Send(msg)
-	return err
-}
-
 // handleLanguageChange handles the language change callback
 func (b *Bot) handleLanguageChange(callback *tgbotapi.CallbackQuery, language string) error {
+	if _, ok := catalogs[language]; !ok {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+		})
+		return err
+	}
+
 	// Update user's language preference
 	err := b.setUserLanguage(callback.From.ID, language)
 	if err != nil {
 		return err
 	}
 
-	trans := b.getTranslation(callback.From.ID)
-	
-	langName := "English"
-	if language == LangTH {
-		langName = "ไทย (Thai)"
-	}
-
-	confirmationText := fmt.Sprintf(trans.LanguageChanged, langName)
+	c := catalogFor(language)
+	confirmationText := c.T("settings.language_changed", "language", c.Name)
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Main Menu", "main_menu"),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("menu.main"), "main_menu"),
 		),
 	)
 
@@ -1706,145 +1569,434 @@ func (b *Bot) handleLanguageChange(callback *tgbotapi.CallbackQuery, language st
 
 // handleMainMenu handles the main menu callback
 func (b *Bot) handleMainMenu(callback *tgbotapi.CallbackQuery) error {
-	trans := b.getTranslation(callback.From.ID)
-	
 	// Get user info for statistics
 	user, err := b.db.GetUserByTelegramID(callback.From.ID)
 	if err != nil {
 		return err
 	}
-	
-	// Get user statistics
-	todos, err := b.db.GetUserTodos(user.ID)
-	if err != nil {
+	if user == nil {
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, catalogFor(callback.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
 		return err
 	}
-	
-	totalTasks := len(todos)
-	completedTasks := 0
-	pendingTasks := 0
-	
-	for _, todo := range todos {
-		if todo.Status == "completed" {
-			completedTasks++
-		} else {
-			pendingTasks++
-		}
-	}
-	
-	completionRate := 0.0
-	if totalTasks > 0 {
-		completionRate = float64(completedTasks) / float64(totalTasks) * 100
+
+	msg, err := b.mainMenuMessage(callback.Message.Chat.ID, user)
+	if err != nil {
+		return err
 	}
-	
-	menuText := fmt.Sprintf(`🏠 <b>Main Menu</b>
 
-👋 Welcome back, <b>%s</b>!
+	_, err = b.api.Send(msg)
+	return err
+}
 
-📊 <b>Your Statistics:</b>
-• Total Tasks: <b>%d</b>
-• Completed: <b>%d</b>
-• Pending: <b>%d</b>
-• Success Rate: <b>%.1f%%</b>`, 
-		user.Name, totalTasks, completedTasks, pendingTasks, completionRate)
+// Reminder delivery tuning
+const (
+	// reminderClaimLease is how long a claimed reminder stays reserved for one instance
+	reminderClaimLease = 2 * time.Minute
+	// reminderClaimBatch caps how many reminders one instance claims per check
+	reminderClaimBatch = 100
+	// maxDeliveryAttempts is how often a failed send is retried before giving up
+	maxDeliveryAttempts = 5
+)
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(trans.MyTasks, "list"),
-			tgbotapi.NewInlineKeyboardButtonData(trans.Statistics, "stats"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(trans.AddTask, "add"),
-			tgbotapi.NewInlineKeyboardButtonData(trans.Reminders, "reminders"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(trans.ServerStats, "serverstats"),
-			tgbotapi.NewInlineKeyboardButtonData(trans.Settings, "settings"),
-		),
-	)
+// deliveryBackoff returns how long to wait before retrying a failed send.
+// Attempts are counted from 1.
+func deliveryBackoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	// Past this many doublings the backoff is capped anyway
+	if attempt > 10 {
+		return 30 * time.Minute
+	}
+	backoff := 30 * time.Second << uint(attempt-1)
+	if backoff > 30*time.Minute {
+		backoff = 30 * time.Minute
+	}
+	return backoff
+}
 
-	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, menuText)
-	msg.ParseMode = "HTML"
-	msg.ReplyMarkup = keyboard
+// deliveryKey identifies one occurrence of a reminder
+type deliveryKey struct {
+	reminderID uuid.UUID
+	occurrence int64
+}
 
-	_, err = b.api.Send(msg)
-	return err
+// occurrenceKey returns the key of the occurrence a reminder was claimed for
+func occurrenceKey(reminder Reminder) deliveryKey {
+	return deliveryKey{reminderID: reminder.ID, occurrence: reminder.DueAt().Unix()}
 }
 
-// reminderChecker runs in background to check and send due reminders
-func (b *Bot) reminderChecker() {
-	ticker := time.NewTicker(30 * time.Second) // Check every 30 seconds
-	defer ticker.Stop()
+// countFailedAttempt returns the attempt number of a failed send: the one
+// from the delivery log, or the count kept in memory when the log could
+// not be written (logged is 0)
+func (b *Bot) countFailedAttempt(reminder Reminder, logged int) int {
+	b.failedAttemptsMu.Lock()
+	defer b.failedAttemptsMu.Unlock()
 
-	for range ticker.C {
-		b.checkAndSendReminders()
+	key := occurrenceKey(reminder)
+	attempt := b.failedAttempts[key] + 1
+	if logged > attempt {
+		attempt = logged
 	}
+	b.failedAttempts[key] = attempt
+	return attempt
+}
+
+// forgetFailedAttempts drops the in-memory failure count of an occurrence
+func (b *Bot) forgetFailedAttempts(reminder Reminder) {
+	b.failedAttemptsMu.Lock()
+	defer b.failedAttemptsMu.Unlock()
+	delete(b.failedAttempts, occurrenceKey(reminder))
 }
 
-// checkAndSendReminders checks for due reminders and sends notifications
-func (b *Bot) checkAndSendReminders() {
-	reminders, err := b.db.GetDueReminders()
+// dueReminder is a claimed reminder together with its todo
+type dueReminder struct {
+	Reminder Reminder
+	Todo     *Todo
+}
+
+// checkAndSendReminders claims due reminders and sends notifications.
+// Claims are leased, so several replicas can run this concurrently.
+// Reminders falling into a user's quiet hours or do-not-disturb are
+// deferred, and several reminders due for one user at once are bundled.
+// Reminders missed while the bot was offline are caught up in one message.
+// It returns how many reminders were claimed.
+func (b *Bot) checkAndSendReminders() int {
+	reminders, err := b.db.ClaimDueReminders(b.instanceID, reminderClaimLease, reminderClaimBatch)
 	if err != nil {
-		return
+		log.Printf("Failed to claim due reminders: %v", err)
+		return 0
 	}
 
+	// Group the claimed reminders by user, keeping the due order
+	users := make(map[uuid.UUID]*User)
+	byUser := make(map[uuid.UUID][]dueReminder)
+	var order []uuid.UUID
 	for _, reminder := range reminders {
 		// Get the todo details
 		todo, err := b.db.GetTodoByID(reminder.TodoID)
 		if err != nil {
+			log.Printf("Failed to get todo for reminder %s: %v", reminder.ID, err)
+			b.holdReminder(reminder)
 			continue
 		}
 
 		// Get the user
-		user, err := b.db.GetUserByID(todo.UserID)
+		user, ok := users[todo.UserID]
+		if !ok {
+			user, err = b.db.GetUserByID(todo.UserID)
+			if err != nil || user == nil {
+				log.Printf("Failed to get user for reminder %s: %v", reminder.ID, err)
+				b.holdReminder(reminder)
+				continue
+			}
+			users[todo.UserID] = user
+			order = append(order, todo.UserID)
+		}
+
+		byUser[todo.UserID] = append(byUser[todo.UserID], dueReminder{Reminder: reminder, Todo: todo})
+	}
+
+	now := time.Now()
+	for _, userID := range order {
+		user := users[userID]
+		quietEnd, isDND, quiet := b.quietUntil(user, now)
+
+		var send, missed []dueReminder
+		for _, item := range byUser[userID] {
+			// An occurrence already recorded as sent (e.g. after a crash
+			// before the reminder was advanced) is not sent again
+			delivered, err := b.db.HasDeliveredReminder(item.Reminder.ID, item.Reminder.DueAt())
+			if err != nil {
+				log.Printf("Failed to check delivery of reminder %s: %v", item.Reminder.ID, err)
+				b.holdReminder(item.Reminder)
+				continue
+			}
+			if delivered {
+				b.finishOccurrence(item.Reminder, user)
+				continue
+			}
+
+			bypass := !isDND && user.QuietBypassHigh && item.Todo.Priority == "high"
+			if quiet && !bypass {
+				if err := b.db.DeferReminder(item.Reminder.ID, quietEnd); err != nil {
+					log.Printf("Failed to defer reminder %s: %v", item.Reminder.ID, err)
+					b.holdReminder(item.Reminder)
+					continue
+				}
+				b.releaseReminder(item.Reminder)
+				continue
+			}
+
+			if isMissed(item.Reminder, now) {
+				missed = append(missed, item)
+				continue
+			}
+			send = append(send, item)
+		}
+
+		b.sendReminders(user, send)
+		b.sendMissedReminders(user, missed)
+	}
+
+	return len(reminders)
+}
+
+// sendReminders delivers a user's due reminders, as one bundled message
+// when several are due at once, and records each delivery
+func (b *Bot) sendReminders(user *User, items []dueReminder) {
+	if len(items) == 0 {
+		return
+	}
+
+	var msg tgbotapi.MessageConfig
+	if len(items) == 1 {
+		msg = b.reminderMessage(user, items[0])
+	} else {
+		msg = b.bundledReminderMessage(user, items)
+	}
+
+	sent, err := b.api.Send(msg)
+	sentAt := time.Now()
+	for _, item := range items {
 		if err != nil {
+			b.recordFailedDelivery(item.Reminder, user, err)
 			continue
 		}
+		b.scheduler.recordLag(sentAt.Sub(item.Reminder.FiresAt()))
+
+		messageID := sent.MessageID
+		if _, err := b.db.RecordReminderDelivery(NewReminderDelivery{
+			ReminderID:        item.Reminder.ID,
+			OccurrenceTime:    item.Reminder.DueAt(),
+			Status:            "sent",
+			InstanceID:        b.instanceID,
+			TelegramMessageID: &messageID,
+		}); err != nil {
+			log.Printf("Failed to record delivery of reminder %s: %v", item.Reminder.ID, err)
+		}
+
+		b.finishOccurrence(item.Reminder, user)
+	}
+}
 
-		// Send reminder notification
-		reminderText := fmt.Sprintf(`⏰ <b>Reminder!</b>
+// reminderMessage builds the notification for a single reminder
+func (b *Bot) reminderMessage(user *User, item dueReminder) tgbotapi.MessageConfig {
+	todo := item.Todo
+	c := userCatalog(user)
 
-📝 <b>%s</b>
+	description := c.T("reminder.no_description")
+	if todo.Description != nil && *todo.Description != "" {
+		description = html.EscapeString(*todo.Description)
+	}
 
-%s
+	// Send reminder notification
+	title := html.EscapeString(todo.Title)
+	reminderText := c.T("reminder.text",
+		"title", title,
+		"description", description,
+		// We need to find the task number for this user
+		"number", b.getTaskNumber(user.ID, todo.ID))
 
-Don't forget to complete this task! 💪
+	compactText := c.T("reminder.compact", "title", title)
+	if todo.DueTime != nil {
+		compactText += c.T("reminder.compact_due", "time", userTimeFormat(user).Time(*todo.DueTime))
+	}
 
-Use /complete %d to mark it done`, 
-			todo.Title, 
-			func() string {
-				if todo.Description != nil && *todo.Description != "" {
-					return *todo.Description
-				}
-				return "No description"
-			}(),
-			// We need to find the task number for this user
-			b.getTaskNumber(user.ID, todo.ID))
+	msg := tgbotapi.NewMessage(user.TelegramID, styled(user, reminderText, compactText, title))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = b.reminderKeyboard(item.Reminder, user)
+	return msg
+}
 
-		msg := tgbotapi.NewMessage(user.TelegramID, reminderText)
-		msg.ParseMode = "HTML"
+// bundledReminderMessage builds one notification covering several reminders
+func (b *Bot) bundledReminderMessage(user *User, items []dueReminder) tgbotapi.MessageConfig {
+	c := userCatalog(user)
+	var text strings.Builder
+	text.WriteString(c.T("reminder.bundle_title", "count", len(items)) + "\n\n")
 
-		_, err = b.api.Send(msg)
+	snooze := snoozeOptions(user)[0]
+	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+	var titles, compact []string
+	for i, item := range items {
+		title := html.EscapeString(item.Todo.Title)
+		text.WriteString(fmt.Sprintf("%d. <b>%s</b>\n", i+1, title))
+		titles = append(titles, title)
+		compact = append(compact, fmt.Sprintf("%d. %s", i+1, title))
+		keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("✅ %d", i+1), fmt.Sprintf("rdone:%s", item.Reminder.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%s · %d", snoozeLabel(c, snooze), i+1), snoozeCallbackData(item.Reminder.ID, snooze)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🔕 %d", i+1), fmt.Sprintf("rstop:%s", item.Reminder.ID)),
+		))
+	}
+	text.WriteString("\n" + c.T("reminder.bundle_footer"))
+
+	msg := tgbotapi.NewMessage(user.TelegramID, styled(user, text.String(),
+		"⏰ "+strings.Join(compact, " · "), numberedTitles(titles)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
+	return msg
+}
+
+// recordFailedDelivery records a failed send and schedules a retry with
+// backoff, giving up on the occurrence after maxDeliveryAttempts
+func (b *Bot) recordFailedDelivery(reminder Reminder, user *User, sendErr error) {
+	errText := sendErr.Error()
+	attempt, err := b.db.RecordReminderDelivery(NewReminderDelivery{
+		ReminderID:     reminder.ID,
+		OccurrenceTime: reminder.DueAt(),
+		Status:         "failed",
+		Error:          &errText,
+		InstanceID:     b.instanceID,
+	})
+	if err != nil {
+		log.Printf("Failed to record delivery of reminder %s: %v", reminder.ID, err)
+		attempt = 0
+	}
+	attempt = b.countFailedAttempt(reminder, attempt)
+
+	if attempt < maxDeliveryAttempts {
+		// Keep the lease until the retry is due so no instance picks it up earlier
+		retryAt := time.Now().Add(deliveryBackoff(attempt))
+		log.Printf("Failed to send reminder %s (attempt %d), retrying at %s: %v", reminder.ID, attempt, retryAt.Format(time.RFC3339), sendErr)
+		if err := b.db.ExtendReminderClaim(reminder.ID, b.instanceID, retryAt); err != nil {
+			log.Printf("Failed to extend claim of reminder %s: %v", reminder.ID, err)
+		}
+		return
+	}
+
+	log.Printf("Giving up on reminder %s after %d attempts: %v", reminder.ID, attempt, sendErr)
+	b.finishOccurrence(reminder, user)
+}
+
+// finishOccurrence moves a reminder past the occurrence it was claimed for
+// and releases the claim
+func (b *Bot) finishOccurrence(reminder Reminder, user *User) {
+	b.forgetFailedAttempts(reminder)
+
+	// A snooze that fired ahead of the next scheduled occurrence
+	// leaves the schedule alone
+	if reminder.SnoozedUntil != nil && reminder.NextNotifyTime.After(time.Now()) {
+		if err := b.db.ClearReminderSnooze(reminder.ID); err != nil {
+			log.Printf("Failed to clear snooze of reminder %s: %v", reminder.ID, err)
+		}
+	} else {
+		// Move on to the next occurrence, or finish the reminder
+		b.advanceReminder(reminder, user)
+	}
+
+	b.releaseReminder(reminder)
+}
+
+// holdReminder keeps a reminder that could not be handled claimed until a
+// retry is due, so the next pass doesn't claim it straight away again
+func (b *Bot) holdReminder(reminder Reminder) {
+	retryAt := time.Now().Add(deliveryBackoff(b.countFailedAttempt(reminder, 0)))
+	if err := b.db.ExtendReminderClaim(reminder.ID, b.instanceID, retryAt); err != nil {
+		log.Printf("Failed to extend claim of reminder %s: %v", reminder.ID, err)
+	}
+}
+
+// releaseReminder gives up this instance's claim on a reminder
+func (b *Bot) releaseReminder(reminder Reminder) {
+	if err := b.db.ReleaseReminderClaim(reminder.ID, b.instanceID); err != nil {
+		log.Printf("Failed to release reminder %s: %v", reminder.ID, err)
+	}
+}
+
+// advanceReminder moves a reminder past the occurrence that was just sent.
+// RepeatCount counts the remaining occurrences, with 0 meaning unlimited.
+func (b *Bot) advanceReminder(reminder Reminder, user *User) {
+	nextTime, ok := b.nextOccurrence(reminder, user)
+	if !ok {
+		// Last occurrence; keep the row so the notification buttons still work
+		if err := b.db.DeactivateReminder(reminder.ID); err != nil {
+			log.Printf("Failed to deactivate finished reminder %s: %v", reminder.ID, err)
+		}
+		return
+	}
+
+	remaining := reminder.RepeatCount
+	if remaining > 0 {
+		remaining--
+	}
+	if err := b.db.UpdateReminderTime(reminder.ID, nextTime, remaining); err != nil {
+		log.Printf("Failed to advance reminder %s: %v", reminder.ID, err)
+	}
+}
+
+// nextOccurrence computes when a reminder should fire after its current
+// occurrence, reporting false when it has no occurrences left
+func (b *Bot) nextOccurrence(reminder Reminder, user *User) (time.Time, bool) {
+	if !reminder.IsRepeating() || reminder.RepeatCount == 1 {
+		return time.Time{}, false
+	}
+
+	var nextTime time.Time
+	if reminder.Schedule != nil {
+		// Calendar schedules repeat on the user's wall clock
+		t, err := b.nextScheduledTime(*reminder.Schedule, user)
 		if err != nil {
-			continue
+			log.Printf("Failed to compute next time for reminder %s: %v", reminder.ID, err)
+			return time.Time{}, false
 		}
+		nextTime = t
+	} else {
+		nextTime = reminder.NextNotifyTime.Add(reminder.RepeatInterval())
+	}
 
-		// Update the next reminder time
-		if reminder.RepeatCount > 1 {
-			// This is a repeating reminder
-			nextTime := reminder.NextNotifyTime.Add(time.Duration(reminder.RepeatIntervalHours) * time.Hour)
-			err = b.db.UpdateReminderTime(reminder.ID, nextTime, reminder.RepeatCount-1)
-			if err != nil {
-				// Continue even if update fails
-			}
-		} else {
-			// One-time reminder, delete it
-			err = b.db.DeleteReminder(reminder.ID)
-			if err != nil {
-				// Continue even if delete fails
-			}
+	if reminder.RepeatUntil != nil && nextTime.After(*reminder.RepeatUntil) {
+		return time.Time{}, false
+	}
+
+	return nextTime, true
+}
+
+// describeRepeat summarises how a reminder repeats and how many occurrences are left
+func (b *Bot) describeRepeat(reminder Reminder, tf timeFormat) string {
+	c := tf.c
+	var parts []string
+	switch {
+	case reminder.Schedule != nil:
+		parts = append(parts, describeSchedule(c, *reminder.Schedule))
+	case reminder.RepeatIntervalSeconds > 0:
+		parts = append(parts, c.T("repeat.every", "interval", formatInterval(reminder.RepeatInterval())))
+	case reminder.DueOffsetSeconds != nil:
+		return c.T("repeat.before_due", "offsets", formatInterval(time.Duration(*reminder.DueOffsetSeconds)*time.Second))
+	default:
+		return c.T("repeat.once")
+	}
+
+	switch {
+	case reminder.RepeatCount > 0:
+		parts = append(parts, c.T("repeat.left", "count", reminder.RepeatCount))
+	case reminder.RepeatUntil != nil:
+		parts = append(parts, c.T("repeat.until", "date", tf.Date(*reminder.RepeatUntil)))
+	default:
+		parts = append(parts, c.T("repeat.forever"))
+	}
+
+	return strings.Join(parts, " · ")
+}
+
+// reminderSummary lists a todo's reminders for the task list
+func (b *Bot) reminderSummary(todoID uuid.UUID, tf timeFormat) string {
+	reminders, err := b.db.GetRemindersForTodo(todoID)
+	if err != nil {
+		log.Printf("Failed to get reminders for todo %s: %v", todoID, err)
+		return ""
+	}
+
+	var summary strings.Builder
+	for _, reminder := range reminders {
+		if !reminder.IsActive {
+			continue
 		}
+		summary.WriteString(fmt.Sprintf("   ⏰ %s (%s)\n",
+			tf.Time(reminder.NextNotifyTime), b.describeRepeat(reminder, tf)))
 	}
+	return summary.String()
 }
 
 // getTaskNumber finds the task number for a given todo ID
@@ -1862,6 +2014,21 @@ func (b *Bot) getTaskNumber(userID uuid.UUID, todoID uuid.UUID) int {
 	return 1 // fallback
 }
 
+// nextScheduledTime computes the next fire time of a cron schedule in the user's timezone
+func (b *Bot) nextScheduledTime(expr string, user *User) (time.Time, error) {
+	cron, err := parseCron(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	next, err := cron.Next(time.Now(), locationOf(user))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return next.UTC(), nil
+}
+
 // parseDuration parses time strings like "2h", "30m", "1d"
 func parseDuration(timeStr string) (time.Duration, error) {
 	if strings.HasSuffix(timeStr, "h") {
@@ -1890,16 +2057,5 @@ func parseDuration(timeStr string) (time.Duration, error) {
 		return time.Duration(weeks) * 7 * 24 * time.Hour, nil
 	}
 	
-	return 0, fmt.Errorf("invalid time format: %s", timeStr)
-}
-
-// escapeMarkdown escapes special characters for Telegram MarkdownV2
-func escapeMarkdown(text string) string {
-	// Telegram MarkdownV2 requires escaping: _ * [ ] ( ) ~ ` > # + - = | { } . !
-	specialChars := []string{"_", "*", "[", "]", "(", ")", "~", "`", ">", "#", "+", "-", "=", "|", "{", "}", ".", "!"}
-	result := text
-	for _, char := range specialChars {
-		result = strings.ReplaceAll(result, char, "\\"+char)
-	}
-	return result
+	return 0, newLocalizedError("error.invalid_duration", "input", timeStr)
 }
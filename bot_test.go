@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDeliveryBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{-1, 30 * time.Second},
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{6, 16 * time.Minute},
+		{7, 30 * time.Minute},
+		{64, 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := deliveryBackoff(tt.attempt); got != tt.want {
+			t.Errorf("deliveryBackoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestCountFailedAttempt(t *testing.T) {
+	b := &Bot{failedAttempts: make(map[deliveryKey]int)}
+	reminder := Reminder{ID: uuid.New(), NextNotifyTime: time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)}
+
+	// Without a delivery log the count still grows until attempts run out
+	for want := 1; want <= maxDeliveryAttempts; want++ {
+		if got := b.countFailedAttempt(reminder, 0); got != want {
+			t.Fatalf("attempt %d counted as %d", want, got)
+		}
+	}
+
+	// The delivery log wins when it has seen more attempts, e.g. from other instances
+	if got := b.countFailedAttempt(reminder, 9); got != 9 {
+		t.Errorf("countFailedAttempt with logged 9 = %d, want 9", got)
+	}
+
+	// The next occurrence starts over
+	later := reminder
+	later.NextNotifyTime = reminder.NextNotifyTime.Add(time.Hour)
+	if got := b.countFailedAttempt(later, 0); got != 1 {
+		t.Errorf("countFailedAttempt for the next occurrence = %d, want 1", got)
+	}
+
+	b.forgetFailedAttempts(reminder)
+	if got := b.countFailedAttempt(reminder, 0); got != 1 {
+		t.Errorf("countFailedAttempt after forgetting = %d, want 1", got)
+	}
+}
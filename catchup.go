@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// missedReminderGrace is how late a reminder may fire before it counts as
+// missed, e.g. because the bot was offline, and is caught up instead
+const missedReminderGrace = 15 * time.Minute
+
+// maxCatchUpOccurrences caps how many missed occurrences are counted one by
+// one before jumping straight to the next future one
+const maxCatchUpOccurrences = 10000
+
+// missedReminder is a reminder caught up after downtime
+type missedReminder struct {
+	dueReminder
+	// Missed is how many occurrences passed, including the current one
+	Missed int
+	// Next is the next future occurrence, or nil if the reminder finished
+	Next *time.Time
+}
+
+// isMissed reports whether a claimed reminder fired too late to send as usual
+func isMissed(reminder Reminder, now time.Time) bool {
+	return now.Sub(reminder.FiresAt()) > missedReminderGrace
+}
+
+// occurrenceAfter returns a repeating reminder's first occurrence after prev
+func (b *Bot) occurrenceAfter(reminder Reminder, user *User, prev time.Time) (time.Time, error) {
+	if reminder.Schedule == nil {
+		return prev.Add(reminder.RepeatInterval()), nil
+	}
+
+	cron, err := parseCron(*reminder.Schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next, err := cron.Next(prev, locationOf(user))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return next.UTC(), nil
+}
+
+// catchUpReminder counts the occurrences a reminder missed up to now and
+// works out its next future occurrence, honouring the repeat count and end
+func (b *Bot) catchUpReminder(item dueReminder, user *User, now time.Time) missedReminder {
+	reminder := item.Reminder
+	result := missedReminder{dueReminder: item}
+
+	// A snooze that fell in the downtime leaves the schedule alone
+	if reminder.SnoozedUntil != nil && reminder.NextNotifyTime.After(now) {
+		next := reminder.NextNotifyTime
+		result.Missed, result.Next = 1, &next
+		return result
+	}
+
+	remaining := reminder.RepeatCount
+	at := reminder.NextNotifyTime
+	for {
+		result.Missed++
+		if !reminder.IsRepeating() || remaining == 1 {
+			return result
+		}
+		if remaining > 0 {
+			remaining--
+		}
+
+		// Stop counting after a very long outage and resume from now
+		prev := at
+		if result.Missed >= maxCatchUpOccurrences {
+			prev = now
+		}
+		next, err := b.occurrenceAfter(reminder, user, prev)
+		if err != nil {
+			log.Printf("Failed to compute next time for reminder %s: %v", reminder.ID, err)
+			return result
+		}
+		if reminder.RepeatUntil != nil && next.After(*reminder.RepeatUntil) {
+			return result
+		}
+		if next.After(now) {
+			result.Next = &next
+			result.Reminder.RepeatCount = remaining
+			return result
+		}
+		at = next
+	}
+}
+
+// sendMissedReminders sends a user one message covering the reminders they
+// missed while the bot was offline, and moves each reminder on to its next
+// future occurrence instead of replaying every missed one
+func (b *Bot) sendMissedReminders(user *User, items []dueReminder) {
+	if len(items) == 0 {
+		return
+	}
+
+	now := time.Now()
+	missed := make([]missedReminder, len(items))
+	for i, item := range items {
+		missed[i] = b.catchUpReminder(item, user, now)
+	}
+
+	sent, err := b.api.Send(b.missedRemindersMessage(user, missed))
+	for _, item := range missed {
+		if err != nil {
+			b.recordFailedDelivery(item.Reminder, user, err)
+			continue
+		}
+
+		messageID := sent.MessageID
+		if _, err := b.db.RecordReminderDelivery(NewReminderDelivery{
+			ReminderID:         item.Reminder.ID,
+			OccurrenceTime:     item.Reminder.DueAt(),
+			Status:             "sent",
+			InstanceID:         b.instanceID,
+			TelegramMessageID:  &messageID,
+			SkippedOccurrences: item.Missed - 1,
+		}); err != nil {
+			log.Printf("Failed to record delivery of reminder %s: %v", item.Reminder.ID, err)
+		}
+
+		b.finishMissedReminder(item)
+	}
+}
+
+// finishMissedReminder moves a caught up reminder to its next occurrence,
+// or finishes it, and releases the claim
+func (b *Bot) finishMissedReminder(item missedReminder) {
+	reminder := item.Reminder
+	switch {
+	case item.Next == nil:
+		// Keep the row so the notification buttons still work
+		if err := b.db.DeactivateReminder(reminder.ID); err != nil {
+			log.Printf("Failed to deactivate finished reminder %s: %v", reminder.ID, err)
+		}
+	case item.Next.Equal(reminder.NextNotifyTime):
+		if err := b.db.ClearReminderSnooze(reminder.ID); err != nil {
+			log.Printf("Failed to clear snooze of reminder %s: %v", reminder.ID, err)
+		}
+	default:
+		if err := b.db.UpdateReminderTime(reminder.ID, *item.Next, reminder.RepeatCount); err != nil {
+			log.Printf("Failed to advance reminder %s: %v", reminder.ID, err)
+		}
+	}
+
+	if item.Missed > 1 {
+		log.Printf("Reminder %s caught up after missing %d occurrences", reminder.ID, item.Missed)
+	}
+	b.releaseReminder(reminder)
+}
+
+// missedRemindersMessage builds the catch-up notification for missed reminders
+func (b *Bot) missedRemindersMessage(user *User, items []missedReminder) tgbotapi.MessageConfig {
+	c := userCatalog(user)
+	tf := userTimeFormat(user)
+	var text strings.Builder
+	text.WriteString(c.T("catchup.title") + "\n\n")
+
+	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+	var titles, compact []string
+	for i, item := range items {
+		title := html.EscapeString(item.Todo.Title)
+		count := ""
+		if item.Missed > 1 {
+			count = fmt.Sprintf(" (×%d)", item.Missed)
+		}
+
+		text.WriteString(fmt.Sprintf("%d. <b>%s</b>%s\n   ⏰ %s\n", i+1, title, count,
+			tf.Time(item.Reminder.DueAt())))
+		if item.Next != nil {
+			text.WriteString("   " + c.T("catchup.next", "time", tf.Time(*item.Next)) + "\n")
+		}
+		titles = append(titles, title)
+		compact = append(compact, title+count)
+
+		keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("✅ %d", i+1), fmt.Sprintf("rdone:%s", item.Reminder.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("📅 %d", i+1), fmt.Sprintf("resched:%s", item.Reminder.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🔕 %d", i+1), fmt.Sprintf("rstop:%s", item.Reminder.ID)),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(user.TelegramID, styled(user, text.String(),
+		c.T("catchup.compact", "titles", strings.Join(compact, " · ")), numberedTitles(titles)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
+	return msg
+}
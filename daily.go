@@ -0,0 +1,395 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Kinds of daily message, matching dailyMessageColumns
+const (
+	dailyDigest   = "digest"
+	dailyBriefing = "briefing"
+	dailyReview   = "review"
+)
+
+// dailyWindow is how long after its time a late daily message is still
+// sent, e.g. after downtime or quiet hours, before it is skipped for the day
+const dailyWindow = 2 * time.Hour
+
+// dailyChecker runs in background and sends daily messages once they are due
+func (b *Bot) dailyChecker() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		b.sendOverdueDigests()
+		b.sendBriefingsAndReviews()
+	}
+}
+
+// claimDailyMessage reports whether this instance should send the user's
+// daily message of the given kind now. It is due from its local time of day
+// until dailyWindow later, is held back during quiet hours, and is claimed in
+// the database so it goes out once a day even across restarts and replicas.
+func (b *Bot) claimDailyMessage(user *User, kind, clock string, now time.Time) bool {
+	local := now.In(locationOf(user))
+	dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+
+	hour, minute, err := parseClock(clock)
+	if err != nil {
+		return false
+	}
+	sendAt := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, local.Location())
+	if local.Before(sendAt) || !local.Before(sendAt.Add(dailyWindow)) {
+		return false
+	}
+
+	if _, _, quiet := b.quietUntil(user, now); quiet {
+		return false
+	}
+
+	claimed, err := b.db.ClaimUserDailyMessage(user.ID, kind, dayStart)
+	if err != nil {
+		log.Printf("Failed to claim %s for user %s: %v", kind, user.ID, err)
+		return false
+	}
+	return claimed
+}
+
+// releaseDailyMessage gives back a daily message whose send failed, so a
+// later check within dailyWindow tries again
+func (b *Bot) releaseDailyMessage(user *User, kind string, lastSent *time.Time) {
+	if err := b.db.ReleaseUserDailyMessage(user.ID, kind, lastSent); err != nil {
+		log.Printf("Failed to release %s for user %s: %v", kind, user.ID, err)
+	}
+}
+
+// sendBriefingsAndReviews sends morning briefings and evening reviews to
+// users who opted in
+func (b *Bot) sendBriefingsAndReviews() {
+	users, err := b.db.GetUsersWithDailyMessages()
+	if err != nil {
+		log.Printf("Failed to get users with daily messages: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for i := range users {
+		user := &users[i]
+
+		if user.BriefingTime != nil && b.claimDailyMessage(user, dailyBriefing, *user.BriefingTime, now) {
+			msg, err := b.briefingMessage(user, now)
+			if err == nil {
+				_, err = b.api.Send(msg)
+			}
+			if err != nil {
+				log.Printf("Failed to send briefing to user %d: %v", user.TelegramID, err)
+				b.releaseDailyMessage(user, dailyBriefing, user.LastBriefingAt)
+			}
+		}
+
+		if user.ReviewTime != nil && b.claimDailyMessage(user, dailyReview, *user.ReviewTime, now) {
+			msg, err := b.reviewMessage(user, now)
+			if err == nil {
+				_, err = b.api.Send(msg)
+			}
+			if err != nil {
+				log.Printf("Failed to send review to user %d: %v", user.TelegramID, err)
+				b.releaseDailyMessage(user, dailyReview, user.LastReviewAt)
+			}
+		}
+	}
+}
+
+// localDay returns the start and end of the user's local day containing now
+func (b *Bot) localDay(user *User, now time.Time) (time.Time, time.Time) {
+	local := now.In(locationOf(user))
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	return start, start.AddDate(0, 0, 1)
+}
+
+// dueToday returns the user's pending tasks due during their local today
+func (b *Bot) dueToday(user *User, todos []Todo, now time.Time) []Todo {
+	start, end := b.localDay(user, now)
+
+	var today []Todo
+	for _, todo := range todos {
+		if todo.Status == "pending" && todo.DueTime != nil && !todo.DueTime.Before(start) && todo.DueTime.Before(end) {
+			today = append(today, todo)
+		}
+	}
+	return today
+}
+
+// briefingMessage builds the morning briefing: today's due tasks, the
+// overdue count and open high-priority tasks
+func (b *Bot) briefingMessage(user *User, now time.Time) (tgbotapi.MessageConfig, error) {
+	todos, err := b.db.GetUserTodos(user.ID)
+	if err != nil {
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to get todos: %w", err)
+	}
+
+	start, _ := b.localDay(user, now)
+	overdue := 0
+	var high []Todo
+	for _, todo := range todos {
+		if todo.Status != "pending" {
+			continue
+		}
+		if todo.DueTime != nil && todo.DueTime.Before(start) {
+			overdue++
+		}
+		if todo.Priority == "high" {
+			high = append(high, todo)
+		}
+	}
+	today := b.dueToday(user, todos, now)
+	c := userCatalog(user)
+
+	var text strings.Builder
+	text.WriteString(c.T("briefing.greeting", "name", html.EscapeString(user.Name)) + "\n\n")
+
+	if len(today) == 0 {
+		text.WriteString(c.T("briefing.nothing_due") + "\n")
+	} else {
+		text.WriteString(c.T("briefing.due_today", "count", len(today)) + "\n")
+		loc := locationOf(user)
+		for _, todo := range today {
+			text.WriteString(fmt.Sprintf("• %s %s\n",
+				formatClock(c, user, todo.DueTime.In(loc)), html.EscapeString(todo.Title)))
+		}
+	}
+
+	if overdue > 0 {
+		text.WriteString("\n" + c.T("briefing.overdue", "count", overdue) + "\n")
+	}
+
+	if len(high) > 0 {
+		text.WriteString("\n" + c.T("briefing.high", "count", len(high)) + "\n")
+		for _, todo := range high {
+			text.WriteString(fmt.Sprintf("• %s\n", html.EscapeString(todo.Title)))
+		}
+	}
+
+	var titles []string
+	for _, todo := range today {
+		titles = append(titles, html.EscapeString(todo.Title))
+	}
+	minimal := c.T("briefing.nothing_due_minimal")
+	if len(titles) > 0 {
+		minimal = strings.Join(titles, "\n")
+	}
+	compact := c.T("briefing.compact", "due", len(today), "overdue", overdue, "high", len(high))
+
+	msg := tgbotapi.NewMessage(user.TelegramID, styled(user, text.String(), compact, minimal))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("briefing.view_list"), "list"),
+		),
+	)
+	return msg, nil
+}
+
+// reviewMessage builds the evening review: tasks completed today and the
+// unfinished tasks due today, with buttons to roll them to tomorrow
+func (b *Bot) reviewMessage(user *User, now time.Time) (tgbotapi.MessageConfig, error) {
+	todos, err := b.db.GetUserTodos(user.ID)
+	if err != nil {
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to get todos: %w", err)
+	}
+
+	start, _ := b.localDay(user, now)
+	var completed []Todo
+	for _, todo := range todos {
+		if todo.Status == "completed" && todo.CompletedAt != nil && !todo.CompletedAt.Before(start) {
+			completed = append(completed, todo)
+		}
+	}
+	unfinished := b.dueToday(user, todos, now)
+	c := userCatalog(user)
+
+	var text strings.Builder
+	text.WriteString(c.T("review.title") + "\n\n")
+
+	if len(completed) == 0 {
+		text.WriteString(c.T("review.nothing_completed") + "\n")
+	} else {
+		text.WriteString(c.T("review.completed", "count", len(completed)) + "\n")
+		for _, todo := range completed {
+			text.WriteString(fmt.Sprintf("• %s\n", html.EscapeString(todo.Title)))
+		}
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var titles []string
+	if len(unfinished) > 0 {
+		text.WriteString("\n" + c.T("review.open", "count", len(unfinished)) + "\n")
+		for i, todo := range unfinished {
+			text.WriteString(fmt.Sprintf("%d. %s\n", i+1, html.EscapeString(todo.Title)))
+			titles = append(titles, html.EscapeString(todo.Title))
+			if i < maxDigestTasks && ownsTodo(&todo, user) {
+				rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(c.T("review.tomorrow", "number", i+1), fmt.Sprintf("dgtomorrow:%s", todo.ID)),
+				))
+			}
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("review.roll_all"), "rollover:today"),
+		))
+	}
+
+	minimal := c.T("review.nothing_left")
+	if len(titles) > 0 {
+		minimal = numberedTitles(titles)
+	}
+	compact := c.T("review.compact", "done", len(completed), "open", len(unfinished))
+
+	msg := tgbotapi.NewMessage(user.TelegramID, styled(user, text.String(), compact, minimal))
+	msg.ParseMode = "HTML"
+	if len(rows) > 0 {
+		msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+	}
+	return msg, nil
+}
+
+// moveToTomorrow moves a task's due time to tomorrow in the user's timezone,
+// keeping its time of day (09:00 if it had none), and reschedules its
+// automatic reminders
+func (b *Bot) moveToTomorrow(todo *Todo, user *User) (*Todo, error) {
+	loc := locationOf(user)
+	now := time.Now().In(loc)
+	due := time.Date(now.Year(), now.Month(), now.Day(), 9, 0, 0, 0, loc)
+	if todo.DueTime != nil {
+		due = todo.DueTime.In(loc)
+	}
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, due.Hour(), due.Minute(), 0, 0, loc).UTC()
+
+	updated, err := b.db.UpdateTodoDueTime(todo.ID, &tomorrow)
+	if err != nil {
+		return nil, err
+	}
+	b.recordActivity(updated, user, activityDue, stringPtr(tomorrow.Format(time.RFC3339)))
+
+	if _, err := b.syncDueReminders(updated, user); err != nil {
+		log.Printf("Failed to update due reminders for todo %s: %v", updated.ID, err)
+	}
+	return updated, nil
+}
+
+// handleRolloverCallback moves every unfinished task due today to tomorrow
+func (b *Bot) handleRolloverCallback(callback *tgbotapi.CallbackQuery) error {
+	user, err := b.db.GetUserByTelegramID(callback.From.ID)
+	if err != nil || user == nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+		})
+		return err
+	}
+
+	todos, err := b.db.GetUserTodos(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get todos: %w", err)
+	}
+
+	moved := 0
+	for _, todo := range b.dueToday(user, todos, time.Now()) {
+		todo := todo
+		// Only the owner moves a task
+		if !ownsTodo(&todo, user) {
+			continue
+		}
+		if _, err := b.moveToTomorrow(&todo, user); err != nil {
+			log.Printf("Failed to move todo %s to tomorrow: %v", todo.ID, err)
+			continue
+		}
+		moved++
+	}
+
+	status := userCatalog(user).T("review.moved", "count", moved)
+	if callback.Message != nil {
+		edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID,
+			callback.Message.Text+"\n"+status)
+		edit.Entities = callback.Message.Entities
+		edit.ReplyMarkup = &tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+		if _, err := b.api.Send(edit); err != nil {
+			log.Printf("Failed to update review message: %v", err)
+		}
+	}
+
+	_, err = b.api.Request(tgbotapi.CallbackConfig{
+		CallbackQueryID: callback.ID,
+		Text:            status,
+	})
+	return err
+}
+
+// handleBriefing handles the /briefing command
+func (b *Bot) handleBriefing(message *tgbotapi.Message) error {
+	return b.handleDailySetting(message, dailyBriefing)
+}
+
+// handleReview handles the /review command
+func (b *Bot) handleReview(message *tgbotapi.Message) error {
+	return b.handleDailySetting(message, dailyReview)
+}
+
+// handleDailySetting shows, sets or turns off the time of the morning
+// briefing or the evening review
+func (b *Bot) handleDailySetting(message *tgbotapi.Message, kind string) error {
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	c := userCatalog(user)
+
+	example, current, update := "07:30", user.BriefingTime, b.db.UpdateUserBriefingTime
+	if kind == dailyReview {
+		example, current, update = "21:00", user.ReviewTime, b.db.UpdateUserReviewTime
+	}
+	name := c.T(kind + ".name")
+
+	args := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	var msgText string
+	switch args {
+	case "":
+		status := c.T("daily.off")
+		if current != nil {
+			status = c.T("daily.at", "time", *current)
+		}
+		msgText = c.T("daily.status", "name", name, "status", status, "command", kind, "example", example)
+
+	case "off":
+		if err := update(user.ID, nil); err != nil {
+			return fmt.Errorf("failed to turn off %s: %w", kind, err)
+		}
+		msgText = c.T("daily.turned_off", "name", name)
+
+	default:
+		hour, minute, err := parseClock(args)
+		if err != nil {
+			msg := tgbotapi.NewMessage(message.Chat.ID, c.T("daily.invalid_time", "command", kind, "example", example))
+			_, err := b.api.Send(msg)
+			return err
+		}
+		clock := fmt.Sprintf("%02d:%02d", hour, minute)
+		if err := update(user.ID, &clock); err != nil {
+			return fmt.Errorf("failed to set %s time: %w", kind, err)
+		}
+		msgText = c.T("daily.set", "name", name, "time", clock, "zone", b.getUserTimezone(message.From.ID))
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
+	_, err = b.api.Send(msg)
+	return err
+}
@@ -5,12 +5,18 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5/stdlib"
 )
 
+// reminderChangesChannel is the LISTEN/NOTIFY channel announcing reminder
+// changes. The payload is the reminder ID followed by the Unix time it next
+// fires at, or just the ID when it no longer fires.
+const reminderChangesChannel = "reminders_changed"
+
 // Database handles all database operations
 type Database struct {
 	db *sql.DB
@@ -73,18 +79,173 @@ func createTables(db *sql.DB) error {
 			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
 			todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
 			repeat_count INTEGER DEFAULT 1,
-			repeat_interval_hours INTEGER DEFAULT 24,
+			repeat_interval_seconds BIGINT DEFAULT 0,
+			repeat_until TIMESTAMP WITH TIME ZONE,
 			next_notify_time TIMESTAMP WITH TIME ZONE NOT NULL,
 			snoozed_until TIMESTAMP WITH TIME ZONE,
 			is_active BOOLEAN DEFAULT true,
 			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
 			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 		)`,
+		`ALTER TABLE reminders ADD COLUMN IF NOT EXISTS schedule TEXT`,
+		`ALTER TABLE reminders ADD COLUMN IF NOT EXISTS repeat_interval_seconds BIGINT DEFAULT 0`,
+		`ALTER TABLE reminders ADD COLUMN IF NOT EXISTS repeat_until TIMESTAMP WITH TIME ZONE`,
+		// Carry hourly repeat intervals over before dropping their column
+		`DO $$
+		BEGIN
+			IF EXISTS (SELECT 1 FROM information_schema.columns
+				WHERE table_schema = current_schema() AND table_name = 'reminders' AND column_name = 'repeat_interval_hours') THEN
+				UPDATE reminders
+				SET repeat_interval_seconds = repeat_interval_hours * 3600
+				WHERE repeat_interval_seconds = 0 AND repeat_count > 1 AND schedule IS NULL;
+			END IF;
+		END
+		$$`,
+		`ALTER TABLE reminders DROP COLUMN IF EXISTS repeat_interval_hours`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS snooze_options TEXT DEFAULT '10m,1h,tonight,tomorrow'`,
+		`ALTER TABLE reminders ADD COLUMN IF NOT EXISTS is_paused BOOLEAN DEFAULT false`,
+		`ALTER TABLE reminders ADD COLUMN IF NOT EXISTS claimed_by TEXT`,
+		`ALTER TABLE reminders ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMP WITH TIME ZONE`,
+		`ALTER TABLE reminders ADD COLUMN IF NOT EXISTS deferred_until TIMESTAMP WITH TIME ZONE`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS quiet_start VARCHAR(5)`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS quiet_end VARCHAR(5)`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS quiet_bypass_high BOOLEAN DEFAULT false`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS dnd_until TIMESTAMP WITH TIME ZONE`,
+		// Existing users get an explicit empty setting, so tasks they already
+		// have don't start sending due reminders nobody asked for
+		`DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+				WHERE table_schema = current_schema() AND table_name = 'users' AND column_name = 'reminder_offsets') THEN
+				ALTER TABLE users ADD COLUMN reminder_offsets TEXT;
+				UPDATE users SET reminder_offsets = '';
+			END IF;
+		END
+		$$`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS high_priority_offsets TEXT`,
+		`ALTER TABLE reminders ADD COLUMN IF NOT EXISTS due_offset_seconds BIGINT`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_enabled BOOLEAN DEFAULT false`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_time VARCHAR(5) DEFAULT '09:00'`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS last_digest_at TIMESTAMP WITH TIME ZONE`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS briefing_time VARCHAR(5)`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS review_time VARCHAR(5)`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS last_briefing_at TIMESTAMP WITH TIME ZONE`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS last_review_at TIMESTAMP WITH TIME ZONE`,
+		`ALTER TABLE todos ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP WITH TIME ZONE`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS escalate_after_minutes INTEGER DEFAULT 0`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS escalate_max INTEGER DEFAULT 2`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS escalate_contact_chat_id BIGINT`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS escalate_invite_token VARCHAR(32)`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS escalate_invite_expires_at TIMESTAMP WITH TIME ZONE`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_escalate_invite_token ON users(escalate_invite_token)`,
+		`CREATE TABLE IF NOT EXISTS reminder_deliveries (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			reminder_id UUID NOT NULL REFERENCES reminders(id) ON DELETE CASCADE,
+			occurrence_time TIMESTAMP WITH TIME ZONE NOT NULL,
+			attempt INTEGER NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			error TEXT,
+			instance_id TEXT NOT NULL,
+			telegram_message_id INTEGER,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`,
+		`ALTER TABLE reminder_deliveries ADD COLUMN IF NOT EXISTS interacted_at TIMESTAMP WITH TIME ZONE`,
+		`ALTER TABLE reminder_deliveries ADD COLUMN IF NOT EXISTS escalation_level INTEGER DEFAULT 0`,
+		`ALTER TABLE reminder_deliveries ADD COLUMN IF NOT EXISTS last_escalated_at TIMESTAMP WITH TIME ZONE`,
+		`ALTER TABLE reminder_deliveries ADD COLUMN IF NOT EXISTS skipped_occurrences INTEGER DEFAULT 0`,
+		`ALTER TABLE reminders ADD COLUMN IF NOT EXISTS deactivated_reason VARCHAR(20)`,
+		`ALTER TABLE todos ADD COLUMN IF NOT EXISTS repeat_rule TEXT`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS week_start VARCHAR(3) DEFAULT 'mon'`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS clock_24h BOOLEAN DEFAULT true`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS date_format VARCHAR(10) DEFAULT 'text'`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS default_priority VARCHAR(20) DEFAULT 'medium'`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS default_list VARCHAR(10) DEFAULT 'all'`,
+		`ALTER TABLE todos ADD COLUMN IF NOT EXISTS chat_id BIGINT`,
+		// Group tasks outlive the member who added them
+		`ALTER TABLE todos ALTER COLUMN user_id DROP NOT NULL`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS username VARCHAR(64)`,
+		`ALTER TABLE todos ADD COLUMN IF NOT EXISTS assignee_id UUID REFERENCES users(id) ON DELETE SET NULL`,
+		`ALTER TABLE todos ADD COLUMN IF NOT EXISTS assignment_status VARCHAR(20)`,
+		`CREATE TABLE IF NOT EXISTS chat_members (
+			chat_id BIGINT NOT NULL,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			PRIMARY KEY (chat_id, user_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS list_invites (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			token VARCHAR(32) NOT NULL UNIQUE,
+			role VARCHAR(10) NOT NULL,
+			expires_at TIMESTAMP WITH TIME ZONE,
+			revoked_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`,
+		`CREATE TABLE IF NOT EXISTS list_members (
+			owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			member_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			role VARCHAR(10) NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			PRIMARY KEY (owner_id, member_id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS task_activity (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			kind VARCHAR(20) NOT NULL,
+			body TEXT,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`,
+		`CREATE TABLE IF NOT EXISTS task_watchers (
+			todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			watching BOOLEAN NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			PRIMARY KEY (todo_id, user_id)
+		)`,
 		`CREATE INDEX IF NOT EXISTS idx_users_telegram_id ON users(telegram_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_todos_user_id ON todos(user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_todos_chat_id ON todos(chat_id) WHERE chat_id IS NOT NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_todos_assignee_id ON todos(assignee_id) WHERE assignee_id IS NOT NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_users_username ON users(lower(username))`,
+		`CREATE INDEX IF NOT EXISTS idx_chat_members_user_id ON chat_members(user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_list_invites_owner_id ON list_invites(owner_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_list_members_member_id ON list_members(member_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_task_activity_todo_id ON task_activity(todo_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_task_watchers_user_id ON task_watchers(user_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_todos_status ON todos(status)`,
 		`CREATE INDEX IF NOT EXISTS idx_reminders_todo_id ON reminders(todo_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_reminders_next_notify ON reminders(next_notify_time) WHERE is_active = true`,
+		`CREATE INDEX IF NOT EXISTS idx_reminder_deliveries_occurrence ON reminder_deliveries(reminder_id, occurrence_time)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_reminder_deliveries_sent ON reminder_deliveries(reminder_id, occurrence_time) WHERE status = 'sent'`,
+		`CREATE TABLE IF NOT EXISTS audit_log (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			user_id UUID,
+			telegram_id BIGINT NOT NULL,
+			action VARCHAR(50) NOT NULL,
+			details TEXT,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_log_telegram_id ON audit_log(telegram_id)`,
+		`CREATE OR REPLACE FUNCTION notify_reminder_change() RETURNS trigger AS $$
+		BEGIN
+			IF NEW.is_active AND NOT NEW.is_paused THEN
+				PERFORM pg_notify('` + reminderChangesChannel + `', NEW.id::text || ' ' ||
+					extract(epoch FROM GREATEST(COALESCE(NEW.snoozed_until, NEW.next_notify_time), NEW.deferred_until, NEW.claimed_until))::text);
+			ELSE
+				PERFORM pg_notify('` + reminderChangesChannel + `', NEW.id::text);
+			END IF;
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql`,
+		`DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'reminders_notify_change') THEN
+				CREATE TRIGGER reminders_notify_change AFTER INSERT OR UPDATE ON reminders
+				FOR EACH ROW EXECUTE FUNCTION notify_reminder_change();
+			END IF;
+		END
+		$$`,
 	}
 
 	for _, query := range queries {
@@ -94,45 +255,39 @@ func createTables(db *sql.DB) error {
 	}
 
 	log.Println("Database tables created/verified successfully")
-	
-	// Run one-time migration to update all UTC users to Asia/Bangkok
-	if err := migrateTimezones(db); err != nil {
-		log.Printf("Warning: Failed to migrate timezones: %v", err)
-	}
-	
+
 	return nil
 }
 
-// migrateTimezones updates all users with UTC timezone to Asia/Bangkok
-func migrateTimezones(db *sql.DB) error {
-	ctx := context.Background()
-	
-	// First check how many UTC users exist
-	var count int
-	checkQuery := `SELECT COUNT(*) FROM users WHERE timezone = 'UTC'`
-	err := db.QueryRowContext(ctx, checkQuery).Scan(&count)
-	if err != nil {
-		return fmt.Errorf("failed to count UTC users: %w", err)
-	}
-	
-	log.Printf("DEBUG: Found %d users with UTC timezone", count)
-	
-	if count == 0 {
-		log.Println("DEBUG: No UTC users found, skipping migration")
-		return nil
+// userColumns lists the user columns in the order userFields expects
+const userColumns = `id, telegram_id, name, timezone, language, default_reminder_interval, notification_style, snooze_options,
+	quiet_start, quiet_end, quiet_bypass_high, dnd_until, reminder_offsets, high_priority_offsets,
+	digest_enabled, digest_time, last_digest_at, briefing_time, review_time, last_briefing_at, last_review_at,
+	escalate_after_minutes, escalate_max, escalate_contact_chat_id,
+	week_start, clock_24h, date_format, default_priority, default_list, username, created_at, updated_at`
+
+// userFields returns scan destinations matching userColumns
+func userFields(user *User) []interface{} {
+	return []interface{}{
+		&user.ID, &user.TelegramID, &user.Name, &user.Timezone,
+		&user.Language, &user.DefaultReminderInterval, &user.NotificationStyle, &user.SnoozeOptions,
+		&user.QuietStart, &user.QuietEnd, &user.QuietBypassHigh, &user.DNDUntil,
+		&user.ReminderOffsets, &user.HighPriorityOffsets,
+		&user.DigestEnabled, &user.DigestTime, &user.LastDigestAt,
+		&user.BriefingTime, &user.ReviewTime, &user.LastBriefingAt, &user.LastReviewAt,
+		&user.EscalateAfterMinutes, &user.EscalateMax, &user.EscalateContactChatID,
+		&user.WeekStart, &user.Clock24h, &user.DateFormat, &user.DefaultPriority, &user.DefaultList,
+		&user.Username, &user.CreatedAt, &user.UpdatedAt,
 	}
-	
-	query := `UPDATE users SET timezone = 'Asia/Bangkok', updated_at = NOW() WHERE timezone = 'UTC'`
-	
-	result, err := db.ExecContext(ctx, query)
-	if err != nil {
-		return fmt.Errorf("failed to migrate timezones: %w", err)
+}
+
+// qualify prefixes every column in a column list with a table alias
+func qualify(columns, alias string) string {
+	parts := strings.Split(columns, ",")
+	for i, part := range parts {
+		parts[i] = alias + "." + strings.TrimSpace(part)
 	}
-	
-	rowsAffected, _ := result.RowsAffected()
-	log.Printf("DEBUG: Successfully migrated %d users from UTC to Asia/Bangkok timezone", rowsAffected)
-	
-	return nil
+	return strings.Join(parts, ", ")
 }
 
 // CreateUser creates a new user
@@ -141,20 +296,15 @@ func (d *Database) CreateUser(user NewUser) (*User, error) {
 	now := time.Now()
 
 	query := `
-		INSERT INTO users (telegram_id, name, timezone, language, default_reminder_interval, notification_style, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, telegram_id, name, timezone, language, default_reminder_interval, notification_style, created_at, updated_at
-	`
+		INSERT INTO users (telegram_id, name, timezone, language, default_reminder_interval, notification_style, digest_enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + userColumns
 
 	var result User
 	err := d.db.QueryRowContext(ctx, query,
 		user.TelegramID, user.Name, user.Timezone, user.Language,
-		24, "detailed", now, now,
-	).Scan(
-		&result.ID, &result.TelegramID, &result.Name, &result.Timezone,
-		&result.Language, &result.DefaultReminderInterval, &result.NotificationStyle,
-		&result.CreatedAt, &result.UpdatedAt,
-	)
+		24, "detailed", user.DigestEnabled, now, now,
+	).Scan(userFields(&result)...)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
@@ -182,381 +332,1563 @@ func (d *Database) UpdateUserLanguage(userID uuid.UUID, language string) error {
 	return nil
 }
 
-// GetUserByTelegramID gets a user by their Telegram ID
-func (d *Database) GetUserByTelegramID(telegramID int64) (*User, error) {
+// UpdateUserNotificationStyle updates how notifications are rendered
+func (d *Database) UpdateUserNotificationStyle(userID uuid.UUID, style string) error {
 	ctx := context.Background()
+	now := time.Now()
 
 	query := `
-		SELECT id, telegram_id, name, timezone, language, default_reminder_interval, notification_style, created_at, updated_at
-		FROM users
-		WHERE telegram_id = $1
+		UPDATE users 
+		SET notification_style = $1, updated_at = $2
+		WHERE id = $3
 	`
 
-	var user User
-	err := d.db.QueryRowContext(ctx, query, telegramID).Scan(
-		&user.ID, &user.TelegramID, &user.Name, &user.Timezone,
-		&user.Language, &user.DefaultReminderInterval, &user.NotificationStyle,
-		&user.CreatedAt, &user.UpdatedAt,
-	)
-
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to get user by telegram ID: %w", err)
+	if _, err := d.db.ExecContext(ctx, query, style, now, userID); err != nil {
+		return fmt.Errorf("failed to update user notification style: %w", err)
 	}
 
-	return &user, nil
+	return nil
 }
 
-// GetUserByID gets a user by their UUID
-func (d *Database) GetUserByID(userID uuid.UUID) (*User, error) {
+// UpdateUserSnoozeOptions updates the snooze choices offered on reminders
+func (d *Database) UpdateUserSnoozeOptions(userID uuid.UUID, options string) error {
 	ctx := context.Background()
+	now := time.Now()
 
 	query := `
-		SELECT id, telegram_id, name, timezone, language, default_reminder_interval, notification_style, created_at, updated_at
-		FROM users
-		WHERE id = $1
+		UPDATE users 
+		SET snooze_options = $1, updated_at = $2
+		WHERE id = $3
 	`
 
-	var user User
-	err := d.db.QueryRowContext(ctx, query, userID).Scan(
-		&user.ID, &user.TelegramID, &user.Name, &user.Timezone,
-		&user.Language, &user.DefaultReminderInterval, &user.NotificationStyle,
-		&user.CreatedAt, &user.UpdatedAt,
-	)
-
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to get user by ID: %w", err)
+	if _, err := d.db.ExecContext(ctx, query, options, now, userID); err != nil {
+		return fmt.Errorf("failed to update user snooze options: %w", err)
 	}
 
-	return &user, nil
+	return nil
 }
 
-// UpdateUserSettings updates user settings
-func (d *Database) UpdateUserSettings(userID uuid.UUID, timezone, language *string, defaultInterval *int) (*User, error) {
+// UpdateUserQuietHours sets or clears (with nil) the user's daily quiet hours
+func (d *Database) UpdateUserQuietHours(userID uuid.UUID, start, end *string) error {
 	ctx := context.Background()
 	now := time.Now()
 
-	// Build dynamic update query
-	query := "UPDATE users SET updated_at = $1"
-	args := []interface{}{now}
-	argIndex := 2
+	query := `
+		UPDATE users 
+		SET quiet_start = $1, quiet_end = $2, updated_at = $3
+		WHERE id = $4
+	`
 
-	if timezone != nil {
-		query += fmt.Sprintf(", timezone = $%d", argIndex)
-		args = append(args, *timezone)
-		argIndex++
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	if language != nil {
-		query += fmt.Sprintf(", language = $%d", argIndex)
-		args = append(args, *language)
-		argIndex++
+	if _, err := tx.ExecContext(ctx, query, start, end, now, userID); err != nil {
+		return fmt.Errorf("failed to update user quiet hours: %w", err)
 	}
-
-	if defaultInterval != nil {
-		query += fmt.Sprintf(", default_reminder_interval = $%d", argIndex)
-		args = append(args, *defaultInterval)
-		argIndex++
+	if err := releaseDeferredReminders(ctx, tx, userID, now); err != nil {
+		return err
 	}
 
-	query += fmt.Sprintf(" WHERE id = $%d", argIndex)
-	args = append(args, userID)
-
-	if _, err := d.db.ExecContext(ctx, query, args...); err != nil {
-		return nil, fmt.Errorf("failed to update user settings: %w", err)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit quiet hours: %w", err)
 	}
 
-	return d.GetUserByID(userID)
+	return nil
 }
 
-// CreateTodo creates a new todo
-func (d *Database) CreateTodo(todo NewTodo) (*Todo, error) {
+// UpdateUserQuietBypass sets whether high-priority reminders bypass quiet hours
+func (d *Database) UpdateUserQuietBypass(userID uuid.UUID, bypass bool) error {
 	ctx := context.Background()
 	now := time.Now()
 
 	query := `
-		INSERT INTO todos (user_id, title, description, due_time, priority, status, tags, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id, user_id, title, description, due_time, priority, status, tags, created_at, updated_at
+		UPDATE users 
+		SET quiet_bypass_high = $1, updated_at = $2
+		WHERE id = $3
 	`
 
-	var result Todo
-	err := d.db.QueryRowContext(ctx, query,
-		todo.UserID, todo.Title, todo.Description, todo.DueTime,
-		todo.Priority, "pending", todo.Tags, now, now,
-	).Scan(
-		&result.ID, &result.UserID, &result.Title, &result.Description,
-		&result.DueTime, &result.Priority, &result.Status, &result.Tags,
-		&result.CreatedAt, &result.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to create todo: %w", err)
+	if _, err := d.db.ExecContext(ctx, query, bypass, now, userID); err != nil {
+		return fmt.Errorf("failed to update user quiet bypass: %w", err)
 	}
 
-	return &result, nil
+	return nil
 }
 
-// GetUserTodos gets all todos for a user
-func (d *Database) GetUserTodos(userID uuid.UUID) ([]Todo, error) {
+// UpdateUserDND sets or clears (with nil) the user's temporary do-not-disturb
+func (d *Database) UpdateUserDND(userID uuid.UUID, until *time.Time) error {
 	ctx := context.Background()
+	now := time.Now()
 
 	query := `
-		SELECT id, user_id, title, description, due_time, priority, status, tags, created_at, updated_at
-		FROM todos
-		WHERE user_id = $1
-		ORDER BY created_at DESC
+		UPDATE users 
+		SET dnd_until = $1, updated_at = $2
+		WHERE id = $3
 	`
 
-	rows, err := d.db.QueryContext(ctx, query, userID)
+	tx, err := d.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user todos: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	var todos []Todo
-	for rows.Next() {
-		var todo Todo
-		err := rows.Scan(
-			&todo.ID, &todo.UserID, &todo.Title, &todo.Description,
-			&todo.DueTime, &todo.Priority, &todo.Status, &todo.Tags,
-			&todo.CreatedAt, &todo.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan todo: %w", err)
-		}
-		todos = append(todos, todo)
+	if _, err := tx.ExecContext(ctx, query, until, now, userID); err != nil {
+		return fmt.Errorf("failed to update user do-not-disturb: %w", err)
+	}
+	if err := releaseDeferredReminders(ctx, tx, userID, now); err != nil {
+		return err
 	}
 
-	return todos, nil
-}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit do-not-disturb: %w", err)
+	}
 
-// UpdateTodoStatus updates the status of a todo
-func (d *Database) UpdateTodoStatus(todoID uuid.UUID, status string) (*Todo, error) {
-	ctx := context.Background()
-	now := time.Now()
+	return nil
+}
 
+// releaseDeferredReminders clears the hold on the user's reminders deferred
+// by quiet hours or do-not-disturb. The notify trigger reschedules them, and
+// delivery defers them again if the new settings still call for it.
+func releaseDeferredReminders(ctx context.Context, db sqlExecer, userID uuid.UUID, now time.Time) error {
 	query := `
-		UPDATE todos 
-		SET status = $1, updated_at = $2
-		WHERE id = $3
-		RETURNING id, user_id, title, description, due_time, priority, status, tags, created_at, updated_at
+		UPDATE reminders 
+		SET deferred_until = NULL, updated_at = $1
+		WHERE deferred_until IS NOT NULL AND is_active = true
+		AND todo_id IN (SELECT id FROM todos WHERE user_id = $2)
 	`
-
-	var todo Todo
-	err := d.db.QueryRowContext(ctx, query, status, now, todoID).Scan(
-		&todo.ID, &todo.UserID, &todo.Title, &todo.Description,
-		&todo.DueTime, &todo.Priority, &todo.Status, &todo.Tags,
-		&todo.CreatedAt, &todo.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to update todo status: %w", err)
+	if _, err := db.ExecContext(ctx, query, now, userID); err != nil {
+		return fmt.Errorf("failed to release deferred reminders: %w", err)
 	}
 
-	return &todo, nil
+	return nil
 }
 
-// DeleteTodo deletes a todo
-func (d *Database) DeleteTodo(todoID uuid.UUID) error {
+// UpdateUserReminderOffsets sets the user's default reminder offsets before
+// a due time; nil falls back to default_reminder_interval
+func (d *Database) UpdateUserReminderOffsets(userID uuid.UUID, offsets *string) error {
 	ctx := context.Background()
+	now := time.Now()
 
-	query := "DELETE FROM todos WHERE id = $1"
-	if _, err := d.db.ExecContext(ctx, query, todoID); err != nil {
-		return fmt.Errorf("failed to delete todo: %w", err)
+	query := `
+		UPDATE users 
+		SET reminder_offsets = $1, updated_at = $2
+		WHERE id = $3
+	`
+
+	if _, err := d.db.ExecContext(ctx, query, offsets, now, userID); err != nil {
+		return fmt.Errorf("failed to update user reminder offsets: %w", err)
 	}
 
 	return nil
 }
 
-// CreateReminder creates a new reminder
-func (d *Database) CreateReminder(reminder NewReminder) (*Reminder, error) {
+// UpdateUserHighPriorityOffsets sets or clears (with nil) the extra reminder
+// offsets used for high priority tasks
+func (d *Database) UpdateUserHighPriorityOffsets(userID uuid.UUID, offsets *string) error {
 	ctx := context.Background()
 	now := time.Now()
 
 	query := `
-		INSERT INTO reminders (todo_id, repeat_count, repeat_interval_hours, next_notify_time, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, todo_id, repeat_count, repeat_interval_hours, next_notify_time, snoozed_until, is_active, created_at, updated_at
+		UPDATE users 
+		SET high_priority_offsets = $1, updated_at = $2
+		WHERE id = $3
 	`
 
-	var result Reminder
-	err := d.db.QueryRowContext(ctx, query,
-		reminder.TodoID, reminder.RepeatCount, reminder.RepeatIntervalHours,
-		reminder.NextNotifyTime, true, now, now,
-	).Scan(
-		&result.ID, &result.TodoID, &result.RepeatCount, &result.RepeatIntervalHours,
-		&result.NextNotifyTime, &result.SnoozedUntil, &result.IsActive,
-		&result.CreatedAt, &result.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to create reminder: %w", err)
+	if _, err := d.db.ExecContext(ctx, query, offsets, now, userID); err != nil {
+		return fmt.Errorf("failed to update user high priority offsets: %w", err)
 	}
 
-	return &result, nil
+	return nil
 }
 
-// GetRemindersForTodo gets all reminders for a todo
-func (d *Database) GetRemindersForTodo(todoID uuid.UUID) ([]Reminder, error) {
+// UpdateUserDigest turns the daily overdue digest on or off and sets its local time
+func (d *Database) UpdateUserDigest(userID uuid.UUID, enabled bool, digestTime string) error {
 	ctx := context.Background()
+	now := time.Now()
 
 	query := `
-		SELECT id, todo_id, repeat_count, repeat_interval_hours, next_notify_time, snoozed_until, is_active, created_at, updated_at
-		FROM reminders
-		WHERE todo_id = $1
-		ORDER BY created_at DESC
+		UPDATE users 
+		SET digest_enabled = $1, digest_time = $2, updated_at = $3
+		WHERE id = $4
 	`
 
-	rows, err := d.db.QueryContext(ctx, query, todoID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get reminders for todo: %w", err)
-	}
-	defer rows.Close()
-
-	var reminders []Reminder
-	for rows.Next() {
-		var reminder Reminder
-		err := rows.Scan(
-			&reminder.ID, &reminder.TodoID, &reminder.RepeatCount, &reminder.RepeatIntervalHours,
-			&reminder.NextNotifyTime, &reminder.SnoozedUntil, &reminder.IsActive,
-			&reminder.CreatedAt, &reminder.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan reminder: %w", err)
-		}
-		reminders = append(reminders, reminder)
+	if _, err := d.db.ExecContext(ctx, query, enabled, digestTime, now, userID); err != nil {
+		return fmt.Errorf("failed to update user digest: %w", err)
 	}
 
-	return reminders, nil
+	return nil
 }
 
-// UpdateReminderNextTime updates the next notification time for a reminder
-func (d *Database) UpdateReminderNextTime(reminderID uuid.UUID, nextTime time.Time) (*Reminder, error) {
+// UpdateUserBriefingTime sets or clears (with nil) the local time of the morning briefing
+func (d *Database) UpdateUserBriefingTime(userID uuid.UUID, briefingTime *string) error {
 	ctx := context.Background()
 	now := time.Now()
 
 	query := `
-		UPDATE reminders 
-		SET next_notify_time = $1, snoozed_until = NULL, updated_at = $2
+		UPDATE users 
+		SET briefing_time = $1, updated_at = $2
 		WHERE id = $3
-		RETURNING id, todo_id, repeat_count, repeat_interval_hours, next_notify_time, snoozed_until, is_active, created_at, updated_at
 	`
 
-	var reminder Reminder
-	err := d.db.QueryRowContext(ctx, query, nextTime, now, reminderID).Scan(
-		&reminder.ID, &reminder.TodoID, &reminder.RepeatCount, &reminder.RepeatIntervalHours,
-		&reminder.NextNotifyTime, &reminder.SnoozedUntil, &reminder.IsActive,
-		&reminder.CreatedAt, &reminder.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to update reminder next time: %w", err)
+	if _, err := d.db.ExecContext(ctx, query, briefingTime, now, userID); err != nil {
+		return fmt.Errorf("failed to update user briefing time: %w", err)
 	}
 
-	return &reminder, nil
+	return nil
 }
 
-// SnoozeReminder snoozes a reminder
-func (d *Database) SnoozeReminder(reminderID uuid.UUID, snoozeUntil time.Time) (*Reminder, error) {
+// UpdateUserReviewTime sets or clears (with nil) the local time of the evening review
+func (d *Database) UpdateUserReviewTime(userID uuid.UUID, reviewTime *string) error {
 	ctx := context.Background()
 	now := time.Now()
 
 	query := `
-		UPDATE reminders 
-		SET snoozed_until = $1, updated_at = $2
+		UPDATE users 
+		SET review_time = $1, updated_at = $2
 		WHERE id = $3
-		RETURNING id, todo_id, repeat_count, repeat_interval_hours, next_notify_time, snoozed_until, is_active, created_at, updated_at
 	`
 
-	var reminder Reminder
-	err := d.db.QueryRowContext(ctx, query, snoozeUntil, now, reminderID).Scan(
-		&reminder.ID, &reminder.TodoID, &reminder.RepeatCount, &reminder.RepeatIntervalHours,
-		&reminder.NextNotifyTime, &reminder.SnoozedUntil, &reminder.IsActive,
-		&reminder.CreatedAt, &reminder.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to snooze reminder: %w", err)
+	if _, err := d.db.ExecContext(ctx, query, reviewTime, now, userID); err != nil {
+		return fmt.Errorf("failed to update user review time: %w", err)
 	}
 
-	return &reminder, nil
+	return nil
+}
+
+// dailyMessageColumns maps each kind of daily message to the column
+// recording when it was last sent
+var dailyMessageColumns = map[string]string{
+	"digest":   "last_digest_at",
+	"briefing": "last_briefing_at",
+	"review":   "last_review_at",
 }
 
-// GetDueReminders gets all reminders that are due to be sent
-func (d *Database) GetDueReminders() ([]Reminder, error) {
+// ClaimUserDailyMessage marks today's daily message of the given kind as
+// sent for a user, reporting false if it was already sent since dayStart.
+// Only the instance that gets true sends the message.
+func (d *Database) ClaimUserDailyMessage(userID uuid.UUID, kind string, dayStart time.Time) (bool, error) {
 	ctx := context.Background()
 	now := time.Now()
 
-	query := `
-		SELECT id, todo_id, repeat_count, repeat_interval_hours, next_notify_time, snoozed_until, is_active, created_at, updated_at
-		FROM reminders
-		WHERE is_active = true 
-		AND next_notify_time <= $1
-		AND (snoozed_until IS NULL OR snoozed_until <= $1)
-		ORDER BY next_notify_time ASC
-	`
+	column, ok := dailyMessageColumns[kind]
+	if !ok {
+		return false, fmt.Errorf("unknown daily message: %s", kind)
+	}
 
-	rows, err := d.db.QueryContext(ctx, query, now)
+	query := fmt.Sprintf(`
+		UPDATE users 
+		SET %[1]s = $1
+		WHERE id = $2 AND (%[1]s IS NULL OR %[1]s < $3)
+	`, column)
+
+	result, err := d.db.ExecContext(ctx, query, now, userID, dayStart)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get due reminders: %w", err)
+		return false, fmt.Errorf("failed to claim user %s: %w", kind, err)
 	}
-	defer rows.Close()
 
-	var reminders []Reminder
-	for rows.Next() {
-		var reminder Reminder
-		err := rows.Scan(
-			&reminder.ID, &reminder.TodoID, &reminder.RepeatCount, &reminder.RepeatIntervalHours,
-			&reminder.NextNotifyTime, &reminder.SnoozedUntil, &reminder.IsActive,
-			&reminder.CreatedAt, &reminder.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan reminder: %w", err)
-		}
-		reminders = append(reminders, reminder)
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim user %s: %w", kind, err)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating reminders: %w", err)
+	return rowsAffected == 1, nil
+}
+
+// ReleaseUserDailyMessage gives back a claimed daily message that could not
+// be sent, restoring when it was last sent so it can be claimed again
+func (d *Database) ReleaseUserDailyMessage(userID uuid.UUID, kind string, lastSent *time.Time) error {
+	ctx := context.Background()
+
+	column, ok := dailyMessageColumns[kind]
+	if !ok {
+		return fmt.Errorf("unknown daily message: %s", kind)
 	}
 
-	return reminders, nil
+	query := fmt.Sprintf(`UPDATE users SET %s = $1 WHERE id = $2`, column)
+
+	if _, err := d.db.ExecContext(ctx, query, lastSent, userID); err != nil {
+		return fmt.Errorf("failed to release user %s: %w", kind, err)
+	}
+
+	return nil
 }
 
-// UpdateReminderTime updates the next notification time and repeat count for a reminder
-func (d *Database) UpdateReminderTime(reminderID uuid.UUID, nextTime time.Time, repeatCount int) error {
+// UpdateUserEscalation sets the escalation policy for ignored high priority
+// reminders; afterMinutes of 0 turns escalation off
+func (d *Database) UpdateUserEscalation(userID uuid.UUID, afterMinutes, max int) error {
 	ctx := context.Background()
 	now := time.Now()
 
 	query := `
-		UPDATE reminders 
-		SET next_notify_time = $1, repeat_count = $2, updated_at = $3
+		UPDATE users 
+		SET escalate_after_minutes = $1, escalate_max = $2, updated_at = $3
 		WHERE id = $4
 	`
 
-	_, err := d.db.ExecContext(ctx, query, nextTime, repeatCount, now, reminderID)
-	if err != nil {
-		return fmt.Errorf("failed to update reminder time: %w", err)
+	if _, err := d.db.ExecContext(ctx, query, afterMinutes, max, now, userID); err != nil {
+		return fmt.Errorf("failed to update user escalation: %w", err)
 	}
 
 	return nil
 }
 
-// DeleteReminder deletes a reminder
-func (d *Database) DeleteReminder(reminderID uuid.UUID) error {
+// UpdateUserEscalationContact sets or clears (with nil) the chat told about
+// reminders the user keeps ignoring
+func (d *Database) UpdateUserEscalationContact(userID uuid.UUID, chatID *int64) error {
 	ctx := context.Background()
+	now := time.Now()
 
-	query := `DELETE FROM reminders WHERE id = $1`
+	query := `
+		UPDATE users 
+		SET escalate_contact_chat_id = $1, updated_at = $2
+		WHERE id = $3
+	`
 
-	_, err := d.db.ExecContext(ctx, query, reminderID)
-	if err != nil {
-		return fmt.Errorf("failed to delete reminder: %w", err)
+	if _, err := d.db.ExecContext(ctx, query, chatID, now, userID); err != nil {
+		return fmt.Errorf("failed to update user escalation contact: %w", err)
+	}
+
+	return nil
+}
+
+// SetEscalationInvite stores the token of the link that makes whoever opens
+// it the user's escalation contact, replacing any earlier link
+func (d *Database) SetEscalationInvite(userID uuid.UUID, token string, expiresAt time.Time) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		UPDATE users 
+		SET escalate_invite_token = $1, escalate_invite_expires_at = $2, updated_at = $3
+		WHERE id = $4
+	`
+
+	if _, err := d.db.ExecContext(ctx, query, token, expiresAt, now, userID); err != nil {
+		return fmt.Errorf("failed to set escalation invite: %w", err)
+	}
+
+	return nil
+}
+
+// AcceptEscalationInvite makes chatID the escalation contact of the user
+// behind an unexpired invite token and uses the token up. It returns the
+// user, or nil if the token is unknown or expired.
+func (d *Database) AcceptEscalationInvite(token string, chatID int64) (*User, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		UPDATE users 
+		SET escalate_contact_chat_id = $1, escalate_invite_token = NULL, escalate_invite_expires_at = NULL, updated_at = $2
+		WHERE escalate_invite_token = $3 AND escalate_invite_expires_at > $2
+		RETURNING id
+	`
+
+	var userID uuid.UUID
+	err := d.db.QueryRowContext(ctx, query, chatID, now, token).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to accept escalation invite: %w", err)
+	}
+
+	return d.GetUserByID(userID)
+}
+
+// UpdateUsername stores a user's current Telegram @username, writing only
+// when it changed
+func (d *Database) UpdateUsername(telegramID int64, username string) error {
+	ctx := context.Background()
+
+	query := `
+		UPDATE users
+		SET username = NULLIF($1, ''), updated_at = $2
+		WHERE telegram_id = $3 AND username IS DISTINCT FROM NULLIF($1, '')
+	`
+	if _, err := d.db.ExecContext(ctx, query, username, time.Now(), telegramID); err != nil {
+		return fmt.Errorf("failed to update username: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserByUsername gets a user by their Telegram @username, ignoring case.
+// If a username moved to someone else, the latest holder wins.
+func (d *Database) GetUserByUsername(username string) (*User, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE lower(username) = lower($1)
+		ORDER BY updated_at DESC
+		LIMIT 1
+	`
+
+	var user User
+	err := d.db.QueryRowContext(ctx, query, username).Scan(userFields(&user)...)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user by username: %w", err)
+	}
+
+	return &user, nil
+}
+
+// TouchChatMember records that a user was active in a group chat. People who
+// never used the bot are not recorded.
+func (d *Database) TouchChatMember(chatID int64, telegramID int64) error {
+	ctx := context.Background()
+
+	query := `
+		INSERT INTO chat_members (chat_id, user_id, last_seen_at)
+		SELECT $1, id, $2 FROM users WHERE telegram_id = $3
+		ON CONFLICT (chat_id, user_id) DO UPDATE SET last_seen_at = EXCLUDED.last_seen_at
+	`
+	if _, err := d.db.ExecContext(ctx, query, chatID, time.Now(), telegramID); err != nil {
+		return fmt.Errorf("failed to record chat member: %w", err)
+	}
+
+	return nil
+}
+
+// GetChatMembers gets the users most recently active in a group chat
+func (d *Database) GetChatMembers(chatID int64, limit int) ([]User, error) {
+	query := `
+		SELECT ` + qualify(userColumns, "u") + `
+		FROM chat_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.chat_id = $1
+		ORDER BY m.last_seen_at DESC
+		LIMIT $2
+	`
+	return d.queryUsers(query, chatID, limit)
+}
+
+// IsChatMember reports whether a user has been active in a group chat
+func (d *Database) IsChatMember(chatID int64, userID uuid.UUID) (bool, error) {
+	ctx := context.Background()
+
+	query := `SELECT EXISTS (SELECT 1 FROM chat_members WHERE chat_id = $1 AND user_id = $2)`
+
+	var member bool
+	if err := d.db.QueryRowContext(ctx, query, chatID, userID).Scan(&member); err != nil {
+		return false, fmt.Errorf("failed to check chat member: %w", err)
+	}
+
+	return member, nil
+}
+
+// GetRecentContacts gets the users most recently active in the group chats
+// a user is in, leaving out the user themselves
+func (d *Database) GetRecentContacts(userID uuid.UUID, limit int) ([]User, error) {
+	query := `
+		SELECT ` + qualify(userColumns, "u") + `
+		FROM users u
+		JOIN (
+			SELECT other.user_id, MAX(other.last_seen_at) AS last_seen_at
+			FROM chat_members mine
+			JOIN chat_members other ON other.chat_id = mine.chat_id AND other.user_id <> mine.user_id
+			WHERE mine.user_id = $1
+			GROUP BY other.user_id
+		) recent ON recent.user_id = u.id
+		ORDER BY recent.last_seen_at DESC
+		LIMIT $2
+	`
+	return d.queryUsers(query, userID, limit)
+}
+
+// queryUsers runs a query selecting userColumns and scans every row
+func (d *Database) queryUsers(query string, args ...interface{}) ([]User, error) {
+	ctx := context.Background()
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(userFields(&user)...); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return users, nil
+}
+
+// GetUserByTelegramID gets a user by their Telegram ID
+func (d *Database) GetUserByTelegramID(telegramID int64) (*User, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE telegram_id = $1
+	`
+
+	var user User
+	err := d.db.QueryRowContext(ctx, query, telegramID).Scan(userFields(&user)...)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user by telegram ID: %w", err)
+	}
+
+	return &user, nil
+}
+
+// GetUsersWithDailyMessages gets all users who opted in to the morning
+// briefing or the evening review
+func (d *Database) GetUsersWithDailyMessages() ([]User, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE briefing_time IS NOT NULL OR review_time IS NOT NULL
+	`
+
+	rows, err := d.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users with daily messages: %w", err)
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(userFields(&user)...); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return users, nil
+}
+
+// GetUserByID gets a user by their UUID
+func (d *Database) GetUserByID(userID uuid.UUID) (*User, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE id = $1
+	`
+
+	var user User
+	err := d.db.QueryRowContext(ctx, query, userID).Scan(userFields(&user)...)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user by ID: %w", err)
+	}
+
+	return &user, nil
+}
+
+// UpdateUserSettings applies the non-nil fields of settings to a user and
+// returns the updated user
+func (d *Database) UpdateUserSettings(userID uuid.UUID, settings UserSettings) (*User, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	// Build dynamic update query
+	query := "UPDATE users SET updated_at = $1"
+	args := []interface{}{now}
+	argIndex := 2
+
+	set := func(column string, value interface{}) {
+		query += fmt.Sprintf(", %s = $%d", column, argIndex)
+		args = append(args, value)
+		argIndex++
+	}
+	// Optional clock columns are cleared with an empty string
+	setClock := func(column string, value *string) {
+		query += fmt.Sprintf(", %s = NULLIF($%d, '')", column, argIndex)
+		args = append(args, *value)
+		argIndex++
+	}
+
+	if settings.Timezone != nil {
+		set("timezone", *settings.Timezone)
+	}
+	if settings.Language != nil {
+		set("language", *settings.Language)
+	}
+	if settings.DefaultReminderInterval != nil {
+		set("default_reminder_interval", *settings.DefaultReminderInterval)
+	}
+	if settings.NotificationStyle != nil {
+		set("notification_style", *settings.NotificationStyle)
+	}
+	if settings.QuietStart != nil {
+		setClock("quiet_start", settings.QuietStart)
+	}
+	if settings.QuietEnd != nil {
+		setClock("quiet_end", settings.QuietEnd)
+	}
+	if settings.DigestEnabled != nil {
+		set("digest_enabled", *settings.DigestEnabled)
+	}
+	if settings.DigestTime != nil {
+		set("digest_time", *settings.DigestTime)
+	}
+	if settings.BriefingTime != nil {
+		setClock("briefing_time", settings.BriefingTime)
+	}
+	if settings.ReviewTime != nil {
+		setClock("review_time", settings.ReviewTime)
+	}
+	if settings.WeekStart != nil {
+		set("week_start", *settings.WeekStart)
+	}
+	if settings.Clock24h != nil {
+		set("clock_24h", *settings.Clock24h)
+	}
+	if settings.DateFormat != nil {
+		set("date_format", *settings.DateFormat)
+	}
+	if settings.DefaultPriority != nil {
+		set("default_priority", *settings.DefaultPriority)
+	}
+	if settings.DefaultList != nil {
+		set("default_list", *settings.DefaultList)
+	}
+
+	query += fmt.Sprintf(" WHERE id = $%d", argIndex)
+	args = append(args, userID)
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to update user settings: %w", err)
+	}
+	if settings.QuietStart != nil || settings.QuietEnd != nil {
+		if err := releaseDeferredReminders(ctx, tx, userID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit user settings: %w", err)
+	}
+
+	return d.GetUserByID(userID)
+}
+
+// todoColumns lists the todo columns in the order todoFields expects
+const todoColumns = `id, user_id, chat_id, assignee_id, assignment_status, title, description, due_time, priority, status, tags, repeat_rule, completed_at, created_at, updated_at`
+
+// todoFields returns scan destinations matching todoColumns
+func todoFields(todo *Todo) []interface{} {
+	return []interface{}{
+		&todo.ID, &todo.UserID, &todo.ChatID, &todo.AssigneeID, &todo.AssignmentStatus, &todo.Title, &todo.Description,
+		&todo.DueTime, &todo.Priority, &todo.Status, &todo.Tags,
+		&todo.RepeatRule, &todo.CompletedAt, &todo.CreatedAt, &todo.UpdatedAt,
+	}
+}
+
+// CreateTodo creates a new todo
+func (d *Database) CreateTodo(todo NewTodo) (*Todo, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		INSERT INTO todos (user_id, chat_id, assignee_id, assignment_status, title, description, due_time, priority, status, tags, repeat_rule, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING ` + todoColumns
+
+	var result Todo
+	err := d.db.QueryRowContext(ctx, query,
+		todo.UserID, todo.ChatID, todo.AssigneeID, todo.AssignmentStatus, todo.Title, todo.Description, todo.DueTime,
+		todo.Priority, "pending", todo.Tags, todo.RepeatRule, now, now,
+	).Scan(todoFields(&result)...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create todo: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetUserTodos gets all of a user's personal todos, the tasks assigned to
+// them and the tasks of lists shared with them, leaving out tasks they added
+// to group chats
+func (d *Database) GetUserTodos(userID uuid.UUID) ([]Todo, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT ` + todoColumns + `
+		FROM todos
+		WHERE (user_id = $1 AND chat_id IS NULL) OR assignee_id = $1
+			OR (chat_id IS NULL AND user_id IN (SELECT owner_id FROM list_members WHERE member_id = $1))
+		ORDER BY created_at DESC
+	`
+
+	rows, err := d.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user todos: %w", err)
+	}
+	defer rows.Close()
+
+	var todos []Todo
+	for rows.Next() {
+		var todo Todo
+		err := rows.Scan(todoFields(&todo)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan todo: %w", err)
+		}
+		todos = append(todos, todo)
+	}
+
+	return todos, nil
+}
+
+// GetChatTodos gets the shared todos of a group chat
+func (d *Database) GetChatTodos(chatID int64) ([]Todo, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT ` + todoColumns + `
+		FROM todos
+		WHERE chat_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := d.db.QueryContext(ctx, query, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat todos: %w", err)
+	}
+	defer rows.Close()
+
+	var todos []Todo
+	for rows.Next() {
+		var todo Todo
+		err := rows.Scan(todoFields(&todo)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan todo: %w", err)
+		}
+		todos = append(todos, todo)
+	}
+
+	return todos, nil
+}
+
+// AssignTodo sets or clears (with nil) who a todo is assigned to and
+// whether they accepted it
+func (d *Database) AssignTodo(todoID uuid.UUID, assigneeID *uuid.UUID, status *string) (*Todo, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		UPDATE todos 
+		SET assignee_id = $1, assignment_status = $2, updated_at = $3
+		WHERE id = $4
+		RETURNING ` + todoColumns
+
+	var todo Todo
+	err := d.db.QueryRowContext(ctx, query, assigneeID, status, now, todoID).Scan(todoFields(&todo)...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign todo: %w", err)
+	}
+
+	return &todo, nil
+}
+
+// UpdateTodoStatus closes a pending todo or reopens a closed one, recording
+// when it was completed. It returns nil if the todo was already in that
+// state, so of two racing updates only one goes through.
+func (d *Database) UpdateTodoStatus(todoID uuid.UUID, status string) (*Todo, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		UPDATE todos 
+		SET status = $1, updated_at = $2,
+			completed_at = CASE WHEN $1 = 'completed' THEN $2::timestamptz ELSE NULL END
+		WHERE id = $3
+		AND CASE WHEN $1 = 'pending' THEN status <> 'pending' ELSE status = 'pending' END
+		RETURNING ` + todoColumns
+
+	var todo Todo
+	err := d.db.QueryRowContext(ctx, query, status, now, todoID).Scan(todoFields(&todo)...)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to update todo status: %w", err)
+	}
+
+	return &todo, nil
+}
+
+// UpdateTodoDueTime sets or clears (with nil) the due time of a todo
+func (d *Database) UpdateTodoDueTime(todoID uuid.UUID, dueTime *time.Time) (*Todo, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		UPDATE todos 
+		SET due_time = $1, updated_at = $2
+		WHERE id = $3
+		RETURNING ` + todoColumns
+
+	var todo Todo
+	err := d.db.QueryRowContext(ctx, query, dueTime, now, todoID).Scan(todoFields(&todo)...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to update todo due time: %w", err)
+	}
+
+	return &todo, nil
+}
+
+// UpdateTodoPriority sets the priority of a todo
+func (d *Database) UpdateTodoPriority(todoID uuid.UUID, priority string) (*Todo, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		UPDATE todos 
+		SET priority = $1, updated_at = $2
+		WHERE id = $3
+		RETURNING ` + todoColumns
+
+	var todo Todo
+	err := d.db.QueryRowContext(ctx, query, priority, now, todoID).Scan(todoFields(&todo)...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to update todo priority: %w", err)
+	}
+
+	return &todo, nil
+}
+
+// UpdateTodoRepeatRule sets or clears (with nil) the schedule a todo recurs on
+func (d *Database) UpdateTodoRepeatRule(todoID uuid.UUID, rule *string) (*Todo, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		UPDATE todos 
+		SET repeat_rule = $1, updated_at = $2
+		WHERE id = $3
+		RETURNING ` + todoColumns
+
+	var todo Todo
+	err := d.db.QueryRowContext(ctx, query, rule, now, todoID).Scan(todoFields(&todo)...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to update todo repeat rule: %w", err)
+	}
+
+	return &todo, nil
+}
+
+// DeleteTodo deletes a todo
+func (d *Database) DeleteTodo(todoID uuid.UUID) error {
+	ctx := context.Background()
+
+	query := "DELETE FROM todos WHERE id = $1"
+	if _, err := d.db.ExecContext(ctx, query, todoID); err != nil {
+		return fmt.Errorf("failed to delete todo: %w", err)
+	}
+
+	return nil
+}
+
+// reminderColumns lists the reminder columns in the order scanReminder expects
+const reminderColumns = `id, todo_id, repeat_count, repeat_interval_seconds, repeat_until, next_notify_time, snoozed_until,
+	is_active, is_paused, schedule, deferred_until, due_offset_seconds, deactivated_reason, created_at, updated_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// reminderFields returns scan destinations matching reminderColumns
+func reminderFields(reminder *Reminder) []interface{} {
+	return []interface{}{
+		&reminder.ID, &reminder.TodoID, &reminder.RepeatCount, &reminder.RepeatIntervalSeconds,
+		&reminder.RepeatUntil, &reminder.NextNotifyTime, &reminder.SnoozedUntil, &reminder.IsActive,
+		&reminder.IsPaused, &reminder.Schedule, &reminder.DeferredUntil, &reminder.DueOffsetSeconds,
+		&reminder.DeactivatedReason, &reminder.CreatedAt, &reminder.UpdatedAt,
+	}
+}
+
+// scanReminder scans a row selected with reminderColumns
+func scanReminder(row rowScanner, reminder *Reminder) error {
+	return row.Scan(reminderFields(reminder)...)
+}
+
+// CreateReminder creates a new reminder
+func (d *Database) CreateReminder(reminder NewReminder) (*Reminder, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		INSERT INTO reminders (todo_id, repeat_count, repeat_interval_seconds, repeat_until, next_notify_time, schedule, due_offset_seconds, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING ` + reminderColumns
+
+	var result Reminder
+	err := scanReminder(d.db.QueryRowContext(ctx, query,
+		reminder.TodoID, reminder.RepeatCount, reminder.RepeatIntervalSeconds, reminder.RepeatUntil,
+		reminder.NextNotifyTime, reminder.Schedule, reminder.DueOffsetSeconds, true, now, now,
+	), &result)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reminder: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ReplaceDueReminders swaps a todo's automatic due-time reminders for a new
+// set in one transaction. Old ones are deactivated rather than deleted so
+// buttons on notifications already sent keep working.
+func (d *Database) ReplaceDueReminders(todoID uuid.UUID, reminders []NewReminder) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE reminders 
+		SET is_active = false, snoozed_until = NULL, deferred_until = NULL, updated_at = $1
+		WHERE todo_id = $2 AND due_offset_seconds IS NOT NULL AND is_active = true
+	`
+	if _, err := tx.ExecContext(ctx, query, now, todoID); err != nil {
+		return fmt.Errorf("failed to deactivate due reminders: %w", err)
+	}
+
+	query = `
+		INSERT INTO reminders (todo_id, repeat_count, repeat_interval_seconds, repeat_until, next_notify_time, schedule, due_offset_seconds, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	for _, reminder := range reminders {
+		_, err := tx.ExecContext(ctx, query,
+			todoID, reminder.RepeatCount, reminder.RepeatIntervalSeconds, reminder.RepeatUntil,
+			reminder.NextNotifyTime, reminder.Schedule, reminder.DueOffsetSeconds, true, now, now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create due reminder: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit due reminders: %w", err)
+	}
+
+	return nil
+}
+
+// GetRemindersForTodo gets all reminders for a todo
+func (d *Database) GetRemindersForTodo(todoID uuid.UUID) ([]Reminder, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT ` + reminderColumns + `
+		FROM reminders
+		WHERE todo_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := d.db.QueryContext(ctx, query, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminders for todo: %w", err)
+	}
+	defer rows.Close()
+
+	var reminders []Reminder
+	for rows.Next() {
+		var reminder Reminder
+		if err := scanReminder(rows, &reminder); err != nil {
+			return nil, fmt.Errorf("failed to scan reminder: %w", err)
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, nil
+}
+
+// UpdateReminderNextTime updates the next notification time for a reminder
+func (d *Database) UpdateReminderNextTime(reminderID uuid.UUID, nextTime time.Time) (*Reminder, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		UPDATE reminders 
+		SET next_notify_time = $1, snoozed_until = NULL, updated_at = $2
+		WHERE id = $3
+		RETURNING ` + reminderColumns
+
+	var reminder Reminder
+	err := scanReminder(d.db.QueryRowContext(ctx, query, nextTime, now, reminderID), &reminder)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to update reminder next time: %w", err)
+	}
+
+	return &reminder, nil
+}
+
+// SnoozeReminder snoozes a reminder
+func (d *Database) SnoozeReminder(reminderID uuid.UUID, snoozeUntil time.Time) (*Reminder, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		UPDATE reminders 
+		SET snoozed_until = $1, is_active = true, updated_at = $2
+		WHERE id = $3
+		RETURNING ` + reminderColumns
+
+	var reminder Reminder
+	err := scanReminder(d.db.QueryRowContext(ctx, query, snoozeUntil, now, reminderID), &reminder)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to snooze reminder: %w", err)
+	}
+
+	return &reminder, nil
+}
+
+// ClaimDueReminders atomically leases due reminders to one bot instance.
+// Rows locked or leased by another instance are skipped, so concurrent
+// replicas never pick up the same occurrence.
+func (d *Database) ClaimDueReminders(instanceID string, lease time.Duration, limit int) ([]Reminder, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		UPDATE reminders
+		SET claimed_by = $1, claimed_until = $2
+		WHERE id IN (
+			SELECT id FROM reminders
+			WHERE is_active = true AND is_paused = false
+			AND COALESCE(snoozed_until, next_notify_time) <= $3
+			AND (deferred_until IS NULL OR deferred_until <= $3)
+			AND (claimed_until IS NULL OR claimed_until <= $3)
+			ORDER BY COALESCE(snoozed_until, next_notify_time) ASC
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + reminderColumns
+
+	rows, err := d.db.QueryContext(ctx, query, instanceID, now.Add(lease), now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var reminders []Reminder
+	for rows.Next() {
+		var reminder Reminder
+		if err := scanReminder(rows, &reminder); err != nil {
+			return nil, fmt.Errorf("failed to scan reminder: %w", err)
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reminders: %w", err)
+	}
+
+	return reminders, nil
+}
+
+// GetUpcomingReminderFires gets when each active reminder firing before the
+// given time next fires, after any snooze, deferral or claim
+func (d *Database) GetUpcomingReminderFires(before time.Time) ([]ReminderFire, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT id, fires_at FROM (
+			SELECT id, GREATEST(COALESCE(snoozed_until, next_notify_time), deferred_until, claimed_until) AS fires_at
+			FROM reminders
+			WHERE is_active = true AND is_paused = false
+		) upcoming
+		WHERE fires_at <= $1
+		ORDER BY fires_at ASC
+	`
+
+	rows, err := d.db.QueryContext(ctx, query, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upcoming reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var fires []ReminderFire
+	for rows.Next() {
+		var fire ReminderFire
+		if err := rows.Scan(&fire.ReminderID, &fire.At); err != nil {
+			return nil, fmt.Errorf("failed to scan upcoming reminder: %w", err)
+		}
+		fires = append(fires, fire)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating upcoming reminders: %w", err)
+	}
+
+	return fires, nil
+}
+
+// ListenReminderChanges holds a connection listening for reminder change
+// notifications, calling onListen once listening and handle with each
+// payload. It returns when the connection fails or ctx is cancelled.
+func (d *Database) ListenReminderChanges(ctx context.Context, onListen func(), handle func(payload string)) error {
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get listener connection: %w", err)
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		pgConn := driverConn.(*stdlib.Conn).Conn()
+		if _, err := pgConn.Exec(ctx, "LISTEN "+reminderChangesChannel); err != nil {
+			return fmt.Errorf("failed to listen for reminder changes: %w", err)
+		}
+		// Don't hand a listening connection back to the pool
+		defer pgConn.Exec(context.Background(), "UNLISTEN *")
+
+		onListen()
+		for {
+			notification, err := pgConn.WaitForNotification(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to wait for reminder changes: %w", err)
+			}
+			handle(notification.Payload)
+		}
+	})
+}
+
+// DeferReminder holds back a due reminder until the given time
+func (d *Database) DeferReminder(reminderID uuid.UUID, until time.Time) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `UPDATE reminders SET deferred_until = $1, updated_at = $2 WHERE id = $3`
+
+	if _, err := d.db.ExecContext(ctx, query, until, now, reminderID); err != nil {
+		return fmt.Errorf("failed to defer reminder: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseReminderClaim gives up an instance's lease on a reminder
+func (d *Database) ReleaseReminderClaim(reminderID uuid.UUID, instanceID string) error {
+	ctx := context.Background()
+
+	query := `
+		UPDATE reminders
+		SET claimed_by = NULL, claimed_until = NULL
+		WHERE id = $1 AND claimed_by = $2
+	`
+
+	if _, err := d.db.ExecContext(ctx, query, reminderID, instanceID); err != nil {
+		return fmt.Errorf("failed to release reminder claim: %w", err)
+	}
+
+	return nil
+}
+
+// ExtendReminderClaim keeps a reminder leased until a retry is due
+func (d *Database) ExtendReminderClaim(reminderID uuid.UUID, instanceID string, until time.Time) error {
+	ctx := context.Background()
+
+	query := `
+		UPDATE reminders
+		SET claimed_until = $1
+		WHERE id = $2 AND claimed_by = $3
+	`
+
+	if _, err := d.db.ExecContext(ctx, query, until, reminderID, instanceID); err != nil {
+		return fmt.Errorf("failed to extend reminder claim: %w", err)
+	}
+
+	return nil
+}
+
+// HasDeliveredReminder reports whether an occurrence of a reminder was already sent
+func (d *Database) HasDeliveredReminder(reminderID uuid.UUID, occurrence time.Time) (bool, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM reminder_deliveries
+			WHERE reminder_id = $1 AND occurrence_time = $2 AND status = 'sent'
+		)
+	`
+
+	var delivered bool
+	if err := d.db.QueryRowContext(ctx, query, reminderID, occurrence).Scan(&delivered); err != nil {
+		return false, fmt.Errorf("failed to check reminder delivery: %w", err)
+	}
+
+	return delivered, nil
+}
+
+// RecordReminderDelivery records a delivery attempt and returns its attempt number
+func (d *Database) RecordReminderDelivery(delivery NewReminderDelivery) (int, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		INSERT INTO reminder_deliveries (reminder_id, occurrence_time, attempt, status, error, instance_id, telegram_message_id, skipped_occurrences, created_at)
+		VALUES ($1, $2,
+			(SELECT COUNT(*) + 1 FROM reminder_deliveries WHERE reminder_id = $1 AND occurrence_time = $2),
+			$3, $4, $5, $6, $7, $8)
+		RETURNING attempt
+	`
+
+	var attempt int
+	err := d.db.QueryRowContext(ctx, query,
+		delivery.ReminderID, delivery.OccurrenceTime, delivery.Status, delivery.Error,
+		delivery.InstanceID, delivery.TelegramMessageID, delivery.SkippedOccurrences, now,
+	).Scan(&attempt)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to record reminder delivery: %w", err)
+	}
+
+	return attempt, nil
+}
+
+// MarkReminderInteracted records that the user acted on a reminder's
+// notifications, which stops them from escalating
+func (d *Database) MarkReminderInteracted(reminderID uuid.UUID) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		UPDATE reminder_deliveries
+		SET interacted_at = $1
+		WHERE reminder_id = $2 AND status = 'sent' AND interacted_at IS NULL
+	`
+
+	if _, err := d.db.ExecContext(ctx, query, now, reminderID); err != nil {
+		return fmt.Errorf("failed to mark reminder interacted: %w", err)
+	}
+
+	return nil
+}
+
+// GetPendingEscalations gets sent reminders for pending high priority todos
+// that nobody acted on within the owner's escalation interval. Only the
+// latest occurrence of each reminder escalates, and only for a day.
+func (d *Database) GetPendingEscalations() ([]PendingEscalation, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		SELECT d.id, d.escalation_level,
+			   ` + qualify(reminderColumns, "r") + `,
+			   ` + qualify(todoColumns, "t") + `,
+			   ` + qualify(userColumns, "u") + `
+		FROM reminder_deliveries d
+		JOIN reminders r ON d.reminder_id = r.id
+		JOIN todos t ON r.todo_id = t.id
+		JOIN users u ON t.user_id = u.id
+		WHERE d.status = 'sent' AND d.interacted_at IS NULL
+		AND t.priority = 'high' AND t.status = 'pending'
+		AND u.escalate_after_minutes > 0 AND d.escalation_level <= u.escalate_max
+		AND COALESCE(d.last_escalated_at, d.created_at) + make_interval(mins => u.escalate_after_minutes) <= $1
+		AND d.created_at > $1 - INTERVAL '1 day'
+		AND NOT EXISTS (
+			SELECT 1 FROM reminder_deliveries newer
+			WHERE newer.reminder_id = d.reminder_id AND newer.status = 'sent'
+			AND newer.occurrence_time > d.occurrence_time
+		)
+		ORDER BY d.created_at ASC
+	`
+
+	rows, err := d.db.QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending escalations: %w", err)
+	}
+	defer rows.Close()
+
+	var results []PendingEscalation
+	for rows.Next() {
+		var result PendingEscalation
+		dest := []interface{}{&result.DeliveryID, &result.EscalationLevel}
+		dest = append(dest, reminderFields(&result.Reminder)...)
+		dest = append(dest, todoFields(&result.Todo)...)
+		dest = append(dest, userFields(&result.User)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan pending escalation: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pending escalations: %w", err)
+	}
+
+	return results, nil
+}
+
+// ClaimEscalation moves a delivery from one escalation level to the next,
+// reporting false if another instance already did
+func (d *Database) ClaimEscalation(deliveryID uuid.UUID, level int) (bool, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		UPDATE reminder_deliveries
+		SET escalation_level = $1 + 1, last_escalated_at = $2
+		WHERE id = $3 AND escalation_level = $1
+	`
+
+	result, err := d.db.ExecContext(ctx, query, level, now, deliveryID)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim escalation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim escalation: %w", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// UpdateReminderTime updates the next notification time and remaining repeat count for a reminder
+func (d *Database) UpdateReminderTime(reminderID uuid.UUID, nextTime time.Time, repeatCount int) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		UPDATE reminders 
+		SET next_notify_time = $1, repeat_count = $2, snoozed_until = NULL, deferred_until = NULL, updated_at = $3
+		WHERE id = $4
+	`
+
+	_, err := d.db.ExecContext(ctx, query, nextTime, repeatCount, now, reminderID)
+	if err != nil {
+		return fmt.Errorf("failed to update reminder time: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserReminders gets all active reminders for a user's todos, soonest first
+func (d *Database) GetUserReminders(userID uuid.UUID) ([]ReminderWithTodo, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT ` + qualify(reminderColumns, "r") + `,
+			   ` + qualify(todoColumns, "t") + `
+		FROM reminders r
+		JOIN todos t ON r.todo_id = t.id
+		WHERE t.user_id = $1 AND r.is_active = true
+		ORDER BY COALESCE(r.snoozed_until, r.next_notify_time) ASC
+	`
+
+	rows, err := d.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var results []ReminderWithTodo
+	for rows.Next() {
+		var result ReminderWithTodo
+		dest := append(reminderFields(&result.Reminder), todoFields(&result.Todo)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan reminder: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reminders: %w", err)
+	}
+
+	return results, nil
+}
+
+// SetReminderPaused pauses or resumes a reminder
+func (d *Database) SetReminderPaused(reminderID uuid.UUID, paused bool) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `UPDATE reminders SET is_paused = $1, updated_at = $2 WHERE id = $3`
+
+	if _, err := d.db.ExecContext(ctx, query, paused, now, reminderID); err != nil {
+		return fmt.Errorf("failed to update reminder pause state: %w", err)
+	}
+
+	return nil
+}
+
+// GetReminderByID gets a reminder by its ID
+func (d *Database) GetReminderByID(reminderID uuid.UUID) (*Reminder, error) {
+	ctx := context.Background()
+
+	query := `SELECT ` + reminderColumns + ` FROM reminders WHERE id = $1`
+
+	var reminder Reminder
+	if err := scanReminder(d.db.QueryRowContext(ctx, query, reminderID), &reminder); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get reminder: %w", err)
+	}
+
+	return &reminder, nil
+}
+
+// DeactivateReminder stops a reminder from firing but keeps it so its
+// notification buttons keep working
+func (d *Database) DeactivateReminder(reminderID uuid.UUID) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		UPDATE reminders 
+		SET is_active = false, snoozed_until = NULL, deferred_until = NULL, updated_at = $1
+		WHERE id = $2
+	`
+
+	if _, err := d.db.ExecContext(ctx, query, now, reminderID); err != nil {
+		return fmt.Errorf("failed to deactivate reminder: %w", err)
+	}
+
+	return nil
+}
+
+// DeactivateTodoReminders stops all active reminders of a todo, recording
+// why so they can be restored if the todo is reopened
+func (d *Database) DeactivateTodoReminders(todoID uuid.UUID, reason string) (int, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		UPDATE reminders 
+		SET is_active = false, snoozed_until = NULL, deferred_until = NULL,
+			deactivated_reason = $1, updated_at = $2
+		WHERE todo_id = $3 AND is_active = true
+	`
+
+	result, err := d.db.ExecContext(ctx, query, reason, now, todoID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to deactivate todo reminders: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to deactivate todo reminders: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// ReactivateReminder turns a deactivated reminder back on at the given time
+func (d *Database) ReactivateReminder(reminderID uuid.UUID, nextTime time.Time, repeatCount int) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		UPDATE reminders 
+		SET is_active = true, deactivated_reason = NULL, next_notify_time = $1, repeat_count = $2,
+			snoozed_until = NULL, deferred_until = NULL, updated_at = $3
+		WHERE id = $4
+	`
+
+	if _, err := d.db.ExecContext(ctx, query, nextTime, repeatCount, now, reminderID); err != nil {
+		return fmt.Errorf("failed to reactivate reminder: %w", err)
+	}
+
+	return nil
+}
+
+// ClearReminderSnooze clears the snooze of a reminder without touching its schedule
+func (d *Database) ClearReminderSnooze(reminderID uuid.UUID) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `UPDATE reminders SET snoozed_until = NULL, deferred_until = NULL, updated_at = $1 WHERE id = $2`
+
+	if _, err := d.db.ExecContext(ctx, query, now, reminderID); err != nil {
+		return fmt.Errorf("failed to clear reminder snooze: %w", err)
+	}
+
+	return nil
+}
+
+// RescheduleReminder replaces the timing of a reminder and reactivates it.
+// A rescheduled automatic reminder no longer follows the task's due time.
+func (d *Database) RescheduleReminder(reminderID uuid.UUID, reminder NewReminder) (*Reminder, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	query := `
+		UPDATE reminders 
+		SET repeat_count = $1, repeat_interval_seconds = $2, repeat_until = $3, next_notify_time = $4,
+			schedule = $5, due_offset_seconds = NULL, snoozed_until = NULL, deferred_until = NULL,
+			is_active = true, updated_at = $6
+		WHERE id = $7
+		RETURNING ` + reminderColumns
+
+	var result Reminder
+	err := scanReminder(d.db.QueryRowContext(ctx, query,
+		reminder.RepeatCount, reminder.RepeatIntervalSeconds, reminder.RepeatUntil,
+		reminder.NextNotifyTime, reminder.Schedule, now, reminderID,
+	), &result)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to reschedule reminder: %w", err)
+	}
+
+	return &result, nil
+}
+
+// DeleteReminder deletes a reminder
+func (d *Database) DeleteReminder(reminderID uuid.UUID) error {
+	ctx := context.Background()
+
+	query := `DELETE FROM reminders WHERE id = $1`
+
+	_, err := d.db.ExecContext(ctx, query, reminderID)
+	if err != nil {
+		return fmt.Errorf("failed to delete reminder: %w", err)
 	}
 
 	return nil
@@ -571,11 +1903,11 @@ func (d *Database) GetAllOverdueTodos() ([]struct {
 	now := time.Now()
 
 	query := `
-		SELECT t.id, t.user_id, t.title, t.description, t.due_time, t.priority, t.status, t.tags, t.created_at, t.updated_at,
-			   u.id, u.telegram_id, u.name, u.timezone, u.language, u.default_reminder_interval, u.notification_style, u.created_at, u.updated_at
+		SELECT ` + qualify(todoColumns, "t") + `,
+			   ` + qualify(userColumns, "u") + `
 		FROM todos t
 		JOIN users u ON t.user_id = u.id
-		WHERE t.status = 'pending' AND t.due_time IS NOT NULL AND t.due_time < $1
+		WHERE t.status = 'pending' AND t.due_time IS NOT NULL AND t.due_time < $1 AND t.chat_id IS NULL
 		ORDER BY t.due_time ASC
 	`
 
@@ -595,14 +1927,7 @@ func (d *Database) GetAllOverdueTodos() ([]struct {
 			Todo Todo
 			User User
 		}
-		err := rows.Scan(
-			&result.Todo.ID, &result.Todo.UserID, &result.Todo.Title, &result.Todo.Description,
-			&result.Todo.DueTime, &result.Todo.Priority, &result.Todo.Status, &result.Todo.Tags,
-			&result.Todo.CreatedAt, &result.Todo.UpdatedAt,
-			&result.User.ID, &result.User.TelegramID, &result.User.Name, &result.User.Timezone,
-			&result.User.Language, &result.User.DefaultReminderInterval, &result.User.NotificationStyle,
-			&result.User.CreatedAt, &result.User.UpdatedAt,
-		)
+		err := rows.Scan(append(todoFields(&result.Todo), userFields(&result.User)...)...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan overdue todo: %w", err)
 		}
@@ -627,7 +1952,7 @@ func (d *Database) GetTodoStats(userID uuid.UUID) (*TodoStats, error) {
 			COUNT(*) FILTER (WHERE priority = 'medium') as medium_priority,
 			COUNT(*) FILTER (WHERE priority = 'low') as low_priority
 		FROM todos
-		WHERE user_id = $2
+		WHERE user_id = $2 AND chat_id IS NULL
 	`
 
 	var stats TodoStats
@@ -648,17 +1973,13 @@ func (d *Database) GetTodoByID(todoID uuid.UUID) (*Todo, error) {
 	ctx := context.Background()
 
 	query := `
-		SELECT id, user_id, title, description, due_time, priority, status, tags, created_at, updated_at
+		SELECT ` + todoColumns + `
 		FROM todos
 		WHERE id = $1
 	`
 
 	var todo Todo
-	err := d.db.QueryRowContext(ctx, query, todoID).Scan(
-		&todo.ID, &todo.UserID, &todo.Title, &todo.Description,
-		&todo.DueTime, &todo.Priority, &todo.Status, &todo.Tags,
-		&todo.CreatedAt, &todo.UpdatedAt,
-	)
+	err := d.db.QueryRowContext(ctx, query, todoID).Scan(todoFields(&todo)...)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get todo: %w", err)
@@ -666,3 +1987,471 @@ func (d *Database) GetTodoByID(todoID uuid.UUID) (*Todo, error) {
 
 	return &todo, nil
 }
+
+// listInviteColumns lists the invite columns in the order listInviteFields expects
+const listInviteColumns = `id, owner_id, token, role, expires_at, revoked_at, created_at`
+
+// listInviteFields returns scan destinations matching listInviteColumns
+func listInviteFields(invite *ListInvite) []interface{} {
+	return []interface{}{
+		&invite.ID, &invite.OwnerID, &invite.Token, &invite.Role,
+		&invite.ExpiresAt, &invite.RevokedAt, &invite.CreatedAt,
+	}
+}
+
+// CreateListInvite creates an invite link to a user's list
+func (d *Database) CreateListInvite(invite NewListInvite) (*ListInvite, error) {
+	ctx := context.Background()
+
+	query := `
+		INSERT INTO list_invites (owner_id, token, role, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + listInviteColumns
+
+	var result ListInvite
+	err := d.db.QueryRowContext(ctx, query,
+		invite.OwnerID, invite.Token, invite.Role, invite.ExpiresAt, time.Now(),
+	).Scan(listInviteFields(&result)...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create list invite: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetListInviteByToken gets an invite by the token in its link, returning
+// nil if there is none
+func (d *Database) GetListInviteByToken(token string) (*ListInvite, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT ` + listInviteColumns + `
+		FROM list_invites
+		WHERE token = $1
+	`
+
+	var invite ListInvite
+	err := d.db.QueryRowContext(ctx, query, token).Scan(listInviteFields(&invite)...)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get list invite: %w", err)
+	}
+
+	return &invite, nil
+}
+
+// GetActiveListInvites gets a user's invites that are neither revoked nor
+// expired, oldest first
+func (d *Database) GetActiveListInvites(ownerID uuid.UUID) ([]ListInvite, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT ` + listInviteColumns + `
+		FROM list_invites
+		WHERE owner_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+		ORDER BY created_at
+	`
+
+	rows, err := d.db.QueryContext(ctx, query, ownerID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list invites: %w", err)
+	}
+	defer rows.Close()
+
+	var invites []ListInvite
+	for rows.Next() {
+		var invite ListInvite
+		if err := rows.Scan(listInviteFields(&invite)...); err != nil {
+			return nil, fmt.Errorf("failed to scan list invite: %w", err)
+		}
+		invites = append(invites, invite)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating list invites: %w", err)
+	}
+
+	return invites, nil
+}
+
+// RevokeListInvite revokes one of a user's invites, reporting whether it
+// was still active
+func (d *Database) RevokeListInvite(ownerID, inviteID uuid.UUID) (bool, error) {
+	ctx := context.Background()
+
+	query := `
+		UPDATE list_invites
+		SET revoked_at = $1
+		WHERE id = $2 AND owner_id = $3 AND revoked_at IS NULL
+	`
+	result, err := d.db.ExecContext(ctx, query, time.Now(), inviteID, ownerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke list invite: %w", err)
+	}
+
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke list invite: %w", err)
+	}
+	return revoked > 0, nil
+}
+
+// JoinList makes a user a member of another user's list. Existing members
+// keep the role the owner gave them; it reports whether the user joined.
+func (d *Database) JoinList(ownerID, memberID uuid.UUID, role string) (bool, error) {
+	ctx := context.Background()
+
+	query := `
+		INSERT INTO list_members (owner_id, member_id, role, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (owner_id, member_id) DO NOTHING
+	`
+	result, err := d.db.ExecContext(ctx, query, ownerID, memberID, role, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to join list: %w", err)
+	}
+
+	joined, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check list join: %w", err)
+	}
+	return joined > 0, nil
+}
+
+// GetListRole gets a member's role on a user's list, or "" if they are not
+// a member
+func (d *Database) GetListRole(ownerID, memberID uuid.UUID) (string, error) {
+	ctx := context.Background()
+
+	query := `SELECT role FROM list_members WHERE owner_id = $1 AND member_id = $2`
+
+	var role string
+	err := d.db.QueryRowContext(ctx, query, ownerID, memberID).Scan(&role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get list role: %w", err)
+	}
+
+	return role, nil
+}
+
+// UpdateListMemberRole changes a member's role on a user's list
+func (d *Database) UpdateListMemberRole(ownerID, memberID uuid.UUID, role string) error {
+	ctx := context.Background()
+
+	query := `UPDATE list_members SET role = $1 WHERE owner_id = $2 AND member_id = $3`
+	if _, err := d.db.ExecContext(ctx, query, role, ownerID, memberID); err != nil {
+		return fmt.Errorf("failed to update list member role: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveListMember removes a member from a user's list
+func (d *Database) RemoveListMember(ownerID, memberID uuid.UUID) error {
+	ctx := context.Background()
+
+	query := `DELETE FROM list_members WHERE owner_id = $1 AND member_id = $2`
+	if _, err := d.db.ExecContext(ctx, query, ownerID, memberID); err != nil {
+		return fmt.Errorf("failed to remove list member: %w", err)
+	}
+
+	return nil
+}
+
+// GetListMembers gets the members of a user's list in the order they joined
+func (d *Database) GetListMembers(ownerID uuid.UUID) ([]ListMember, error) {
+	query := `
+		SELECT ` + qualify(userColumns, "u") + `, m.role, m.created_at
+		FROM list_members m
+		JOIN users u ON u.id = m.member_id
+		WHERE m.owner_id = $1
+		ORDER BY m.created_at
+	`
+	return d.queryListMembers(query, ownerID)
+}
+
+// GetSharedLists gets the lists a user joined, with their owners
+func (d *Database) GetSharedLists(memberID uuid.UUID) ([]ListMember, error) {
+	query := `
+		SELECT ` + qualify(userColumns, "u") + `, m.role, m.created_at
+		FROM list_members m
+		JOIN users u ON u.id = m.owner_id
+		WHERE m.member_id = $1
+		ORDER BY m.created_at
+	`
+	return d.queryListMembers(query, memberID)
+}
+
+// queryListMembers runs a query selecting userColumns, a role and a join
+// time, and scans every row
+func (d *Database) queryListMembers(query string, args ...interface{}) ([]ListMember, error) {
+	ctx := context.Background()
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []ListMember
+	for rows.Next() {
+		var member ListMember
+		dest := append(userFields(&member.User), &member.Role, &member.JoinedAt)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan list member: %w", err)
+		}
+		members = append(members, member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating list members: %w", err)
+	}
+
+	return members, nil
+}
+
+// taskActivityColumns lists the activity columns in the order taskActivityFields expects
+const taskActivityColumns = `id, todo_id, user_id, kind, body, created_at`
+
+// taskActivityFields returns scan destinations matching taskActivityColumns
+func taskActivityFields(entry *TaskActivity) []interface{} {
+	return []interface{}{
+		&entry.ID, &entry.TodoID, &entry.UserID, &entry.Kind, &entry.Body, &entry.CreatedAt,
+	}
+}
+
+// CreateTaskActivity records a comment or change on a task
+func (d *Database) CreateTaskActivity(activity NewTaskActivity) (*TaskActivity, error) {
+	ctx := context.Background()
+
+	query := `
+		INSERT INTO task_activity (todo_id, user_id, kind, body, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + taskActivityColumns
+
+	var result TaskActivity
+	err := d.db.QueryRowContext(ctx, query,
+		activity.TodoID, activity.UserID, activity.Kind, activity.Body, time.Now(),
+	).Scan(taskActivityFields(&result)...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task activity: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetTaskActivity gets the latest activity on a task, oldest first
+func (d *Database) GetTaskActivity(todoID uuid.UUID, limit int) ([]TaskActivity, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT ` + taskActivityColumns + `
+		FROM (
+			SELECT ` + taskActivityColumns + `
+			FROM task_activity
+			WHERE todo_id = $1
+			ORDER BY created_at DESC
+			LIMIT $2
+		) latest
+		ORDER BY created_at
+	`
+
+	rows, err := d.db.QueryContext(ctx, query, todoID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task activity: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []TaskActivity
+	for rows.Next() {
+		var entry TaskActivity
+		if err := rows.Scan(taskActivityFields(&entry)...); err != nil {
+			return nil, fmt.Errorf("failed to scan task activity: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task activity: %w", err)
+	}
+
+	return entries, nil
+}
+
+// SetTaskWatching records whether a user watches a task
+func (d *Database) SetTaskWatching(todoID, userID uuid.UUID, watching bool) error {
+	ctx := context.Background()
+
+	query := `
+		INSERT INTO task_watchers (todo_id, user_id, watching, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (todo_id, user_id) DO UPDATE SET watching = EXCLUDED.watching
+	`
+	if _, err := d.db.ExecContext(ctx, query, todoID, userID, watching, time.Now()); err != nil {
+		return fmt.Errorf("failed to set task watching: %w", err)
+	}
+
+	return nil
+}
+
+// AddTaskWatcher makes a user watch a task unless they already chose
+// whether to
+func (d *Database) AddTaskWatcher(todoID, userID uuid.UUID) error {
+	ctx := context.Background()
+
+	query := `
+		INSERT INTO task_watchers (todo_id, user_id, watching, created_at)
+		VALUES ($1, $2, true, $3)
+		ON CONFLICT (todo_id, user_id) DO NOTHING
+	`
+	if _, err := d.db.ExecContext(ctx, query, todoID, userID, time.Now()); err != nil {
+		return fmt.Errorf("failed to add task watcher: %w", err)
+	}
+
+	return nil
+}
+
+// GetTaskWatchers gets the users watching a task: its owner and assignee
+// unless they unwatched it, and everyone who chose to watch it
+func (d *Database) GetTaskWatchers(todoID uuid.UUID) ([]User, error) {
+	query := `
+		SELECT ` + qualify(userColumns, "u") + `
+		FROM users u
+		WHERE (
+			u.id IN (SELECT user_id FROM todos WHERE id = $1)
+			OR u.id IN (SELECT assignee_id FROM todos WHERE id = $1 AND assignee_id IS NOT NULL)
+			OR u.id IN (SELECT user_id FROM task_watchers WHERE todo_id = $1 AND watching)
+		)
+		AND u.id NOT IN (SELECT user_id FROM task_watchers WHERE todo_id = $1 AND NOT watching)
+		ORDER BY u.created_at
+	`
+	return d.queryUsers(query, todoID)
+}
+
+// exportTables lists the tables included in a personal data export, in
+// the order they are written
+var exportTables = []string{"users", "todos", "reminders", "chat_members", "list_invites", "list_members", "task_activity", "task_watchers"}
+
+// exportQueries select a user's rows of each exported table as one JSON array
+var exportQueries = map[string]string{
+	"users":         `SELECT COALESCE(json_agg(u), '[]') FROM users u WHERE u.id = $1`,
+	"todos":         `SELECT COALESCE(json_agg(t ORDER BY t.created_at), '[]') FROM todos t WHERE t.user_id = $1`,
+	"reminders":     `SELECT COALESCE(json_agg(r ORDER BY r.created_at), '[]') FROM reminders r JOIN todos t ON t.id = r.todo_id WHERE t.user_id = $1`,
+	"chat_members":  `SELECT COALESCE(json_agg(m ORDER BY m.last_seen_at), '[]') FROM chat_members m WHERE m.user_id = $1`,
+	"list_invites":  `SELECT COALESCE(json_agg(i ORDER BY i.created_at), '[]') FROM list_invites i WHERE i.owner_id = $1`,
+	"list_members":  `SELECT COALESCE(json_agg(m ORDER BY m.created_at), '[]') FROM list_members m WHERE m.owner_id = $1 OR m.member_id = $1`,
+	"task_activity": `SELECT COALESCE(json_agg(a ORDER BY a.created_at), '[]') FROM task_activity a WHERE a.user_id = $1`,
+	"task_watchers": `SELECT COALESCE(json_agg(w ORDER BY w.created_at), '[]') FROM task_watchers w WHERE w.user_id = $1`,
+}
+
+// ExportUserData gets all of a user's rows as JSON arrays keyed by table
+// name. The tables are read from one snapshot so they agree with each other.
+func (d *Database) ExportUserData(userID uuid.UUID) (map[string][]byte, error) {
+	ctx := context.Background()
+
+	tx, err := d.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	data := make(map[string][]byte, len(exportTables))
+	for _, table := range exportTables {
+		var rows []byte
+		if err := tx.QueryRowContext(ctx, exportQueries[table], userID).Scan(&rows); err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", table, err)
+		}
+		data[table] = rows
+	}
+
+	return data, nil
+}
+
+// DeleteUserAccount deletes a user and, through ON DELETE CASCADE, their
+// todos, reminders, deliveries and anything else that references them.
+// Invite links and list shares the user issued or joined are removed
+// explicitly, and other users stop escalating to the user's chat. Group
+// tasks belong to the group, so they stay behind without a creator and
+// only lose the user's reminders. The audit entry is written in the same
+// transaction, so a deletion is never left unrecorded.
+func (d *Database) DeleteUserAccount(userID uuid.UUID, audit NewAuditEntry) error {
+	ctx := context.Background()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertAuditEntry(ctx, tx, audit); err != nil {
+		return err
+	}
+
+	query := `
+		DELETE FROM reminders
+		WHERE todo_id IN (SELECT id FROM todos WHERE user_id = $1 AND chat_id IS NOT NULL)
+	`
+	if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to delete group task reminders: %w", err)
+	}
+	query = `UPDATE todos SET user_id = NULL, updated_at = $1 WHERE user_id = $2 AND chat_id IS NOT NULL`
+	if _, err := tx.ExecContext(ctx, query, time.Now(), userID); err != nil {
+		return fmt.Errorf("failed to detach group tasks: %w", err)
+	}
+
+	query = `
+		UPDATE users
+		SET escalate_contact_chat_id = NULL, updated_at = $1
+		WHERE escalate_contact_chat_id = (SELECT telegram_id FROM users WHERE id = $2)
+	`
+	if _, err := tx.ExecContext(ctx, query, time.Now(), userID); err != nil {
+		return fmt.Errorf("failed to clear escalation contacts: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM list_invites WHERE owner_id = $1", userID); err != nil {
+		return fmt.Errorf("failed to delete list invites: %w", err)
+	}
+	query = `DELETE FROM list_members WHERE owner_id = $1 OR member_id = $1`
+	if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to delete list shares: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = $1", userID); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit user deletion: %w", err)
+	}
+
+	return nil
+}
+
+// RecordAudit appends an entry to the audit log
+func (d *Database) RecordAudit(audit NewAuditEntry) error {
+	return insertAuditEntry(context.Background(), d.db, audit)
+}
+
+// sqlExecer is satisfied by both *sql.DB and *sql.Tx
+type sqlExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertAuditEntry writes an audit log entry
+func insertAuditEntry(ctx context.Context, db sqlExecer, audit NewAuditEntry) error {
+	query := `
+		INSERT INTO audit_log (user_id, telegram_id, action, details, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	if _, err := db.ExecContext(ctx, query, audit.UserID, audit.TelegramID, audit.Action, audit.Details, time.Now()); err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+)
+
+// defaultDigestTime is the local time the overdue digest is sent at
+const defaultDigestTime = "09:00"
+
+// maxDigestTasks caps how many tasks get buttons in one digest
+const maxDigestTasks = 20
+
+// sendOverdueDigests sends each user whose digest time has passed today a
+// single message listing their overdue pending tasks
+func (b *Bot) sendOverdueDigests() {
+	overdue, err := b.db.GetAllOverdueTodos()
+	if err != nil {
+		log.Printf("Failed to get overdue todos: %v", err)
+		return
+	}
+
+	// Results are ordered by due time, so each user's tasks stay in order
+	users := make(map[uuid.UUID]User)
+	var order []uuid.UUID
+	tasks := make(map[uuid.UUID][]Todo)
+	for _, item := range overdue {
+		if !item.User.DigestEnabled {
+			continue
+		}
+		if _, ok := users[item.User.ID]; !ok {
+			users[item.User.ID] = item.User
+			order = append(order, item.User.ID)
+		}
+		tasks[item.User.ID] = append(tasks[item.User.ID], item.Todo)
+	}
+
+	now := time.Now()
+	for _, userID := range order {
+		user := users[userID]
+
+		digestTime := user.DigestTime
+		if digestTime == "" {
+			digestTime = defaultDigestTime
+		}
+		if !b.claimDailyMessage(&user, dailyDigest, digestTime, now) {
+			continue
+		}
+
+		if _, err := b.api.Send(b.digestMessage(&user, tasks[userID])); err != nil {
+			log.Printf("Failed to send digest to user %d: %v", user.TelegramID, err)
+			b.releaseDailyMessage(&user, dailyDigest, user.LastDigestAt)
+		}
+	}
+}
+
+// digestMessage builds the overdue digest with per-task buttons
+func (b *Bot) digestMessage(user *User, todos []Todo) tgbotapi.MessageConfig {
+	c := userCatalog(user)
+	tf := userTimeFormat(user)
+	var text strings.Builder
+	text.WriteString(c.T("digest.title", "count", len(todos)) + "\n\n")
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var titles []string
+	for i, todo := range todos {
+		if i == maxDigestTasks {
+			text.WriteString(c.T("digest.more", "count", len(todos)-maxDigestTasks) + "\n")
+			break
+		}
+		titles = append(titles, html.EscapeString(todo.Title))
+
+		text.WriteString(fmt.Sprintf("%d. <b>%s</b>\n   %s\n", i+1,
+			html.EscapeString(todo.Title), c.T("list.due", "time", tf.Time(*todo.DueTime))))
+
+		n := i + 1
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("✅ %d", n), fmt.Sprintf("dgdone:%s", todo.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("review.tomorrow", "number", n), fmt.Sprintf("dgtomorrow:%s", todo.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("digest.drop", "number", n), fmt.Sprintf("dgdrop:%s", todo.ID)),
+		))
+	}
+
+	text.WriteString("\n" + c.T("digest.footer"))
+
+	compact := c.T("digest.compact", "count", len(todos), "titles", strings.Join(titles, " · "))
+	msg := tgbotapi.NewMessage(user.TelegramID, styled(user, text.String(), compact, numberedTitles(titles)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+	return msg
+}
+
+// ownTodo loads a todo from callback data and checks it belongs to the caller
+func (b *Bot) ownTodo(telegramID int64, todoIDStr string) (*Todo, *User, error) {
+	todo, user, err := b.userTodo(telegramID, todoIDStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ownsTodo(todo, user) {
+		return nil, nil, fmt.Errorf("todo %s does not belong to user %d", todo.ID, telegramID)
+	}
+	return todo, user, nil
+}
+
+// completableTodo loads a todo from callback data and checks the caller
+// may complete it
+func (b *Bot) completableTodo(telegramID int64, todoIDStr string) (*Todo, *User, error) {
+	todo, user, err := b.userTodo(telegramID, todoIDStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !b.canCompleteTodo(todo, user) {
+		return nil, nil, fmt.Errorf("todo %s may not be completed by user %d", todo.ID, telegramID)
+	}
+	return todo, user, nil
+}
+
+// viewableTodo loads a todo from callback data and checks the caller can
+// see it
+func (b *Bot) viewableTodo(telegramID int64, todoIDStr string) (*Todo, *User, error) {
+	todo, user, err := b.userTodo(telegramID, todoIDStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !b.canViewTodo(todo, user) {
+		return nil, nil, fmt.Errorf("todo %s is not visible to user %d", todo.ID, telegramID)
+	}
+	return todo, user, nil
+}
+
+// userTodo loads a todo by ID together with the Telegram user asking for
+// it, leaving permission checks to the caller
+func (b *Bot) userTodo(telegramID int64, todoIDStr string) (*Todo, *User, error) {
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid todo ID: %w", err)
+	}
+
+	user, err := b.db.GetUserByTelegramID(telegramID)
+	if err != nil || user == nil {
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	todo, err := b.db.GetTodoByID(todoID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return todo, user, nil
+}
+
+// handleDigestCallback handles the complete, tomorrow and drop buttons of a digest
+func (b *Bot) handleDigestCallback(callback *tgbotapi.CallbackQuery, action, todoIDStr string) error {
+	c := b.catalog(callback.From.ID)
+	todo, user, err := b.ownTodo(callback.From.ID, todoIDStr)
+	if err != nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            c.T("task.not_found_short"),
+		})
+		return err
+	}
+
+	var status string
+	switch action {
+	case "dgdone":
+		todo, _, err = b.closeTodo(todo, user, "completed")
+		status = c.T("digest.done")
+	case "dgdrop":
+		todo, _, err = b.closeTodo(todo, user, "cancelled")
+		status = c.T("digest.dropped")
+	case "dgtomorrow":
+		todo, err = b.moveToTomorrow(todo, user)
+		if err == nil {
+			status = c.T("digest.moved", "time", userTimeFormat(user).Time(*todo.DueTime))
+		}
+	}
+	if err == nil && todo == nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            c.T("complete.not_pending_short"),
+		})
+		return err
+	}
+	if err != nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            c.T("task.update_failed"),
+		})
+		return err
+	}
+
+	if err := b.finishReminderMessage(callback, todo.ID, status+": "+todo.Title); err != nil {
+		log.Printf("Failed to update digest message: %v", err)
+	}
+
+	_, err = b.api.Request(tgbotapi.CallbackConfig{
+		CallbackQueryID: callback.ID,
+		Text:            status,
+	})
+	return err
+}
+
+// handleDigest handles the /digest command
+func (b *Bot) handleDigest(message *tgbotapi.Message) error {
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	c := userCatalog(user)
+
+	digestTime := user.DigestTime
+	if digestTime == "" {
+		digestTime = defaultDigestTime
+	}
+
+	args := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	var msgText string
+	switch args {
+	case "":
+		status := c.T("digest.off")
+		if user.DigestEnabled {
+			status = c.T("digest.on_at", "time", digestTime)
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("digest.help", "status", status))
+		msg.ParseMode = "HTML"
+		_, err := b.api.Send(msg)
+		return err
+
+	case "on":
+		err = b.db.UpdateUserDigest(user.ID, true, digestTime)
+		msgText = c.T("digest.turned_on", "time", digestTime)
+
+	case "off":
+		err = b.db.UpdateUserDigest(user.ID, false, digestTime)
+		msgText = c.T("digest.turned_off")
+
+	default:
+		hour, minute, parseErr := parseClock(args)
+		if parseErr != nil {
+			msg := tgbotapi.NewMessage(message.Chat.ID, c.T("daily.invalid_time", "command", "digest", "example", "08:30"))
+			_, err := b.api.Send(msg)
+			return err
+		}
+		digestTime = fmt.Sprintf("%02d:%02d", hour, minute)
+		err = b.db.UpdateUserDigest(user.ID, true, digestTime)
+		msgText = c.T("digest.set", "time", digestTime, "zone", b.getUserTimezone(message.From.ID))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update digest: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
+	_, err = b.api.Send(msg)
+	return err
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// parseOffsets parses a list of offsets before a due time such as
+// "24h and 1h before due" or "1d,2h" into distinct durations, longest first
+func parseOffsets(input string) ([]time.Duration, error) {
+	s := strings.ToLower(strings.TrimSpace(input))
+	s = strings.TrimSuffix(s, "before due")
+
+	var offsets []time.Duration
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if field == "and" {
+			continue
+		}
+		d, err := parseDuration(field)
+		if err != nil || d <= 0 {
+			return nil, newLocalizedError("error.invalid_offset", "input", field)
+		}
+		offsets = append(offsets, d)
+	}
+
+	return normalizeOffsets(offsets), nil
+}
+
+// normalizeOffsets removes duplicate offsets and sorts them longest first
+func normalizeOffsets(offsets []time.Duration) []time.Duration {
+	seen := make(map[time.Duration]bool)
+	var result []time.Duration
+	for _, d := range offsets {
+		if !seen[d] {
+			seen[d] = true
+			result = append(result, d)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] > result[j] })
+	return result
+}
+
+// formatOffsets renders offsets in the compact form parseOffsets accepts
+func formatOffsets(offsets []time.Duration) string {
+	parts := make([]string, len(offsets))
+	for i, d := range offsets {
+		parts[i] = formatInterval(d)
+	}
+	return strings.Join(parts, ",")
+}
+
+// baseOffsets returns the user's default offsets before a due time. Users
+// who never chose any get a single reminder default_reminder_interval hours
+// before the due time. Users from before due reminders existed start with an
+// empty setting, so they get none until they choose some.
+func baseOffsets(user *User) []time.Duration {
+	if user.ReminderOffsets != nil {
+		offsets, _ := parseOffsets(*user.ReminderOffsets)
+		return offsets
+	}
+	if user.DefaultReminderInterval > 0 {
+		return []time.Duration{time.Duration(user.DefaultReminderInterval) * time.Hour}
+	}
+	return nil
+}
+
+// dueOffsets returns the offsets to use for a task of the given priority
+func dueOffsets(user *User, priority string) []time.Duration {
+	offsets := baseOffsets(user)
+	if priority == "high" && user.HighPriorityOffsets != nil {
+		extra, _ := parseOffsets(*user.HighPriorityOffsets)
+		offsets = append(offsets, extra...)
+	}
+	return normalizeOffsets(offsets)
+}
+
+// syncDueReminders recreates a todo's automatic reminders from its due time
+// and the user's default offsets. Offsets that have already passed are
+// skipped. It returns the number of reminders scheduled.
+func (b *Bot) syncDueReminders(todo *Todo, user *User) (int, error) {
+	var reminders []NewReminder
+	if todo.DueTime != nil && todo.Status == "pending" {
+		now := time.Now()
+		for _, offset := range dueOffsets(user, todo.Priority) {
+			at := todo.DueTime.Add(-offset)
+			if !at.After(now) {
+				continue
+			}
+			seconds := int64(offset / time.Second)
+			reminders = append(reminders, NewReminder{
+				TodoID:           todo.ID,
+				RepeatCount:      1,
+				NextNotifyTime:   at.UTC(),
+				DueOffsetSeconds: &seconds,
+			})
+		}
+	}
+
+	if err := b.db.ReplaceDueReminders(todo.ID, reminders); err != nil {
+		return 0, err
+	}
+	return len(reminders), nil
+}
+
+// parseDueTime parses a due time such as "2h", "tomorrow 18:00" or
+// "2026-11-02 17:00" in the given location
+func parseDueTime(input string, now time.Time, loc *time.Location) (time.Time, error) {
+	s := strings.ToLower(strings.TrimSpace(input))
+	if d, err := parseDuration(strings.TrimPrefix(s, "in ")); err == nil && d > 0 {
+		return now.Add(d), nil
+	}
+
+	t, _, err := parseReminderTime(s, now, loc, nil)
+	return t, err
+}
+
+// splitDueSuffix splits a trailing "due <when>" off task text, e.g.
+// "Pay rent due tomorrow 9am". Text without a valid due suffix is returned
+// unchanged.
+func splitDueSuffix(text string, now time.Time, loc *time.Location) (string, *time.Time) {
+	i := strings.LastIndex(strings.ToLower(text), " due ")
+	if i <= 0 {
+		return text, nil
+	}
+
+	due, err := parseDueTime(text[i+len(" due "):], now, loc)
+	if err != nil {
+		return text, nil
+	}
+	return strings.TrimSpace(text[:i]), &due
+}
+
+// describeOffsets renders offsets for display, e.g. "24h and 1h before due"
+func describeOffsets(c *Catalog, offsets []time.Duration) string {
+	if len(offsets) == 0 {
+		return c.T("offsets.none")
+	}
+	parts := make([]string, len(offsets))
+	for i, d := range offsets {
+		parts[i] = formatInterval(d)
+	}
+	return c.T("repeat.before_due", "offsets", strings.Join(parts, c.T("offsets.separator")))
+}
+
+// resyncUserDueReminders reschedules the automatic reminders of all of a
+// user's pending tasks, used after the defaults change
+func (b *Bot) resyncUserDueReminders(user *User) int {
+	todos, err := b.db.GetUserTodos(user.ID)
+	if err != nil {
+		log.Printf("Failed to get todos for user %s: %v", user.ID, err)
+		return 0
+	}
+
+	updated := 0
+	for i := range todos {
+		// Tasks assigned to the user follow their owner's defaults
+		if todos[i].DueTime == nil || todos[i].Status != "pending" || !ownsTodo(&todos[i], user) {
+			continue
+		}
+		if _, err := b.syncDueReminders(&todos[i], user); err != nil {
+			log.Printf("Failed to reschedule due reminders for todo %s: %v", todos[i].ID, err)
+			continue
+		}
+		updated++
+	}
+	return updated
+}
+
+// handleDefaults handles the /defaults command
+func (b *Bot) handleDefaults(message *tgbotapi.Message) error {
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	c := userCatalog(user)
+
+	args := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	if args == "" {
+		high := c.T("offsets.none")
+		if user.HighPriorityOffsets != nil {
+			offsets, _ := parseOffsets(*user.HighPriorityOffsets)
+			high = describeOffsets(c, offsets)
+		}
+		msgText := c.T("defaults.help",
+			"all", describeOffsets(c, baseOffsets(user)), "high", high, "hours", user.DefaultReminderInterval)
+		msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
+		msg.ParseMode = "HTML"
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	high := false
+	if rest := strings.TrimPrefix(args, "high"); rest != args {
+		high = true
+		args = strings.TrimSpace(rest)
+	}
+
+	var stored *string
+	switch {
+	case args == "reset", args == "off" && high:
+		stored = nil
+	case args == "off":
+		stored = stringPtr("")
+	default:
+		offsets, err := parseOffsets(args)
+		if err != nil || len(offsets) == 0 {
+			msg := tgbotapi.NewMessage(message.Chat.ID, c.T("defaults.invalid"))
+			_, err := b.api.Send(msg)
+			return err
+		}
+		stored = stringPtr(formatOffsets(offsets))
+	}
+
+	if high {
+		err = b.db.UpdateUserHighPriorityOffsets(user.ID, stored)
+		user.HighPriorityOffsets = stored
+	} else {
+		err = b.db.UpdateUserReminderOffsets(user.ID, stored)
+		user.ReminderOffsets = stored
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update reminder defaults: %w", err)
+	}
+
+	updated := b.resyncUserDueReminders(user)
+
+	var msgText string
+	if high {
+		offsets := []time.Duration(nil)
+		if stored != nil {
+			offsets, _ = parseOffsets(*stored)
+		}
+		msgText = c.T("defaults.high_set", "offsets", describeOffsets(c, offsets))
+	} else {
+		msgText = c.T("defaults.set", "offsets", describeOffsets(c, baseOffsets(user)))
+	}
+	if updated > 0 {
+		msgText += "\n\n" + c.T("defaults.updated", "count", updated)
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleDue handles the /due command
+func (b *Bot) handleDue(message *tgbotapi.Message) error {
+	c := b.catalog(message.From.ID)
+	parts := strings.SplitN(strings.TrimSpace(message.CommandArguments()), " ", 2)
+	if len(parts) != 2 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("due.usage"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	taskNum, err := strconv.Atoi(parts[0])
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.invalid_number"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	todos, err := b.db.GetUserTodos(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get todos: %w", err)
+	}
+
+	if taskNum < 1 || taskNum > len(todos) {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.not_found", "max", len(todos)))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	todo := todos[taskNum-1]
+	if !b.canEditTodo(&todo, user) {
+		return b.sendNotAllowed(message.Chat.ID, c, &todo)
+	}
+	owner, err := b.todoOwner(&todo, user)
+	if err != nil {
+		return err
+	}
+
+	var dueTime *time.Time
+	if strings.ToLower(strings.TrimSpace(parts[1])) != "off" {
+		due, err := parseDueTime(parts[1], b.nowInUserTimezone(message.From.ID), b.userLocation(message.From.ID))
+		if err != nil {
+			msg := tgbotapi.NewMessage(message.Chat.ID, c.T("due.invalid_time", "error", c.Error(err)))
+			_, err := b.api.Send(msg)
+			return err
+		}
+		due = due.UTC()
+		dueTime = &due
+	}
+
+	updatedTodo, err := b.db.UpdateTodoDueTime(todo.ID, dueTime)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("due.failed"))
+		_, err2 := b.api.Send(msg)
+		return err2
+	}
+
+	var dueBody *string
+	if dueTime != nil {
+		dueBody = stringPtr(dueTime.Format(time.RFC3339))
+	}
+	b.recordActivity(updatedTodo, user, activityDue, dueBody)
+
+	scheduled, err := b.syncDueReminders(updatedTodo, owner)
+	if err != nil {
+		log.Printf("Failed to schedule due reminders for todo %s: %v", updatedTodo.ID, err)
+	}
+
+	title := html.EscapeString(updatedTodo.Title)
+	tf := userTimeFormat(user)
+	var msgText string
+	if dueTime == nil {
+		msgText = c.T("due.removed", "title", title)
+	} else {
+		msgText = c.T("due.set", "title", title, "time", tf.When(*dueTime))
+		if scheduled > 0 {
+			msgText += "\n\n" + c.T("add.reminders_scheduled", "count", scheduled) + ". " + c.T("due.change_defaults")
+		}
+	}
+
+	minimal := c.T("due.removed_minimal", "title", title)
+	if dueTime != nil {
+		minimal = c.T("due.set_minimal", "title", title, "time", tf.When(*dueTime))
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, styled(user, msgText, "📅 "+minimal, minimal))
+	msg.ParseMode = "HTML"
+	_, err = b.api.Send(msg)
+	return err
+}
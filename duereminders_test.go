@@ -0,0 +1,35 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseOffsets(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []time.Duration
+	}{
+		{"1h", []time.Duration{time.Hour}},
+		{"24h and 1h before due", []time.Duration{24 * time.Hour, time.Hour}},
+		{"15m,1d, 2h", []time.Duration{24 * time.Hour, 2 * time.Hour, 15 * time.Minute}},
+		{"1d 24h", []time.Duration{24 * time.Hour}},
+	}
+	for _, tt := range tests {
+		got, err := parseOffsets(tt.input)
+		if err != nil {
+			t.Errorf("parseOffsets(%q) error: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseOffsets(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"0h", "soon", "1h, tomorrow"} {
+		if _, err := parseOffsets(input); err == nil {
+			t.Errorf("parseOffsets(%q) succeeded, want an error", input)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+)
+
+// defaultEscalateMax is how many times an ignored reminder is resent
+// before escalation gives up
+const defaultEscalateMax = 2
+
+// Someone else becomes a user's escalation contact by opening a link that
+// starts the bot with /start esc_<token>, so nobody is alerted without
+// having agreed to it
+const (
+	escalationPayloadPrefix = "esc_"
+	escalationInviteTTL     = 24 * time.Hour
+)
+
+// checkEscalations resends high priority reminders nobody acted on, more
+// urgently each time, and tells the user's escalation contact once the
+// resends run out
+func (b *Bot) checkEscalations() {
+	pending, err := b.db.GetPendingEscalations()
+	if err != nil {
+		log.Printf("Failed to get pending escalations: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, item := range pending {
+		user := item.User
+
+		// Escalations respect quiet hours like the reminders themselves
+		_, isDND, quiet := b.quietUntil(&user, now)
+		if quiet && (isDND || !user.QuietBypassHigh) {
+			continue
+		}
+
+		claimed, err := b.db.ClaimEscalation(item.DeliveryID, item.EscalationLevel)
+		if err != nil {
+			log.Printf("Failed to claim escalation of reminder %s: %v", item.Reminder.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		level := item.EscalationLevel + 1
+		if level <= user.EscalateMax {
+			if _, err := b.api.Send(b.escalationMessage(&user, item, level)); err != nil {
+				log.Printf("Failed to send escalation of reminder %s: %v", item.Reminder.ID, err)
+			}
+			continue
+		}
+
+		if user.EscalateContactChatID != nil {
+			if _, err := b.api.Send(b.escalationContactMessage(&user, item)); err != nil {
+				log.Printf("Failed to alert escalation contact of user %d: %v", user.TelegramID, err)
+			}
+		}
+	}
+}
+
+// escalationMessage builds the resent notification for an ignored reminder
+func (b *Bot) escalationMessage(user *User, item PendingEscalation, level int) tgbotapi.MessageConfig {
+	c := userCatalog(user)
+	heading := c.T("escalation.second_notice")
+	prefix := "⚠️"
+	if level >= 2 {
+		heading = c.T("escalation.urgent")
+		prefix = "🚨"
+	}
+
+	title := html.EscapeString(item.Todo.Title)
+	text := fmt.Sprintf("%s\n\n🔴 <b>%s</b>\n", heading, title)
+	if item.Todo.DueTime != nil {
+		text += c.T("list.due", "time", userTimeFormat(user).Time(*item.Todo.DueTime)) + "\n"
+	}
+	text += "\n" + c.T("escalation.waiting")
+
+	msg := tgbotapi.NewMessage(user.TelegramID, styled(user, text,
+		fmt.Sprintf("%s <b>%s</b>", prefix, title), c.T("escalation.still_pending", "title", title)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = b.reminderKeyboard(item.Reminder, user)
+	return msg
+}
+
+// escalationContactMessage builds the alert sent to the user's escalation contact
+func (b *Bot) escalationContactMessage(user *User, item PendingEscalation) tgbotapi.MessageConfig {
+	c := userCatalog(user)
+	text := c.T("escalation.contact_alert",
+		"name", html.EscapeString(user.Name), "title", html.EscapeString(item.Todo.Title))
+	if item.Todo.DueTime != nil {
+		text += "\n" + c.T("list.due", "time", userTimeFormat(user).Time(*item.Todo.DueTime))
+	}
+
+	msg := tgbotapi.NewMessage(*user.EscalateContactChatID, text)
+	msg.ParseMode = "HTML"
+	return msg
+}
+
+// markReminderInteracted stops a reminder's notifications from escalating
+func (b *Bot) markReminderInteracted(reminderID uuid.UUID) {
+	if err := b.db.MarkReminderInteracted(reminderID); err != nil {
+		log.Printf("Failed to mark reminder %s interacted: %v", reminderID, err)
+	}
+}
+
+// handleEscalate handles the /escalate command
+func (b *Bot) handleEscalate(message *tgbotapi.Message) error {
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	c := userCatalog(user)
+
+	fields := strings.Fields(strings.ToLower(message.CommandArguments()))
+	var msgText string
+	switch {
+	case len(fields) == 0:
+		status := c.T("escalation.off")
+		if user.EscalateAfterMinutes > 0 {
+			status = c.T("escalation.every",
+				"interval", formatInterval(time.Duration(user.EscalateAfterMinutes)*time.Minute), "count", user.EscalateMax)
+		}
+		contact := c.T("escalation.no_contact")
+		if user.EscalateContactChatID != nil {
+			contact = strconv.FormatInt(*user.EscalateContactChatID, 10)
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("escalation.help", "status", status, "contact", contact))
+		msg.ParseMode = "HTML"
+		_, err := b.api.Send(msg)
+		return err
+
+	case fields[0] == "off":
+		err = b.db.UpdateUserEscalation(user.ID, 0, user.EscalateMax)
+		msgText = c.T("escalation.turned_off")
+
+	case fields[0] == "contact" && len(fields) == 2:
+		var chatID *int64
+		switch fields[1] {
+		case "off":
+		case "here":
+			chatID = &message.Chat.ID
+		case "invite":
+			return b.sendEscalationInvite(message.Chat.ID, user)
+		default:
+			msg := tgbotapi.NewMessage(message.Chat.ID, c.T("escalation.invalid_chat"))
+			_, err := b.api.Send(msg)
+			return err
+		}
+		err = b.db.UpdateUserEscalationContact(user.ID, chatID)
+		if chatID == nil {
+			msgText = c.T("escalation.contact_removed")
+		} else {
+			msgText = c.T("escalation.contact_set", "chat", *chatID)
+		}
+
+	default:
+		after, parseErr := parseDuration(fields[0])
+		max := user.EscalateMax
+		if max <= 0 {
+			max = defaultEscalateMax
+		}
+		if len(fields) == 2 {
+			n, convErr := strconv.Atoi(strings.TrimPrefix(fields[1], "x"))
+			if convErr != nil || n < 1 {
+				parseErr = fmt.Errorf("invalid count: %s", fields[1])
+			}
+			max = n
+		}
+		if parseErr != nil || after < time.Minute || len(fields) > 2 {
+			msg := tgbotapi.NewMessage(message.Chat.ID, c.T("escalation.invalid"))
+			_, err := b.api.Send(msg)
+			return err
+		}
+		minutes := int(after / time.Minute)
+		err = b.db.UpdateUserEscalation(user.ID, minutes, max)
+		msgText = c.T("escalation.set",
+			"interval", formatInterval(time.Duration(minutes)*time.Minute), "count", max)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update escalation: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// sendEscalationInvite sends the user a link that makes whoever opens it
+// their escalation contact
+func (b *Bot) sendEscalationInvite(chatID int64, user *User) error {
+	c := userCatalog(user)
+	token, err := newInviteToken()
+	if err != nil {
+		return err
+	}
+	if err := b.db.SetEscalationInvite(user.ID, token, time.Now().Add(escalationInviteTTL)); err != nil {
+		return fmt.Errorf("failed to create escalation invite: %w", err)
+	}
+
+	link := fmt.Sprintf("https://t.me/%s?start=%s%s", b.api.Self.UserName, escalationPayloadPrefix, token)
+	msg := tgbotapi.NewMessage(chatID, c.T("escalation.invite", "link", link))
+	msg.DisableWebPagePreview = true
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// acceptEscalationInvite makes the chat that opened an escalation link the
+// contact of the user who shared it
+func (b *Bot) acceptEscalationInvite(chatID int64, contact *User, token string) error {
+	c := userCatalog(contact)
+	owner, err := b.db.AcceptEscalationInvite(token, chatID)
+	if err != nil {
+		return err
+	}
+	if owner == nil {
+		msg := tgbotapi.NewMessage(chatID, c.T("escalation.invite_invalid"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	if owner.ID != contact.ID {
+		oc := userCatalog(owner)
+		b.notifyUser(owner, oc.T("escalation.contact_accepted_owner", "name", html.EscapeString(userDisplayName(contact))))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, c.T("escalation.contact_accepted", "name", html.EscapeString(userDisplayName(owner))))
+	msg.ParseMode = "HTML"
+	_, err = b.api.Send(msg)
+	return err
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateFormatText writes dates with the catalog's month and weekday names,
+// e.g. "Sat 31 Dec 2026" or "ส. 31 ธ.ค. 2569"
+const dateFormatText = "text"
+
+// dateFormats lists the date formats in the order settings shows them
+var dateFormats = []string{dateFormatText, "iso", "dmy", "mdy"}
+
+// formatDate writes a date in the user's date format. Written and
+// day-first or month-first dates use the catalog's era, so Thai dates
+// carry Buddhist-era years; ISO dates always stay Gregorian.
+func formatDate(c *Catalog, user *User, t, now time.Time) string {
+	year := strconv.Itoa(t.Year() + c.yearOffset)
+	switch dateFormat(user) {
+	case "iso":
+		return t.Format("2006-01-02")
+	case "dmy":
+		return t.Format("02/01/") + year
+	case "mdy":
+		return t.Format("01/02/") + year
+	}
+
+	key := "date.text"
+	if t.Year() == now.Year() {
+		key = "date.text_this_year"
+	}
+	return c.T(key,
+		"weekday", c.T("date.weekday."+strconv.Itoa(int(t.Weekday()))),
+		"day", t.Day(),
+		"month", c.T("date.month."+strconv.Itoa(int(t.Month()))),
+		"year", year)
+}
+
+// formatClock writes a time of day on the user's 12 or 24-hour clock
+func formatClock(c *Catalog, user *User, t time.Time) string {
+	if user == nil || user.Clock24h {
+		return c.T("time.clock_24", "hour", t.Format("15"), "minute", t.Format("04"))
+	}
+	period := c.T("time.am")
+	if t.Hour() >= 12 {
+		period = c.T("time.pm")
+	}
+	return c.T("time.clock_12", "hour", t.Format("3"), "minute", t.Format("04"), "period", period)
+}
+
+// formatDateTime writes a date and time, naming yesterday, today and
+// tomorrow instead of writing out their dates. Both times must be in the
+// user's timezone.
+func formatDateTime(c *Catalog, user *User, t, now time.Time) string {
+	clock := formatClock(c, user, t)
+	switch daysBetween(now, t) {
+	case -1:
+		return c.T("time.yesterday_at", "time", clock)
+	case 0:
+		return c.T("time.today_at", "time", clock)
+	case 1:
+		return c.T("time.tomorrow_at", "time", clock)
+	}
+	return c.T("time.date_at", "date", formatDate(c, user, t, now), "time", clock)
+}
+
+// daysBetween counts the calendar days from one local date to another
+func daysBetween(from, to time.Time) int {
+	fromDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDay := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDay.Sub(fromDay).Hours() / 24)
+}
+
+// formatRelative describes how far away a time is, e.g. "in 3h" or "2d ago"
+func formatRelative(c *Catalog, d time.Duration) string {
+	switch {
+	case d > -time.Minute && d < time.Minute:
+		return c.T("time.now")
+	case d < 0:
+		return c.T("time.ago", "span", formatSpan(c, -d))
+	}
+	return c.T("time.in", "span", formatSpan(c, d))
+}
+
+// formatSpan writes a duration rounded for reading: minutes below an hour,
+// hours and minutes below ten hours, then whole hours and days
+func formatSpan(c *Catalog, d time.Duration) string {
+	d = d.Round(time.Minute)
+	switch {
+	case d < time.Hour:
+		return c.T("time.span.minutes", "count", int64(d/time.Minute))
+	case d < 10*time.Hour && d%time.Hour != 0:
+		return c.T("time.span.hours_minutes", "hours", int64(d/time.Hour), "minutes", int64(d%time.Hour/time.Minute))
+	case d < 24*time.Hour:
+		return c.T("time.span.hours", "count", int64(d.Round(time.Hour)/time.Hour))
+	}
+	return c.T("time.span.days", "count", int64(d.Round(24*time.Hour)/(24*time.Hour)))
+}
+
+// Percent writes a percentage with one decimal, e.g. "62.5%"
+func (c *Catalog) Percent(value float64) string {
+	number := strconv.FormatFloat(value, 'f', 1, 64)
+	return c.T("number.percent", "value", strings.Replace(number, ".", c.T("number.decimal"), 1))
+}
+
+// timeFormat formats times in a user's timezone, language, date format
+// and clock. It resolves the timezone once, so a message builds one and
+// uses it for every time it shows.
+type timeFormat struct {
+	c    *Catalog
+	user *User
+	loc  *time.Location
+	now  time.Time
+}
+
+// userTimeFormat returns the time format of a loaded user; a nil user gets
+// the defaults
+func userTimeFormat(user *User) timeFormat {
+	loc := locationOf(user)
+	return timeFormat{c: userCatalog(user), user: user, loc: loc, now: time.Now().In(loc)}
+}
+
+// timeFormatFor looks up a user by Telegram ID and returns their time format
+func (b *Bot) timeFormatFor(telegramID int64) timeFormat {
+	user, _ := b.db.GetUserByTelegramID(telegramID)
+	return userTimeFormat(user)
+}
+
+// Time formats a date and time
+func (f timeFormat) Time(t time.Time) string {
+	return formatDateTime(f.c, f.user, t.In(f.loc), f.now)
+}
+
+// Date formats the date of a time, without the time of day
+func (f timeFormat) Date(t time.Time) string {
+	return formatDate(f.c, f.user, t.In(f.loc), f.now)
+}
+
+// When formats a time for confirmations, adding how far away it is, e.g.
+// "today 18:00 (in 3h)"
+func (f timeFormat) When(t time.Time) string {
+	return f.c.T("time.when",
+		"time", f.Time(t),
+		"relative", formatRelative(f.c, t.Sub(f.now)))
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUserTimeFormatDate(t *testing.T) {
+	if err := loadCatalogs(); err != nil {
+		t.Fatalf("loadCatalogs error: %v", err)
+	}
+	// 20:00 UTC on New Year's Day is already the 2nd in Bangkok
+	at := time.Date(2026, 1, 1, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		user *User
+		want string
+	}{
+		{&User{Timezone: "Asia/Bangkok", DateFormat: "iso"}, "2026-01-02"},
+		{&User{Timezone: "America/New_York", DateFormat: "iso"}, "2026-01-01"},
+		{&User{Timezone: "Local", DateFormat: "iso"}, "2026-01-02"},
+		{&User{Timezone: "Asia/Bangkok", DateFormat: "mdy", Language: "en"}, "01/02/2026"},
+	}
+	for _, tt := range tests {
+		if got := userTimeFormat(tt.user).Date(at); got != tt.want {
+			t.Errorf("Date for %s/%s = %q, want %q", tt.user.Timezone, tt.user.DateFormat, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+)
+
+// In groups and supergroups tasks belong to the chat rather than to a
+// person: todos.chat_id holds the group and user_id whoever added the task.
+// Anyone in the group can list, add, complete and assign them, and open,
+// comment on and watch them with /task, /comment and /watch. Group tasks
+// stay out of personal lists, digests and statistics, and get no reminders.
+
+// isGroupChat reports whether a chat is a group or supergroup
+func isGroupChat(chat *tgbotapi.Chat) bool {
+	return chat != nil && (chat.IsGroup() || chat.IsSuperGroup())
+}
+
+// addressedToOtherBot reports whether a command was written as
+// /command@otherbot, which group chats use to pick between several bots
+func (b *Bot) addressedToOtherBot(message *tgbotapi.Message) bool {
+	_, at, found := strings.Cut(message.CommandWithAt(), "@")
+	return found && !strings.EqualFold(at, b.api.Self.UserName)
+}
+
+// senderCatalog returns the catalog for the person who wrote a group
+// message, using their Telegram app language if they never started the bot
+func (b *Bot) senderCatalog(from *tgbotapi.User) *Catalog {
+	user, err := b.db.GetUserByTelegramID(from.ID)
+	if err != nil || user == nil {
+		return catalogFor(from.LanguageCode)
+	}
+	return userCatalog(user)
+}
+
+// groupMember returns the bot user behind a group message, registering
+// people who have not started the bot in a private chat yet
+func (b *Bot) groupMember(from *tgbotapi.User) (*User, error) {
+	user, err := b.db.GetUserByTelegramID(from.ID)
+	if err != nil || user != nil {
+		return user, err
+	}
+
+	language := defaultLanguage
+	if catalog := matchCatalog(from.LanguageCode); catalog != nil {
+		language = catalog.Language
+	}
+	return b.db.CreateUser(NewUser{
+		TelegramID: from.ID,
+		Name:       strings.TrimSpace(from.FirstName + " " + from.LastName),
+		Timezone:   defaultTimezone,
+		Language:   language,
+	})
+}
+
+// pendingChatTodos returns a group's open tasks, numbered in this order by
+// /list and /complete
+func (b *Bot) pendingChatTodos(chatID int64) ([]Todo, error) {
+	todos, err := b.db.GetChatTodos(chatID)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Todo
+	for _, todo := range todos {
+		if todo.Status == "pending" {
+			pending = append(pending, todo)
+		}
+	}
+	return pending, nil
+}
+
+// handleGroupMessage handles a message in a group chat. Only the shared
+// list commands work there; everything else points to the private chat.
+func (b *Bot) handleGroupMessage(message *tgbotapi.Message) error {
+	// Ordinary chatter is none of our business, except replies to task cards
+	if !message.IsCommand() {
+		if todoID, ok := b.repliedTaskID(message); ok && message.Text != "" {
+			return b.handleCommentReply(message, todoID)
+		}
+		return nil
+	}
+	if b.addressedToOtherBot(message) {
+		return nil
+	}
+
+	switch message.Command() {
+	case "add":
+		return b.handleGroupAdd(message)
+	case "list":
+		return b.handleGroupList(message)
+	case "complete":
+		return b.handleGroupComplete(message)
+	case "assign":
+		return b.handleGroupAssign(message)
+	case "task":
+		return b.handleTask(message)
+	case "comment":
+		return b.handleComment(message)
+	case "watch":
+		return b.handleWatch(message)
+	case "unwatch":
+		return b.handleUnwatch(message)
+	case "start", "help":
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.senderCatalog(message.From).T("group.help"))
+		msg.ParseMode = "HTML"
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	if _, exists := b.commands[message.Command()]; !exists {
+		return nil
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.senderCatalog(message.From).T("group.private_only", "bot", b.api.Self.UserName))
+	msg.ReplyToMessageID = message.MessageID
+	_, err := b.api.Send(msg)
+	return err
+}
+
+// handleGroupAdd adds a task to the group's list
+func (b *Bot) handleGroupAdd(message *tgbotapi.Message) error {
+	c := b.senderCatalog(message.From)
+	args := message.CommandArguments()
+	if args == "" {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("add.missing_title"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	user, err := b.groupMember(message.From)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	args, dueTime := splitDueSuffix(args, b.nowInUserTimezone(message.From.ID), b.userLocation(message.From.ID))
+	if dueTime != nil {
+		due := dueTime.UTC()
+		dueTime = &due
+	}
+	title, description := splitTitle(args)
+
+	chatID := message.Chat.ID
+	todo, err := b.db.CreateTodo(NewTodo{
+		UserID:      user.ID,
+		ChatID:      &chatID,
+		Title:       title,
+		Description: description,
+		DueTime:     dueTime,
+		Priority:    defaultPriority(user),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create todo: %w", err)
+	}
+
+	msgText := c.T("group.added", "name", html.EscapeString(message.From.FirstName), "title", html.EscapeString(todo.Title))
+	if todo.DueTime != nil {
+		msgText += "\n" + c.T("list.due", "time", userTimeFormat(user).Time(*todo.DueTime))
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
+	msg.ParseMode = "HTML"
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// groupListMessage renders a group's open tasks with complete buttons
+func (b *Bot) groupListMessage(chat *tgbotapi.Chat, c *Catalog, viewerID int64) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	todos, err := b.pendingChatTodos(chat.ID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to get todos: %w", err)
+	}
+
+	var text strings.Builder
+	text.WriteString(c.T("group.list_title", "chat", html.EscapeString(chat.Title)) + "\n\n")
+	if len(todos) == 0 {
+		text.WriteString(c.T("group.list_empty"))
+	}
+
+	names := map[uuid.UUID]string{}
+	tf := b.timeFormatFor(viewerID)
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	for i, todo := range todos {
+		text.WriteString(fmt.Sprintf("%d. <b>%s</b>", i+1, html.EscapeString(todo.Title)))
+		if todo.DueTime != nil {
+			text.WriteString(" 📅 " + tf.Time(*todo.DueTime))
+		}
+		text.WriteString("\n")
+		if todo.Description != nil {
+			text.WriteString("   " + html.EscapeString(*todo.Description) + "\n")
+		}
+		text.WriteString("   " + c.T("group.added_by", "name", html.EscapeString(b.creatorName(c, &todo, names))) + "\n")
+		if note := b.sharingNote(c, &todo, nil, names); note != "" {
+			text.WriteString("   " + note + "\n")
+		}
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("✅ %d", i+1), fmt.Sprintf("complete:%s", todo.ID)),
+		))
+	}
+
+	return text.String(), tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
+
+// handleGroupList shows the group's open tasks
+func (b *Bot) handleGroupList(message *tgbotapi.Message) error {
+	text, keyboard, err := b.groupListMessage(message.Chat, b.senderCatalog(message.From), message.From.ID)
+	if err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "HTML"
+	if len(keyboard.InlineKeyboard) > 0 {
+		msg.ReplyMarkup = keyboard
+	}
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleGroupComplete completes a group task by its number in /list
+func (b *Bot) handleGroupComplete(message *tgbotapi.Message) error {
+	c := b.senderCatalog(message.From)
+	args := strings.TrimSpace(message.CommandArguments())
+	if args == "" {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("complete.usage"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	taskNum, err := strconv.Atoi(args)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.invalid_number"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	todos, err := b.pendingChatTodos(message.Chat.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get todos: %w", err)
+	}
+	if taskNum < 1 || taskNum > len(todos) {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.not_found", "max", len(todos)))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	closed, err := b.completeGroupTodo(&todos[taskNum-1], message.From)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("complete.failed"))
+		_, err2 := b.api.Send(msg)
+		return err2
+	}
+	if !closed {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("complete.not_pending", "title", html.EscapeString(todos[taskNum-1].Title)))
+		msg.ParseMode = "HTML"
+		_, err := b.api.Send(msg)
+		return err
+	}
+	return b.announceGroupCompletion(message.Chat.ID, c, message.From, &todos[taskNum-1])
+}
+
+// handleGroupCallback handles the buttons of group task lists and /assign
+func (b *Bot) handleGroupCallback(callback *tgbotapi.CallbackQuery, action, todoIDStr, arg string) error {
+	c := b.senderCatalog(callback.From)
+	switch action {
+	case "asgto":
+		return b.handleGroupAssignCallback(callback, todoIDStr, arg)
+	case "task", "watch", "unwatch":
+		return b.handleTaskCallback(callback, action, todoIDStr, arg)
+	}
+	if action != "complete" {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+		})
+		return err
+	}
+
+	todo, err := b.chatTodo(callback.Message.Chat.ID, todoIDStr)
+	if err != nil || todo.Status != "pending" {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            c.T("task.not_found_short"),
+		})
+		return err
+	}
+
+	closed, err := b.completeGroupTodo(todo, callback.From)
+	if err != nil || !closed {
+		text := c.T("complete.failed")
+		if err == nil {
+			text = c.T("complete.not_pending_short")
+		}
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            text,
+		})
+		return err
+	}
+
+	// Refresh the list so the completed task drops off
+	text, keyboard, err := b.groupListMessage(callback.Message.Chat, c, callback.From.ID)
+	if err == nil {
+		edit := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID, text, keyboard)
+		edit.ParseMode = "HTML"
+		if _, err := b.api.Send(edit); err != nil {
+			log.Printf("Failed to refresh group list: %v", err)
+		}
+	}
+
+	if _, err := b.api.Request(tgbotapi.CallbackConfig{CallbackQueryID: callback.ID}); err != nil {
+		log.Printf("Failed to answer group callback: %v", err)
+	}
+	return b.announceGroupCompletion(callback.Message.Chat.ID, c, callback.From, todo)
+}
+
+// creatorName returns the name of whoever added a task, which for group
+// tasks may be someone who has since deleted their account
+func (b *Bot) creatorName(c *Catalog, todo *Todo, names map[uuid.UUID]string) string {
+	if todo.UserID == uuid.Nil {
+		return c.T("group.former_member")
+	}
+	return b.cachedUserName(todo.UserID, names)
+}
+
+// chatTodo gets a task by ID, checking it belongs to the group chat
+func (b *Bot) chatTodo(chatID int64, todoIDStr string) (*Todo, error) {
+	todoID, err := uuid.Parse(todoIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid todo ID: %w", err)
+	}
+	todo, err := b.db.GetTodoByID(todoID)
+	if err != nil {
+		return nil, err
+	}
+	if todo.ChatID == nil || *todo.ChatID != chatID {
+		return nil, fmt.Errorf("todo %s does not belong to chat %d", todo.ID, chatID)
+	}
+	return todo, nil
+}
+
+// groupTodo loads one of a group's tasks for the person who wrote a message
+// or pressed a button there. They are recorded as in the group, so they hear
+// about the activity of tasks they go on to watch.
+func (b *Bot) groupTodo(chatID int64, from *tgbotapi.User, todoIDStr string) (*Todo, *User, error) {
+	todo, err := b.chatTodo(chatID, todoIDStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := b.groupMember(from)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := b.db.TouchChatMember(chatID, from.ID); err != nil {
+		log.Printf("Failed to record member of chat %d: %v", chatID, err)
+	}
+	return todo, user, nil
+}
+
+// completeGroupTodo completes a group task on behalf of the member who
+// asked, so its activity names them. It reports false if someone else
+// closed the task first.
+func (b *Bot) completeGroupTodo(todo *Todo, from *tgbotapi.User) (bool, error) {
+	user, err := b.groupMember(from)
+	if err != nil {
+		return false, fmt.Errorf("failed to get user: %w", err)
+	}
+	closed, _, err := b.closeTodo(todo, user, "completed")
+	return closed != nil, err
+}
+
+// announceGroupCompletion tells the group who completed a task
+func (b *Bot) announceGroupCompletion(chatID int64, c *Catalog, from *tgbotapi.User, todo *Todo) error {
+	msg := tgbotapi.NewMessage(chatID, c.T("group.completed",
+		"name", html.EscapeString(from.FirstName),
+		"title", html.EscapeString(todo.Title)))
+	msg.ParseMode = "HTML"
+	_, err := b.api.Send(msg)
+	return err
+}
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// defaultLanguage is the catalog every other catalog is checked against
+// and finally falls back to
+const defaultLanguage = "en"
+
+// localeFiles holds one message catalog per language, named <code>.json
+//
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// catalogMetaKey is the catalog entry describing the language itself
+const catalogMetaKey = "_meta"
+
+// catalogMeta describes a catalog's language
+type catalogMeta struct {
+	// Name is the language's own name, e.g. "ไทย"
+	Name string `json:"name"`
+	// Flag is shown next to the name in the language picker
+	Flag string `json:"flag"`
+	// Plural names the plural rule, one of pluralRules
+	Plural string `json:"plural"`
+	// Fallback is the language to use for missing messages, defaulting to
+	// defaultLanguage
+	Fallback string `json:"fallback"`
+	// YearOffset is added to Gregorian years in written dates, e.g. 543
+	// for Buddhist-era years
+	YearOffset int `json:"year_offset"`
+}
+
+// pluralRule picks the plural form for a count
+type pluralRule struct {
+	// forms lists the forms every plural message must provide
+	forms []string
+	form  func(n int64) string
+}
+
+// pluralRules are the plural rules catalogs can choose from, following the
+// CLDR rules for integers
+var pluralRules = map[string]pluralRule{
+	// Languages without plural forms, e.g. Thai, Japanese, Chinese
+	"none": {
+		forms: []string{"other"},
+		form:  func(n int64) string { return "other" },
+	},
+	// One and many, e.g. English, German, Spanish
+	"one": {
+		forms: []string{"one", "other"},
+		form: func(n int64) string {
+			if n == 1 {
+				return "one"
+			}
+			return "other"
+		},
+	},
+	// Zero counts as one, e.g. French, Portuguese
+	"zero-one": {
+		forms: []string{"one", "other"},
+		form: func(n int64) string {
+			if n == 0 || n == 1 {
+				return "one"
+			}
+			return "other"
+		},
+	},
+	// East Slavic languages, e.g. Russian, Ukrainian
+	"slavic": {
+		forms: []string{"one", "few", "many"},
+		form: func(n int64) string {
+			switch {
+			case n%10 == 1 && n%100 != 11:
+				return "one"
+			case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+				return "few"
+			}
+			return "many"
+		},
+	},
+}
+
+// catalogMessage is one catalog entry. In the JSON files it is a string, a
+// list of lines, or an object of plural forms keyed by form name.
+type catalogMessage struct {
+	text  string
+	forms map[string]string
+}
+
+// UnmarshalJSON accepts the three entry shapes
+func (m *catalogMessage) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &m.text); err == nil {
+		return nil
+	}
+
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		m.text = strings.Join(lines, "\n")
+		return nil
+	}
+
+	var forms map[string]json.RawMessage
+	if err := json.Unmarshal(data, &forms); err != nil {
+		return fmt.Errorf("expected a string, a list of lines or plural forms")
+	}
+	m.forms = make(map[string]string, len(forms))
+	for form, raw := range forms {
+		var inner catalogMessage
+		if err := inner.UnmarshalJSON(raw); err != nil || inner.forms != nil {
+			return fmt.Errorf("plural form %s: expected a string or a list of lines", form)
+		}
+		m.forms[form] = inner.text
+	}
+	return nil
+}
+
+// Catalog holds the messages of one language
+type Catalog struct {
+	Language string
+	Name     string
+	Flag     string
+
+	yearOffset int
+	plural     pluralRule
+	messages   map[string]catalogMessage
+	fallback   *Catalog
+}
+
+// catalogs holds the loaded catalogs by language code
+var catalogs = map[string]*Catalog{}
+
+// languages lists the loaded catalogs, the default language first
+var languages []*Catalog
+
+// placeholderPattern matches named placeholders such as {title}
+var placeholderPattern = regexp.MustCompile(`\{[a-z_]+\}`)
+
+// loadCatalogs reads the embedded message catalogs and checks that every
+// language has each message of the default language, with all the plural
+// forms its rule needs and no unknown placeholders
+func loadCatalogs() error {
+	files, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		return fmt.Errorf("failed to read message catalogs: %w", err)
+	}
+
+	metas := map[string]catalogMeta{}
+	for _, file := range files {
+		language := strings.TrimSuffix(file.Name(), path.Ext(file.Name()))
+		data, err := localeFiles.ReadFile("locales/" + file.Name())
+		if err != nil {
+			return fmt.Errorf("failed to read message catalog %s: %w", file.Name(), err)
+		}
+
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("failed to parse message catalog %s: %w", file.Name(), err)
+		}
+
+		var meta catalogMeta
+		if err := json.Unmarshal(raw[catalogMetaKey], &meta); err != nil {
+			return fmt.Errorf("message catalog %s: invalid %s: %w", file.Name(), catalogMetaKey, err)
+		}
+		rule, ok := pluralRules[meta.Plural]
+		if !ok {
+			return fmt.Errorf("message catalog %s: unknown plural rule %q", file.Name(), meta.Plural)
+		}
+		delete(raw, catalogMetaKey)
+
+		catalog := &Catalog{
+			Language:   language,
+			Name:       meta.Name,
+			Flag:       meta.Flag,
+			yearOffset: meta.YearOffset,
+			plural:     rule,
+			messages:   make(map[string]catalogMessage, len(raw)),
+		}
+		for key, value := range raw {
+			var message catalogMessage
+			if err := json.Unmarshal(value, &message); err != nil {
+				return fmt.Errorf("message catalog %s: %s: %w", file.Name(), key, err)
+			}
+			catalog.messages[key] = message
+		}
+		catalogs[language] = catalog
+		metas[language] = meta
+	}
+
+	base, ok := catalogs[defaultLanguage]
+	if !ok {
+		return fmt.Errorf("message catalog %s.json is missing", defaultLanguage)
+	}
+
+	var problems []string
+	for language, catalog := range catalogs {
+		fallback := metas[language].Fallback
+		if fallback == "" {
+			fallback = defaultLanguage
+		}
+		if language != defaultLanguage {
+			if catalog.fallback = catalogs[fallback]; catalog.fallback == nil {
+				problems = append(problems, fmt.Sprintf("%s: unknown fallback language %s", language, fallback))
+			}
+		}
+		problems = append(problems, checkCatalog(catalog, base)...)
+		languages = append(languages, catalog)
+	}
+
+	sort.Slice(languages, func(i, j int) bool {
+		if (languages[i] == base) != (languages[j] == base) {
+			return languages[i] == base
+		}
+		return languages[i].Language < languages[j].Language
+	})
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("message catalogs are incomplete:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// checkCatalog compares a catalog with the default one
+func checkCatalog(catalog, base *Catalog) []string {
+	var problems []string
+	for key, want := range base.messages {
+		got, ok := catalog.messages[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: missing %s", catalog.Language, key))
+			continue
+		}
+
+		// Plural messages need every form of the language's rule; languages
+		// without plurals may use a plain string
+		if want.forms != nil && !(got.forms == nil && len(catalog.plural.forms) == 1) {
+			for _, form := range catalog.plural.forms {
+				if _, ok := got.forms[form]; !ok {
+					problems = append(problems, fmt.Sprintf("%s: %s is missing plural form %s", catalog.Language, key, form))
+				}
+			}
+		}
+
+		allowed := map[string]bool{}
+		for _, text := range want.variants() {
+			for _, placeholder := range placeholderPattern.FindAllString(text, -1) {
+				allowed[placeholder] = true
+			}
+		}
+		for _, text := range got.variants() {
+			for _, placeholder := range placeholderPattern.FindAllString(text, -1) {
+				if !allowed[placeholder] {
+					problems = append(problems, fmt.Sprintf("%s: %s uses unknown placeholder %s", catalog.Language, key, placeholder))
+				}
+			}
+		}
+	}
+
+	for key := range catalog.messages {
+		if _, ok := base.messages[key]; !ok {
+			problems = append(problems, fmt.Sprintf("%s: unknown message %s", catalog.Language, key))
+		}
+	}
+	return problems
+}
+
+// variants returns the message's text or all its plural forms
+func (m catalogMessage) variants() []string {
+	if m.forms == nil {
+		return []string{m.text}
+	}
+	texts := make([]string, 0, len(m.forms))
+	for _, text := range m.forms {
+		texts = append(texts, text)
+	}
+	return texts
+}
+
+// matchCatalog returns the catalog for a language code such as "th" or
+// "pt-BR", falling back to the base language, or nil if there is none
+func matchCatalog(language string) *Catalog {
+	language = strings.ToLower(strings.ReplaceAll(language, "_", "-"))
+	if catalog, ok := catalogs[language]; ok {
+		return catalog
+	}
+	if base, _, found := strings.Cut(language, "-"); found {
+		if catalog, ok := catalogs[base]; ok {
+			return catalog
+		}
+	}
+	return nil
+}
+
+// catalogFor returns the catalog for a language code, falling back to the
+// default language
+func catalogFor(language string) *Catalog {
+	if catalog := matchCatalog(language); catalog != nil {
+		return catalog
+	}
+	return catalogs[defaultLanguage]
+}
+
+// userCatalog returns the catalog for a user's language
+func userCatalog(user *User) *Catalog {
+	if user == nil {
+		return catalogFor(defaultLanguage)
+	}
+	return catalogFor(user.Language)
+}
+
+// T renders a message. Args are placeholder name and value pairs; a
+// "count" arg picks the plural form of plural messages.
+func (c *Catalog) T(key string, args ...interface{}) string {
+	message, catalog := c.lookup(key)
+	if catalog == nil {
+		return key
+	}
+
+	text := message.text
+	if message.forms != nil {
+		text = message.forms[catalog.plural.form(countArg(args))]
+		if text == "" {
+			text = message.forms["other"]
+		}
+	}
+
+	if len(args) == 0 {
+		return text
+	}
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, "{"+fmt.Sprint(args[i])+"}", fmt.Sprint(args[i+1]))
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// lookup finds a message along the fallback chain
+func (c *Catalog) lookup(key string) (catalogMessage, *Catalog) {
+	for catalog := c; catalog != nil; catalog = catalog.fallback {
+		if message, ok := catalog.messages[key]; ok {
+			return message, catalog
+		}
+	}
+	return catalogMessage{}, nil
+}
+
+// countArg returns the integer "count" arg, or 0
+func countArg(args []interface{}) int64 {
+	for i := 0; i+1 < len(args); i += 2 {
+		if args[i] != "count" {
+			continue
+		}
+		switch n := args[i+1].(type) {
+		case int:
+			return int64(n)
+		case int64:
+			return n
+		}
+	}
+	return 0
+}
+
+// localizedError is an error whose message comes from the catalogs, so it
+// can be shown to users in their own language
+type localizedError struct {
+	key  string
+	args []interface{}
+}
+
+// newLocalizedError creates an error rendered from a catalog message
+func newLocalizedError(key string, args ...interface{}) error {
+	return &localizedError{key: key, args: args}
+}
+
+// Error renders the error in the default language
+func (e *localizedError) Error() string {
+	return catalogFor(defaultLanguage).T(e.key, e.args...)
+}
+
+// Error renders an error for users, translating catalog errors
+func (c *Catalog) Error(err error) string {
+	var localized *localizedError
+	if errors.As(err, &localized) {
+		return c.T(localized.key, localized.args...)
+	}
+	return err.Error()
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestPluralRules(t *testing.T) {
+	tests := []struct {
+		rule string
+		n    int64
+		want string
+	}{
+		{"none", 1, "other"},
+		{"one", 0, "other"},
+		{"one", 1, "one"},
+		{"one", 2, "other"},
+		{"zero-one", 0, "one"},
+		{"zero-one", 1, "one"},
+		{"zero-one", 2, "other"},
+		{"slavic", 1, "one"},
+		{"slavic", 21, "one"},
+		{"slavic", 11, "many"},
+		{"slavic", 3, "few"},
+		{"slavic", 24, "few"},
+		{"slavic", 13, "many"},
+		{"slavic", 5, "many"},
+		{"slavic", 0, "many"},
+	}
+	for _, tt := range tests {
+		if got := pluralRules[tt.rule].form(tt.n); got != tt.want {
+			t.Errorf("%s rule for %d = %q, want %q", tt.rule, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestCatalogsLoad(t *testing.T) {
+	if err := loadCatalogs(); err != nil {
+		t.Fatalf("loadCatalogs error: %v", err)
+	}
+	if got := catalogFor("en").T("priority.high"); got == "priority.high" {
+		t.Error("English catalog is missing priority.high")
+	}
+}
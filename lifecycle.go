@@ -0,0 +1,421 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+)
+
+// closeTodo completes or cancels a todo and stops its reminders. Completing
+// a recurring todo also creates its next instance, which is returned. When
+// an assignee completes a task, its owner is told, and watchers hear about
+// it through the activity feed. A todo that is no longer pending is left
+// alone and nil is returned.
+func (b *Bot) closeTodo(todo *Todo, user *User, status string) (*Todo, *Todo, error) {
+	if todo.Status != "pending" {
+		return nil, nil, nil
+	}
+	updated, err := b.db.UpdateTodoStatus(todo.ID, status)
+	if err != nil || updated == nil {
+		return nil, nil, err
+	}
+
+	var told []uuid.UUID
+	if status == "completed" && isAssignee(updated, user) {
+		b.notifyOwner(updated, user, "assign.completed")
+		told = append(told, updated.UserID)
+	}
+	b.recordActivity(updated, user, status, nil, told...)
+
+	// The next instance follows the owner's timezone and reminder defaults
+	owner, err := b.todoOwner(updated, user)
+	if err != nil {
+		log.Printf("Failed to get owner of todo %s: %v", updated.ID, err)
+		owner = user
+	}
+
+	var next *Todo
+	if status == "completed" && updated.RepeatRule != nil {
+		next, err = b.createNextInstance(updated, owner)
+		if err != nil {
+			log.Printf("Failed to create next instance of todo %s: %v", updated.ID, err)
+		}
+	}
+
+	if _, err := b.db.DeactivateTodoReminders(updated.ID, status); err != nil {
+		log.Printf("Failed to stop reminders of todo %s: %v", updated.ID, err)
+	}
+
+	return updated, next, nil
+}
+
+// createNextInstance creates the next occurrence of a recurring todo and
+// carries its reminders forward to it
+func (b *Bot) createNextInstance(todo *Todo, user *User) (*Todo, error) {
+	cron, err := parseCron(*todo.RepeatRule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repeat rule: %w", err)
+	}
+
+	// Finishing early moves on from the due time, finishing late from now
+	after := time.Now()
+	if todo.DueTime != nil && todo.DueTime.After(after) {
+		after = *todo.DueTime
+	}
+	due, err := cron.Next(after, locationOf(user))
+	if err != nil {
+		return nil, err
+	}
+	due = due.UTC()
+
+	// A group task whose creator left passes to whoever completed it
+	ownerID := todo.UserID
+	if ownerID == uuid.Nil {
+		ownerID = user.ID
+	}
+
+	next, err := b.db.CreateTodo(NewTodo{
+		UserID:           ownerID,
+		ChatID:           todo.ChatID,
+		AssigneeID:       todo.AssigneeID,
+		AssignmentStatus: todo.AssignmentStatus,
+		Title:            todo.Title,
+		Description:      todo.Description,
+		DueTime:          &due,
+		Priority:         todo.Priority,
+		Tags:             todo.Tags,
+		RepeatRule:       todo.RepeatRule,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reminders, err := b.db.GetRemindersForTodo(todo.ID)
+	if err != nil {
+		return next, err
+	}
+
+	now := time.Now()
+	for _, reminder := range reminders {
+		if !reminder.IsActive || reminder.DueOffsetSeconds != nil {
+			continue
+		}
+
+		carried := NewReminder{
+			TodoID:                next.ID,
+			RepeatCount:           reminder.RepeatCount,
+			RepeatIntervalSeconds: reminder.RepeatIntervalSeconds,
+			RepeatUntil:           reminder.RepeatUntil,
+			NextNotifyTime:        reminder.NextNotifyTime,
+			Schedule:              reminder.Schedule,
+		}
+		// One-off reminders keep their distance from the due time;
+		// repeating ones simply carry on
+		if !reminder.IsRepeating() {
+			if todo.DueTime == nil {
+				continue
+			}
+			carried.NextNotifyTime = reminder.NextNotifyTime.Add(due.Sub(*todo.DueTime))
+			if !carried.NextNotifyTime.After(now) {
+				continue
+			}
+		}
+
+		if _, err := b.db.CreateReminder(carried); err != nil {
+			log.Printf("Failed to carry reminder %s forward: %v", reminder.ID, err)
+		}
+	}
+
+	if _, err := b.syncDueReminders(next, user); err != nil {
+		log.Printf("Failed to schedule due reminders for todo %s: %v", next.ID, err)
+	}
+
+	return next, nil
+}
+
+// stoppedReminders returns the reminders stopped when a todo was closed
+// that can still fire again. Automatic due reminders are left out as they
+// are rebuilt from the due time instead.
+func (b *Bot) stoppedReminders(todo *Todo, user *User) ([]missedReminder, error) {
+	reminders, err := b.db.GetRemindersForTodo(todo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var result []missedReminder
+	for _, reminder := range reminders {
+		if reminder.IsActive || reminder.DeactivatedReason == nil || reminder.DueOffsetSeconds != nil {
+			continue
+		}
+		switch *reminder.DeactivatedReason {
+		case "completed", "cancelled":
+		default:
+			continue
+		}
+
+		if reminder.NextNotifyTime.After(now) {
+			next := reminder.NextNotifyTime
+			result = append(result, missedReminder{dueReminder: dueReminder{Reminder: reminder, Todo: todo}, Next: &next})
+			continue
+		}
+		// Repeating reminders resume at their next future occurrence
+		item := b.catchUpReminder(dueReminder{Reminder: reminder, Todo: todo}, user, now)
+		if item.Next != nil {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// restoreReminders turns a reopened todo's stopped reminders back on and
+// reschedules its automatic due reminders, returning how many are active
+func (b *Bot) restoreReminders(todo *Todo, user *User) (int, error) {
+	stopped, err := b.stoppedReminders(todo, user)
+	if err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	for _, item := range stopped {
+		if err := b.db.ReactivateReminder(item.Reminder.ID, *item.Next, item.Reminder.RepeatCount); err != nil {
+			log.Printf("Failed to restore reminder %s: %v", item.Reminder.ID, err)
+			continue
+		}
+		restored++
+	}
+
+	scheduled, err := b.syncDueReminders(todo, user)
+	if err != nil {
+		log.Printf("Failed to schedule due reminders for todo %s: %v", todo.ID, err)
+	}
+
+	return restored + scheduled, nil
+}
+
+// handleReopen handles the /reopen command
+func (b *Bot) handleReopen(message *tgbotapi.Message) error {
+	c := b.catalog(message.From.ID)
+	taskNum, err := strconv.Atoi(strings.TrimSpace(message.CommandArguments()))
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("reopen.usage"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	todos, err := b.db.GetUserTodos(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get todos: %w", err)
+	}
+
+	if taskNum < 1 || taskNum > len(todos) {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.not_found", "max", len(todos)))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	todo := todos[taskNum-1]
+	if !b.canCompleteTodo(&todo, user) {
+		return b.sendNotAllowed(message.Chat.ID, c, &todo)
+	}
+
+	if todo.Status == "pending" {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("reopen.already_open", "title", html.EscapeString(todo.Title)))
+		msg.ParseMode = "HTML"
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	updatedTodo, err := b.db.UpdateTodoStatus(todo.ID, "pending")
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("reopen.failed"))
+		_, err2 := b.api.Send(msg)
+		return err2
+	}
+	if updatedTodo == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("reopen.already_open", "title", html.EscapeString(todo.Title)))
+		msg.ParseMode = "HTML"
+		_, err := b.api.Send(msg)
+		return err
+	}
+	b.recordActivity(updatedTodo, user, activityReopened, nil)
+
+	// Reminders belong to the owner, so only they are offered to restore them
+	restorable := 0
+	if ownsTodo(updatedTodo, user) {
+		stopped, err := b.stoppedReminders(updatedTodo, user)
+		if err != nil {
+			log.Printf("Failed to get stopped reminders of todo %s: %v", updatedTodo.ID, err)
+		}
+		restorable = len(stopped) + len(dueOffsets(user, updatedTodo.Priority))
+		if updatedTodo.DueTime == nil || !updatedTodo.DueTime.After(time.Now()) {
+			restorable = len(stopped)
+		}
+	}
+
+	msgText := c.T("reopen.done", "title", html.EscapeString(updatedTodo.Title))
+	msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
+	msg.ParseMode = "HTML"
+	if restorable > 0 {
+		msg.Text += "\n\n" + c.T("reopen.reminders_stopped")
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(c.T("reopen.restore"), fmt.Sprintf("restore:%s", updatedTodo.ID)),
+			),
+		)
+	}
+
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleRestoreCallback restores the reminders of a reopened task
+func (b *Bot) handleRestoreCallback(callback *tgbotapi.CallbackQuery, todoIDStr string) error {
+	c := b.catalog(callback.From.ID)
+	todo, user, err := b.ownTodo(callback.From.ID, todoIDStr)
+	if err != nil || todo.Status != "pending" {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            c.T("task.not_found_short"),
+		})
+		return err
+	}
+
+	restored, err := b.restoreReminders(todo, user)
+	if err != nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            c.T("reopen.restore_failed"),
+		})
+		return err
+	}
+
+	if err := b.finishReminderMessage(callback, todo.ID, c.T("reopen.restored_count", "count", restored)); err != nil {
+		log.Printf("Failed to update reopen message: %v", err)
+	}
+
+	_, err = b.api.Request(tgbotapi.CallbackConfig{
+		CallbackQueryID: callback.ID,
+		Text:            c.T("reopen.restored"),
+	})
+	return err
+}
+
+// handleRecur handles the /recur command
+func (b *Bot) handleRecur(message *tgbotapi.Message) error {
+	c := b.catalog(message.From.ID)
+	parts := strings.SplitN(strings.TrimSpace(message.CommandArguments()), " ", 2)
+	if len(parts) != 2 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("recur.usage"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	taskNum, err := strconv.Atoi(parts[0])
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.invalid_number"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	todos, err := b.db.GetUserTodos(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get todos: %w", err)
+	}
+
+	if taskNum < 1 || taskNum > len(todos) {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.not_found", "max", len(todos)))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	todo := todos[taskNum-1]
+	if !b.canEditTodo(&todo, user) {
+		return b.sendNotAllowed(message.Chat.ID, c, &todo)
+	}
+	owner, err := b.todoOwner(&todo, user)
+	if err != nil {
+		return err
+	}
+
+	var rule *string
+	if strings.ToLower(strings.TrimSpace(parts[1])) != "off" {
+		expr, err := parseSchedule(parts[1])
+		if err != nil {
+			msg := tgbotapi.NewMessage(message.Chat.ID, c.T("recur.invalid", "error", c.Error(err)))
+			_, err := b.api.Send(msg)
+			return err
+		}
+		rule = &expr
+	}
+
+	updatedTodo, err := b.db.UpdateTodoRepeatRule(todo.ID, rule)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("task.update_failed"))
+		_, err2 := b.api.Send(msg)
+		return err2
+	}
+
+	if rule == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("recur.off", "title", html.EscapeString(updatedTodo.Title)))
+		msg.ParseMode = "HTML"
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	// A recurring task without a due time starts at its next occurrence
+	if updatedTodo.DueTime == nil && updatedTodo.Status == "pending" {
+		if due, err := b.nextScheduledTime(*rule, owner); err == nil {
+			if withDue, err := b.db.UpdateTodoDueTime(updatedTodo.ID, &due); err == nil {
+				updatedTodo = withDue
+				b.recordActivity(updatedTodo, user, activityDue, stringPtr(due.UTC().Format(time.RFC3339)))
+				if _, err := b.syncDueReminders(updatedTodo, owner); err != nil {
+					log.Printf("Failed to schedule due reminders for todo %s: %v", updatedTodo.ID, err)
+				}
+			}
+		}
+	}
+
+	msgText := c.T("recur.set", "title", html.EscapeString(updatedTodo.Title), "schedule", describeSchedule(c, *rule))
+	if updatedTodo.DueTime != nil {
+		msgText += "\n\n" + c.T("list.due", "time", userTimeFormat(user).Time(*updatedTodo.DueTime))
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
+	msg.ParseMode = "HTML"
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// nextInstanceNote describes the next instance of a recurring todo for
+// completion messages
+func (b *Bot) nextInstanceNote(next *Todo, user *User) string {
+	if next == nil || next.DueTime == nil {
+		return ""
+	}
+	return "\n\n" + userCatalog(user).T("recur.next", "time", userTimeFormat(user).Time(*next.DueTime))
+}
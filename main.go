@@ -27,6 +27,11 @@ func main() {
 		log.Fatal("DATABASE_URL environment variable is required")
 	}
 
+	// Load message catalogs, refusing to start with incomplete translations
+	if err := loadCatalogs(); err != nil {
+		log.Fatalf("Failed to load message catalogs: %v", err)
+	}
+
 	log.Printf("Starting Telegram Todo Bot...")
 	log.Printf("Bot Token: %s", botToken[:10]+"...")
 	log.Printf("Database URL: %s", dbURL[:30]+"...")
@@ -15,6 +15,29 @@ type User struct {
 	Language                string     `json:"language"`
 	DefaultReminderInterval int        `json:"default_reminder_interval"`
 	NotificationStyle       string     `json:"notification_style"`
+	SnoozeOptions           string     `json:"snooze_options"`
+	QuietStart              *string    `json:"quiet_start,omitempty"`
+	QuietEnd                *string    `json:"quiet_end,omitempty"`
+	QuietBypassHigh         bool       `json:"quiet_bypass_high"`
+	DNDUntil                *time.Time `json:"dnd_until,omitempty"`
+	ReminderOffsets         *string    `json:"reminder_offsets,omitempty"`
+	HighPriorityOffsets     *string    `json:"high_priority_offsets,omitempty"`
+	DigestEnabled           bool       `json:"digest_enabled"`
+	DigestTime              string     `json:"digest_time"`
+	LastDigestAt            *time.Time `json:"last_digest_at,omitempty"`
+	BriefingTime            *string    `json:"briefing_time,omitempty"`
+	ReviewTime              *string    `json:"review_time,omitempty"`
+	LastBriefingAt          *time.Time `json:"last_briefing_at,omitempty"`
+	LastReviewAt            *time.Time `json:"last_review_at,omitempty"`
+	EscalateAfterMinutes    int        `json:"escalate_after_minutes"`
+	EscalateMax             int        `json:"escalate_max"`
+	EscalateContactChatID   *int64     `json:"escalate_contact_chat_id,omitempty"`
+	WeekStart               string     `json:"week_start"`
+	Clock24h                bool       `json:"clock_24h"`
+	DateFormat              string     `json:"date_format"`
+	DefaultPriority         string     `json:"default_priority"`
+	DefaultList             string     `json:"default_list"`
+	Username                *string    `json:"username,omitempty"`
 	CreatedAt               time.Time  `json:"created_at"`
 	UpdatedAt               time.Time  `json:"updated_at"`
 }
@@ -23,12 +46,20 @@ type User struct {
 type Todo struct {
 	ID          uuid.UUID  `json:"id"`
 	UserID      uuid.UUID  `json:"user_id"`
+	// ChatID is the group chat owning a shared task, nil for personal tasks
+	ChatID      *int64     `json:"chat_id,omitempty"`
+	// AssigneeID is who the owner asked to do the task, and
+	// AssignmentStatus whether they accepted yet
+	AssigneeID       *uuid.UUID `json:"assignee_id,omitempty"`
+	AssignmentStatus *string    `json:"assignment_status,omitempty"`
 	Title       string     `json:"title"`
 	Description *string    `json:"description,omitempty"`
 	DueTime     *time.Time `json:"due_time,omitempty"`
 	Priority    string     `json:"priority"`
 	Status      string     `json:"status"`
 	Tags        *string    `json:"tags,omitempty"`
+	RepeatRule  *string    `json:"repeat_rule,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 }
@@ -38,14 +69,58 @@ type Reminder struct {
 	ID                     uuid.UUID  `json:"id"`
 	TodoID                 uuid.UUID  `json:"todo_id"`
 	RepeatCount            int        `json:"repeat_count"`
-	RepeatIntervalHours    int        `json:"repeat_interval_hours"`
+	RepeatIntervalSeconds  int64      `json:"repeat_interval_seconds"`
+	RepeatUntil            *time.Time `json:"repeat_until,omitempty"`
 	NextNotifyTime         time.Time  `json:"next_notify_time"`
 	SnoozedUntil          *time.Time `json:"snoozed_until,omitempty"`
 	IsActive               bool       `json:"is_active"`
+	IsPaused               bool       `json:"is_paused"`
+	Schedule               *string    `json:"schedule,omitempty"`
+	DeferredUntil          *time.Time `json:"deferred_until,omitempty"`
+	DueOffsetSeconds       *int64     `json:"due_offset_seconds,omitempty"`
+	DeactivatedReason      *string    `json:"deactivated_reason,omitempty"`
 	CreatedAt              time.Time  `json:"created_at"`
 	UpdatedAt              time.Time  `json:"updated_at"`
 }
 
+// DueAt returns when the current occurrence is due, taking a snooze into account
+func (r Reminder) DueAt() time.Time {
+	if r.SnoozedUntil != nil {
+		return *r.SnoozedUntil
+	}
+	return r.NextNotifyTime
+}
+
+// RepeatInterval returns the fixed repeat interval, or 0 for one-shot and scheduled reminders
+func (r Reminder) RepeatInterval() time.Duration {
+	return time.Duration(r.RepeatIntervalSeconds) * time.Second
+}
+
+// FiresAt returns when the reminder fires, after any quiet hours deferral
+func (r Reminder) FiresAt() time.Time {
+	if r.DeferredUntil != nil && r.DeferredUntil.After(r.DueAt()) {
+		return *r.DeferredUntil
+	}
+	return r.DueAt()
+}
+
+// IsRepeating reports whether the reminder fires more than once
+func (r Reminder) IsRepeating() bool {
+	return r.Schedule != nil || r.RepeatIntervalSeconds > 0
+}
+
+// ReminderWithTodo is a reminder together with the todo it belongs to
+type ReminderWithTodo struct {
+	Reminder Reminder
+	Todo     Todo
+}
+
+// ReminderFire is when a reminder next fires
+type ReminderFire struct {
+	ReminderID uuid.UUID
+	At         time.Time
+}
+
 // TodoStats represents statistics for todos
 type TodoStats struct {
 	Total         int `json:"total"`
@@ -59,26 +134,133 @@ type TodoStats struct {
 
 // NewUser represents a new user to be created
 type NewUser struct {
-	TelegramID int64  `json:"telegram_id"`
-	Name       string `json:"name"`
-	Timezone   string `json:"timezone"`
-	Language   string `json:"language"`
+	TelegramID    int64  `json:"telegram_id"`
+	Name          string `json:"name"`
+	Timezone      string `json:"timezone"`
+	Language      string `json:"language"`
+	DigestEnabled bool   `json:"digest_enabled"`
+}
+
+// UserSettings holds changes to a user's settings. Nil fields are left
+// unchanged; an empty quiet, briefing or review time clears it.
+type UserSettings struct {
+	Timezone                *string
+	Language                *string
+	DefaultReminderInterval *int
+	NotificationStyle       *string
+	QuietStart              *string
+	QuietEnd                *string
+	DigestEnabled           *bool
+	DigestTime              *string
+	BriefingTime            *string
+	ReviewTime              *string
+	WeekStart               *string
+	Clock24h                *bool
+	DateFormat              *string
+	DefaultPriority         *string
+	DefaultList             *string
 }
 
 // NewTodo represents a new todo to be created
 type NewTodo struct {
 	UserID      uuid.UUID  `json:"user_id"`
+	ChatID      *int64     `json:"chat_id,omitempty"`
+	AssigneeID       *uuid.UUID `json:"assignee_id,omitempty"`
+	AssignmentStatus *string    `json:"assignment_status,omitempty"`
 	Title       string     `json:"title"`
 	Description *string    `json:"description,omitempty"`
 	DueTime     *time.Time `json:"due_time,omitempty"`
 	Priority    string     `json:"priority"`
 	Tags        *string    `json:"tags,omitempty"`
+	RepeatRule  *string    `json:"repeat_rule,omitempty"`
 }
 
 // NewReminder represents a new reminder to be created
 type NewReminder struct {
 	TodoID                 uuid.UUID `json:"todo_id"`
-	RepeatCount            int       `json:"repeat_count"`
-	RepeatIntervalHours    int       `json:"repeat_interval_hours"`
-	NextNotifyTime         time.Time `json:"next_notify_time"`
+	RepeatCount            int        `json:"repeat_count"`
+	RepeatIntervalSeconds  int64      `json:"repeat_interval_seconds"`
+	RepeatUntil            *time.Time `json:"repeat_until,omitempty"`
+	NextNotifyTime         time.Time  `json:"next_notify_time"`
+	Schedule               *string    `json:"schedule,omitempty"`
+	DueOffsetSeconds       *int64     `json:"due_offset_seconds,omitempty"`
+}
+
+// PendingEscalation is a sent, unanswered reminder that is due its next escalation step
+type PendingEscalation struct {
+	DeliveryID      uuid.UUID
+	EscalationLevel int
+	Reminder        Reminder
+	Todo            Todo
+	User            User
+}
+
+// NewReminderDelivery represents a reminder delivery attempt to be recorded
+type NewReminderDelivery struct {
+	ReminderID        uuid.UUID `json:"reminder_id"`
+	OccurrenceTime    time.Time `json:"occurrence_time"`
+	Status            string    `json:"status"`
+	Error             *string   `json:"error,omitempty"`
+	InstanceID        string    `json:"instance_id"`
+	TelegramMessageID *int      `json:"telegram_message_id,omitempty"`
+	// SkippedOccurrences counts the occurrences missed before this one
+	// and not sent separately, e.g. while the bot was offline
+	SkippedOccurrences int `json:"skipped_occurrences"`
+}
+
+// NewAuditEntry records an action on a user's personal data. Entries are
+// kept after the account is deleted, identified by the Telegram ID.
+type NewAuditEntry struct {
+	UserID     uuid.UUID `json:"user_id"`
+	TelegramID int64     `json:"telegram_id"`
+	Action     string    `json:"action"`
+	Details    string    `json:"details"`
+}
+
+// ListInvite is a link that lets people join a user's list
+type ListInvite struct {
+	ID        uuid.UUID  `json:"id"`
+	OwnerID   uuid.UUID  `json:"owner_id"`
+	Token     string     `json:"token"`
+	Role      string     `json:"role"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NewListInvite represents the data needed to create an invite link
+type NewListInvite struct {
+	OwnerID   uuid.UUID  `json:"owner_id"`
+	Token     string     `json:"token"`
+	Role      string     `json:"role"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// ListMember is one side of a shared list with the role of its member. User
+// is the member when listing an owner's members, and the owner when listing
+// the lists a member joined.
+type ListMember struct {
+	User     User      `json:"user"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// TaskActivity is an entry in a task's activity feed: a comment or a change
+// someone made. Body holds the comment, the new due time in RFC 3339 or the
+// new assignee's ID, depending on the kind.
+type TaskActivity struct {
+	ID        uuid.UUID `json:"id"`
+	TodoID    uuid.UUID `json:"todo_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Kind      string    `json:"kind"`
+	Body      *string   `json:"body,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewTaskActivity represents the data needed to record task activity
+type NewTaskActivity struct {
+	TodoID uuid.UUID `json:"todo_id"`
+	UserID uuid.UUID `json:"user_id"`
+	Kind   string    `json:"kind"`
+	Body   *string   `json:"body,omitempty"`
 }
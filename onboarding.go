@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// New users are walked through language, timezone and notification style
+// in a single welcome message that is edited in place. Its buttons use
+// onb:<step>[:<value>] callbacks.
+
+// onboardingSkipRow returns the button leaving onboarding for the main menu
+func onboardingSkipRow(c *Catalog) []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(c.T("onboarding.skip"), "onb:skip"),
+	)
+}
+
+// onboardingLanguageStep asks the user to confirm the detected language
+func onboardingLanguageStep(user *User) (string, tgbotapi.InlineKeyboardMarkup) {
+	c := userCatalog(user)
+	text := c.T("onboarding.welcome",
+		"name", html.EscapeString(strings.TrimSpace(user.Name)),
+		"language", c.Flag+" "+c.Name)
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(languages); i += 2 {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, language := range languages[i:min(i+2, len(languages))] {
+			label := language.Flag + " " + language.Name
+			if language == c {
+				label = "✓ " + label
+			}
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, "onb:lang:"+language.Language))
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(c.T("onboarding.continue"), "onb:tz")),
+		onboardingSkipRow(c),
+	)
+	return text, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// onboardingTimezoneStep asks the user to keep or change their timezone
+func (b *Bot) onboardingTimezoneStep(user *User) (string, tgbotapi.InlineKeyboardMarkup) {
+	c := userCatalog(user)
+	zone := b.getUserTimezone(user.TelegramID)
+	text := c.T("onboarding.timezone",
+		"zone", html.EscapeString(zone),
+		"offset", zoneOffset(zone, time.Now()),
+		"time", formatClock(c, user, b.nowInUserTimezone(user.TelegramID)))
+
+	rows := timezoneRegionRows("onb:region")
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(c.T("timezone.use_location"), "tz:location")),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(c.T("onboarding.keep_timezone", "zone", zone), "onb:style")),
+		onboardingSkipRow(c),
+	)
+	return text, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// onboardingStyleStep asks the user how notifications should look
+func onboardingStyleStep(user *User) (string, tgbotapi.InlineKeyboardMarkup) {
+	c := userCatalog(user)
+	current := notificationStyle(user)
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, style := range notificationStyles {
+		label := styleName(c, style)
+		if style == current {
+			label = "✓ " + label
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, "onb:style:"+style),
+		))
+	}
+	rows = append(rows, onboardingSkipRow(c))
+	return c.T("onboarding.style"), tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// startOnboarding sends the welcome message for a new user
+func (b *Bot) startOnboarding(chatID int64, user *User) error {
+	text, keyboard := onboardingLanguageStep(user)
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = keyboard
+	_, err := b.api.Send(msg)
+	return err
+}
+
+// handleOnboardingCallback moves through the onboarding steps
+func (b *Bot) handleOnboardingCallback(callback *tgbotapi.CallbackQuery, step, value string) error {
+	user, err := b.db.GetUserByTelegramID(callback.From.ID)
+	if err != nil || user == nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+		})
+		return err
+	}
+	c := userCatalog(user)
+
+	var text string
+	var keyboard tgbotapi.InlineKeyboardMarkup
+	switch step {
+	case "lang":
+		if _, ok := catalogs[value]; ok && value != user.Language {
+			if err := b.db.UpdateUserLanguage(user.ID, value); err != nil {
+				return fmt.Errorf("failed to update language: %w", err)
+			}
+			user.Language = value
+		}
+		text, keyboard = onboardingLanguageStep(user)
+
+	case "tz":
+		text, keyboard = b.onboardingTimezoneStep(user)
+
+	case "region":
+		rows := timezoneButtonRows(regionCities(value, time.Now()), time.Now(), "onb:zone")
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("timezone.regions"), "onb:tz"),
+		))
+		text = c.T("timezone.region", "region", html.EscapeString(value))
+		keyboard = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+
+	case "zone":
+		if err := b.setUserTimezone(user, value); err != nil {
+			_, err := b.api.Request(tgbotapi.CallbackConfig{
+				CallbackQueryID: callback.ID,
+				Text:            c.T("timezone.unknown"),
+			})
+			return err
+		}
+		text, keyboard = onboardingStyleStep(user)
+
+	case "style":
+		if notificationStyle(&User{NotificationStyle: value}) != value {
+			text, keyboard = onboardingStyleStep(user)
+			break
+		}
+		if err := b.db.UpdateUserNotificationStyle(user.ID, value); err != nil {
+			return fmt.Errorf("failed to update notification style: %w", err)
+		}
+		user.NotificationStyle = value
+		return b.finishOnboarding(callback, user, true)
+
+	case "skip":
+		return b.finishOnboarding(callback, user, false)
+	}
+
+	if text != "" {
+		edit := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID, text, keyboard)
+		edit.ParseMode = "HTML"
+		if _, err := b.api.Send(edit); err != nil {
+			log.Printf("Failed to show onboarding step %s: %v", step, err)
+		}
+	}
+
+	_, err = b.api.Request(tgbotapi.CallbackConfig{
+		CallbackQueryID: callback.ID,
+	})
+	return err
+}
+
+// finishOnboarding closes the welcome message and opens the main menu. After
+// a completed onboarding it first shows how a reminder for a sample task
+// looks in the chosen style.
+func (b *Bot) finishOnboarding(callback *tgbotapi.CallbackQuery, user *User, completed bool) error {
+	c := userCatalog(user)
+
+	text := c.T("start.timezone_hint", "zone", html.EscapeString(b.getUserTimezone(user.TelegramID)))
+	if completed {
+		text = c.T("onboarding.done")
+	}
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = "HTML"
+	if _, err := b.api.Send(edit); err != nil {
+		log.Printf("Failed to close onboarding: %v", err)
+	}
+
+	if completed {
+		description := c.T("onboarding.sample_description")
+		due := time.Now().Add(time.Hour).Truncate(time.Hour)
+		sample := b.reminderMessage(user, dueReminder{Todo: &Todo{
+			Title:       c.T("onboarding.sample_title"),
+			Description: &description,
+			DueTime:     &due,
+		}})
+		// The sample is not a stored task, so it gets no action buttons
+		sample.ReplyMarkup = nil
+		if _, err := b.api.Send(sample); err != nil {
+			log.Printf("Failed to send sample reminder: %v", err)
+		}
+	}
+
+	if _, err := b.api.Request(tgbotapi.CallbackConfig{CallbackQueryID: callback.ID}); err != nil {
+		log.Printf("Failed to answer onboarding callback: %v", err)
+	}
+
+	msg, err := b.mainMenuMessage(callback.Message.Chat.ID, user)
+	if err != nil {
+		return err
+	}
+	_, err = b.api.Send(msg)
+	return err
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Audit log actions
+const (
+	auditExport        = "export"
+	auditDeleteAccount = "delete_account"
+)
+
+// exportArchive packs the exported tables into a ZIP with one indented
+// JSON file per table, and describes the row counts for the audit log
+func exportArchive(data map[string][]byte) ([]byte, string, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	var counts []string
+	for _, table := range exportTables {
+		var rows []json.RawMessage
+		if err := json.Unmarshal(data[table], &rows); err != nil {
+			return nil, "", fmt.Errorf("failed to read exported %s: %w", table, err)
+		}
+		counts = append(counts, fmt.Sprintf("%s=%d", table, len(rows)))
+
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, data[table], "", "  "); err != nil {
+			return nil, "", fmt.Errorf("failed to format exported %s: %w", table, err)
+		}
+		file, err := archive.Create(table + ".json")
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to add %s to archive: %w", table, err)
+		}
+		if _, err := file.Write(pretty.Bytes()); err != nil {
+			return nil, "", fmt.Errorf("failed to write %s to archive: %w", table, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return buf.Bytes(), strings.Join(counts, " "), nil
+}
+
+// handleExportMe handles the /exportme command
+func (b *Bot) handleExportMe(message *tgbotapi.Message) error {
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	c := userCatalog(user)
+
+	data, err := b.db.ExportUserData(user.ID)
+	if err != nil {
+		log.Printf("Failed to export data of user %s: %v", user.ID, err)
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("exportme.failed"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	archive, counts, err := exportArchive(data)
+	if err != nil {
+		return err
+	}
+
+	// Record the export before handing the data out
+	if err := b.db.RecordAudit(NewAuditEntry{
+		UserID:     user.ID,
+		TelegramID: user.TelegramID,
+		Action:     auditExport,
+		Details:    counts,
+	}); err != nil {
+		log.Printf("Failed to audit export of user %s: %v", user.ID, err)
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("exportme.failed"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	name := fmt.Sprintf("todo-export-%s.zip", b.nowInUserTimezone(user.TelegramID).Format("2006-01-02"))
+	document := tgbotapi.NewDocument(message.Chat.ID, tgbotapi.FileBytes{Name: name, Bytes: archive})
+	document.Caption = c.T("exportme.caption")
+	_, err = b.api.Send(document)
+	return err
+}
+
+// handleDeleteMe handles the /deleteme command by asking for a typed
+// confirmation
+func (b *Bot) handleDeleteMe(message *tgbotapi.Message) error {
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	c := userCatalog(user)
+
+	stats, err := b.db.GetTodoStats(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get todo stats: %w", err)
+	}
+
+	b.setPending(message.From.ID, pendingInput{Kind: pendingDeleteAccount})
+	msg := tgbotapi.NewMessage(message.Chat.ID, c.T("deleteme.confirm",
+		"count", stats.Total,
+		"phrase", c.T("deleteme.phrase")))
+	msg.ParseMode = "HTML"
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleDeleteAccountInput deletes the account if the user typed the
+// confirmation phrase, and cancels otherwise
+func (b *Bot) handleDeleteAccountInput(message *tgbotapi.Message) error {
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil || user == nil {
+		return err
+	}
+	c := userCatalog(user)
+
+	if !strings.EqualFold(strings.TrimSpace(message.Text), c.T("deleteme.phrase")) {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("deleteme.cancelled"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	// Reminder deletions are not announced over LISTEN/NOTIFY, so drop them
+	// from this instance's schedule; other instances find nothing to claim
+	reminders, err := b.db.GetUserReminders(user.ID)
+	if err != nil {
+		log.Printf("Failed to get reminders of user %s: %v", user.ID, err)
+	}
+
+	stats, err := b.db.GetTodoStats(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get todo stats: %w", err)
+	}
+	err = b.db.DeleteUserAccount(user.ID, NewAuditEntry{
+		UserID:     user.ID,
+		TelegramID: user.TelegramID,
+		Action:     auditDeleteAccount,
+		Details:    fmt.Sprintf("todos=%d reminders=%d", stats.Total, len(reminders)),
+	})
+	if err != nil {
+		log.Printf("Failed to delete user %s: %v", user.ID, err)
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("deleteme.failed"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	for _, item := range reminders {
+		b.scheduler.unschedule(item.Reminder.ID)
+	}
+	log.Printf("Deleted user %s at their request", user.ID)
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, c.T("deleteme.done"))
+	msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+	_, err = b.api.Send(msg)
+	return err
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// quietUntil reports whether reminders for the user should be held back
+// right now and, if so, until when. isDND is set when the hold comes from a
+// temporary /dnd rather than the daily quiet hours.
+func (b *Bot) quietUntil(user *User, now time.Time) (until time.Time, isDND bool, quiet bool) {
+	if user.DNDUntil != nil && now.Before(*user.DNDUntil) {
+		return *user.DNDUntil, true, true
+	}
+
+	if user.QuietStart == nil || user.QuietEnd == nil {
+		return time.Time{}, false, false
+	}
+
+	end, ok := quietWindowEnd(*user.QuietStart, *user.QuietEnd, now.In(locationOf(user)))
+	return end, false, ok
+}
+
+// quietWindowEnd returns the end of the daily quiet window containing now,
+// if any. Windows may wrap past midnight, e.g. 22:00-07:00.
+func quietWindowEnd(start, end string, now time.Time) (time.Time, bool) {
+	startHour, startMinute, err := parseClock(start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	endHour, endMinute, err := parseClock(end)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	startOfDay := startHour*60 + startMinute
+	endOfDay := endHour*60 + endMinute
+	current := now.Hour()*60 + now.Minute()
+
+	endToday := time.Date(now.Year(), now.Month(), now.Day(), endHour, endMinute, 0, 0, now.Location())
+	switch {
+	case startOfDay == endOfDay:
+		return time.Time{}, false
+	case startOfDay < endOfDay:
+		if current >= startOfDay && current < endOfDay {
+			return endToday, true
+		}
+	default:
+		if current >= startOfDay {
+			return endToday.AddDate(0, 0, 1), true
+		}
+		if current < endOfDay {
+			return endToday, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// handleQuiet handles the /quiet command
+func (b *Bot) handleQuiet(message *tgbotapi.Message) error {
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	c := userCatalog(user)
+
+	args := strings.Fields(strings.ToLower(message.CommandArguments()))
+	switch {
+	case len(args) == 0:
+		status := c.T("quiet.off")
+		if user.QuietStart != nil && user.QuietEnd != nil {
+			status = *user.QuietStart + "–" + *user.QuietEnd
+		}
+		bypass := c.T("quiet.off")
+		if user.QuietBypassHigh {
+			bypass = c.T("quiet.on")
+		}
+		msgText := c.T("quiet.help", "status", status, "bypass", bypass)
+		msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
+		msg.ParseMode = "HTML"
+		_, err := b.api.Send(msg)
+		return err
+
+	case len(args) == 1 && args[0] == "off":
+		if err := b.db.UpdateUserQuietHours(user.ID, nil, nil); err != nil {
+			return fmt.Errorf("failed to clear quiet hours: %w", err)
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("quiet.turned_off"))
+		_, err := b.api.Send(msg)
+		return err
+
+	case len(args) == 2 && args[0] == "bypass" && (args[1] == "on" || args[1] == "off"):
+		if err := b.db.UpdateUserQuietBypass(user.ID, args[1] == "on"); err != nil {
+			return fmt.Errorf("failed to update quiet bypass: %w", err)
+		}
+		msgText := c.T("quiet.bypass_on")
+		if args[1] == "off" {
+			msgText = c.T("quiet.bypass_off")
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	// Accept "22:00-07:00" as well as "22:00 07:00"
+	bounds := strings.FieldsFunc(strings.Join(args, " "), func(r rune) bool {
+		return r == '-' || r == '–' || r == ' '
+	})
+	if len(bounds) != 2 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("quiet.usage"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	var normalized [2]string
+	for i, bound := range bounds {
+		hour, minute, err := parseClock(bound)
+		if err != nil {
+			msg := tgbotapi.NewMessage(message.Chat.ID, c.T("quiet.invalid_time", "input", bound))
+			_, err := b.api.Send(msg)
+			return err
+		}
+		normalized[i] = fmt.Sprintf("%02d:%02d", hour, minute)
+	}
+	if normalized[0] == normalized[1] {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("quiet.same_times"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	if err := b.db.UpdateUserQuietHours(user.ID, &normalized[0], &normalized[1]); err != nil {
+		return fmt.Errorf("failed to update quiet hours: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, c.T("quiet.set", "start", normalized[0], "end", normalized[1], "zone", b.getUserTimezone(message.From.ID)))
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleDND handles the /dnd command
+func (b *Bot) handleDND(message *tgbotapi.Message) error {
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	c := userCatalog(user)
+
+	args := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	switch args {
+	case "":
+		msgText := c.T("dnd.status_off")
+		if user.DNDUntil != nil && time.Now().Before(*user.DNDUntil) {
+			msgText = c.T("dnd.status_on", "time", userTimeFormat(user).Time(*user.DNDUntil))
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
+		_, err := b.api.Send(msg)
+		return err
+
+	case "off":
+		if err := b.db.UpdateUserDND(user.ID, nil); err != nil {
+			return fmt.Errorf("failed to clear do-not-disturb: %w", err)
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("dnd.turned_off"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	duration, err := parseDuration(args)
+	if err != nil || duration <= 0 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("dnd.invalid"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	until := time.Now().Add(duration)
+	if err := b.db.UpdateUserDND(user.ID, &until); err != nil {
+		return fmt.Errorf("failed to set do-not-disturb: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, c.T("dnd.set", "time", userTimeFormat(user).When(until)))
+	_, err = b.api.Send(msg)
+	return err
+}
@@ -0,0 +1,506 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+)
+
+// Kinds of prompts that wait for a free-text answer
+const (
+	pendingReschedule = "reschedule"
+	// pendingDeleteAccount waits for the typed confirmation of /deleteme
+	pendingDeleteAccount = "delete_account"
+	// pendingSharedAdd waits for a task to add to another user's shared list
+	pendingSharedAdd = "shared_add"
+)
+
+// pendingInput records that the next text message from a user answers a prompt
+type pendingInput struct {
+	Kind       string
+	ReminderID uuid.UUID
+	OwnerID    uuid.UUID
+}
+
+// setPending remembers that the user's next text message answers a prompt
+func (b *Bot) setPending(userID int64, input pendingInput) {
+	b.pendingMu.Lock()
+	defer b.pendingMu.Unlock()
+	b.pending[userID] = input
+}
+
+// takePending returns and clears the prompt the user is answering, if any
+func (b *Bot) takePending(userID int64) (pendingInput, bool) {
+	b.pendingMu.Lock()
+	defer b.pendingMu.Unlock()
+	input, ok := b.pending[userID]
+	delete(b.pending, userID)
+	return input, ok
+}
+
+// defaultSnoozeOptions is used when a user has not configured their own
+const defaultSnoozeOptions = "10m,1h,tonight,tomorrow"
+
+// maxSnoozeOptions keeps the snooze row readable on a phone
+const maxSnoozeOptions = 4
+
+// maxCallbackData is Telegram's limit on a button's callback data in bytes
+const maxCallbackData = 64
+
+// snoozeCallbackData encodes the snooze button of a reminder
+func snoozeCallbackData(reminderID uuid.UUID, option string) string {
+	return fmt.Sprintf("snooze:%s:%s", reminderID, option)
+}
+
+// maxSnoozeOptionLength is the longest snooze option whose button still
+// fits in maxCallbackData
+var maxSnoozeOptionLength = maxCallbackData - len(snoozeCallbackData(uuid.Nil, ""))
+
+// snoozeOptions returns the snooze choices offered on the user's reminders
+func snoozeOptions(user *User) []string {
+	options := user.SnoozeOptions
+	if options == "" {
+		options = defaultSnoozeOptions
+	}
+	return strings.Split(options, ",")
+}
+
+// snoozeLabel renders a snooze option as a button label
+func snoozeLabel(c *Catalog, option string) string {
+	switch option {
+	case "tonight", "tomorrow":
+		return c.T("snooze." + option)
+	}
+	return "😴 " + option
+}
+
+// reminderKeyboard builds the action buttons attached to a reminder notification
+func (b *Bot) reminderKeyboard(reminder Reminder, user *User) tgbotapi.InlineKeyboardMarkup {
+	c := userCatalog(user)
+	var snoozeRow []tgbotapi.InlineKeyboardButton
+	for _, option := range snoozeOptions(user) {
+		snoozeRow = append(snoozeRow, tgbotapi.NewInlineKeyboardButtonData(
+			snoozeLabel(c, option), snoozeCallbackData(reminder.ID, option)))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("reminder.button_done"), fmt.Sprintf("rdone:%s", reminder.ID)),
+		),
+		snoozeRow,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("reminder.button_reschedule"), fmt.Sprintf("resched:%s", reminder.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("reminder.button_stop"), fmt.Sprintf("rstop:%s", reminder.ID)),
+		),
+	)
+}
+
+// ownReminder loads a reminder and its todo, returning nil if either is
+// missing or the todo does not belong to the Telegram user
+func (b *Bot) ownReminder(telegramID int64, reminderIDStr string) (*Reminder, *Todo, error) {
+	reminderID, err := uuid.Parse(reminderIDStr)
+	if err != nil {
+		return nil, nil, nil
+	}
+
+	reminder, err := b.db.GetReminderByID(reminderID)
+	if err != nil || reminder == nil {
+		return nil, nil, err
+	}
+
+	todo, err := b.db.GetTodoByID(reminder.TodoID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := b.db.GetUserByTelegramID(telegramID)
+	if err != nil || user == nil || user.ID != todo.UserID {
+		return nil, nil, err
+	}
+
+	return reminder, todo, nil
+}
+
+// finishReminderMessage removes one item's buttons from the notification a
+// callback came from and appends a status line. The item is a reminder, or a
+// task in a digest; bundled notifications keep the buttons of other items.
+func (b *Bot) finishReminderMessage(callback *tgbotapi.CallbackQuery, itemID uuid.UUID, status string) error {
+	if callback.Message == nil {
+		return nil
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	if callback.Message.ReplyMarkup != nil {
+		for _, row := range callback.Message.ReplyMarkup.InlineKeyboard {
+			var kept []tgbotapi.InlineKeyboardButton
+			for _, button := range row {
+				if button.CallbackData == nil || !strings.Contains(*button.CallbackData, itemID.String()) {
+					kept = append(kept, button)
+				}
+			}
+			if len(kept) > 0 {
+				rows = append(rows, kept)
+			}
+		}
+	}
+
+	// Text comes without markup; its entities keep the formatting, and the
+	// appended status doesn't move their offsets
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID,
+		callback.Message.Text+"\n"+status)
+	edit.Entities = callback.Message.Entities
+	edit.ReplyMarkup = &tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+
+	_, err := b.api.Send(edit)
+	return err
+}
+
+// handleReminderDoneCallback completes the task behind a reminder
+func (b *Bot) handleReminderDoneCallback(callback *tgbotapi.CallbackQuery, reminderIDStr string) error {
+	reminder, todo, err := b.ownReminder(callback.From.ID, reminderIDStr)
+	if err != nil || reminder == nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            b.catalog(callback.From.ID).T("reminder.not_found"),
+		})
+		return err
+	}
+
+	user, err := b.db.GetUserByTelegramID(callback.From.ID)
+	if err != nil || user == nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Completing the task stops all of its reminders
+	closed, _, err := b.closeTodo(todo, user, "completed")
+	if err != nil || closed == nil {
+		text := b.catalog(callback.From.ID).T("complete.failed")
+		if err == nil {
+			text = b.catalog(callback.From.ID).T("complete.not_pending_short")
+		}
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            text,
+		})
+		return err
+	}
+	b.markReminderInteracted(reminder.ID)
+
+	if err := b.finishReminderMessage(callback, reminder.ID, b.catalog(callback.From.ID).T("reminder.done_status", "title", todo.Title)); err != nil {
+		log.Printf("Failed to update reminder message: %v", err)
+	}
+
+	_, err = b.api.Request(tgbotapi.CallbackConfig{
+		CallbackQueryID: callback.ID,
+		Text:            b.catalog(callback.From.ID).T("reminder.done"),
+	})
+	return err
+}
+
+// handleReminderStopCallback stops a reminder from firing again
+func (b *Bot) handleReminderStopCallback(callback *tgbotapi.CallbackQuery, reminderIDStr string) error {
+	reminder, todo, err := b.ownReminder(callback.From.ID, reminderIDStr)
+	if err != nil || reminder == nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            b.catalog(callback.From.ID).T("reminder.not_found"),
+		})
+		return err
+	}
+
+	if err := b.db.DeactivateReminder(reminder.ID); err != nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            b.catalog(callback.From.ID).T("reminder.stop_failed"),
+		})
+		return err
+	}
+	b.markReminderInteracted(reminder.ID)
+
+	if err := b.finishReminderMessage(callback, reminder.ID, b.catalog(callback.From.ID).T("reminder.stopped_status", "title", todo.Title)); err != nil {
+		log.Printf("Failed to update reminder message: %v", err)
+	}
+
+	_, err = b.api.Request(tgbotapi.CallbackConfig{
+		CallbackQueryID: callback.ID,
+		Text:            b.catalog(callback.From.ID).T("reminder.stopped"),
+	})
+	return err
+}
+
+// handleRescheduleCallback asks the user for a new reminder time
+func (b *Bot) handleRescheduleCallback(callback *tgbotapi.CallbackQuery, reminderIDStr string) error {
+	reminder, todo, err := b.ownReminder(callback.From.ID, reminderIDStr)
+	if err != nil || reminder == nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            b.catalog(callback.From.ID).T("reminder.not_found"),
+		})
+		return err
+	}
+
+	b.setPending(callback.From.ID, pendingInput{Kind: pendingReschedule, ReminderID: reminder.ID})
+	b.markReminderInteracted(reminder.ID)
+
+	msgText := b.catalog(callback.From.ID).T("reschedule.prompt", "title", html.EscapeString(todo.Title))
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, msgText)
+	msg.ParseMode = "HTML"
+	if _, err := b.api.Send(msg); err != nil {
+		return err
+	}
+
+	_, err = b.api.Request(tgbotapi.CallbackConfig{
+		CallbackQueryID: callback.ID,
+	})
+	return err
+}
+
+// handleRescheduleInput applies the new time the user typed after pressing Reschedule
+func (b *Bot) handleRescheduleInput(message *tgbotapi.Message, input pendingInput) error {
+	c := b.catalog(message.From.ID)
+	reminder, todo, err := b.ownReminder(message.From.ID, input.ReminderID.String())
+	if err != nil {
+		return fmt.Errorf("failed to get reminder: %w", err)
+	}
+	if reminder == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("reschedule.gone"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	spec, err := parseReminderSpec(message.Text, time.Now(), b.userLocation(message.From.ID), todo.DueTime)
+	if err != nil {
+		// Keep waiting for a valid answer
+		b.setPending(message.From.ID, input)
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("reschedule.invalid_time", "error", c.Error(err)))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	updated, err := b.db.RescheduleReminder(reminder.ID, NewReminder{
+		TodoID:                todo.ID,
+		RepeatCount:           spec.Count,
+		RepeatIntervalSeconds: int64(spec.Interval / time.Second),
+		RepeatUntil:           spec.Until,
+		NextNotifyTime:        spec.NextTime.UTC(),
+		Schedule:              spec.Schedule,
+	})
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("reschedule.failed"))
+		_, err2 := b.api.Send(msg)
+		return err2
+	}
+
+	tf := b.timeFormatFor(message.From.ID)
+	msgText := c.T("reschedule.done",
+		"title", html.EscapeString(todo.Title),
+		"time", tf.When(updated.NextNotifyTime),
+		"repeat", b.describeRepeat(*updated, tf))
+	msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
+	msg.ParseMode = "HTML"
+
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleSnoozeOptions handles the /snoozeoptions command
+func (b *Bot) handleSnoozeOptions(message *tgbotapi.Message) error {
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	c := userCatalog(user)
+
+	args := strings.Fields(strings.ToLower(message.CommandArguments()))
+	if len(args) == 0 {
+		msgText := c.T("snoozeoptions.text",
+			"options", strings.Join(snoozeOptions(user), ", "), "max", maxSnoozeOptions)
+		msg := tgbotapi.NewMessage(message.Chat.ID, msgText)
+		msg.ParseMode = "HTML"
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	if len(args) == 1 && args[0] == "reset" {
+		args = strings.Split(defaultSnoozeOptions, ",")
+	}
+
+	if len(args) > maxSnoozeOptions {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("snoozeoptions.too_many", "max", maxSnoozeOptions))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	for _, option := range args {
+		if len(option) > maxSnoozeOptionLength {
+			msg := tgbotapi.NewMessage(message.Chat.ID, c.T("snoozeoptions.too_long",
+				"option", option, "max", maxSnoozeOptionLength))
+			_, err := b.api.Send(msg)
+			return err
+		}
+		if _, err := resolveSnooze(option, time.Now(), time.UTC); err != nil {
+			msg := tgbotapi.NewMessage(message.Chat.ID, c.T("snoozeoptions.invalid", "option", option))
+			_, err := b.api.Send(msg)
+			return err
+		}
+	}
+
+	if err := b.db.UpdateUserSnoozeOptions(user.ID, strings.Join(args, ",")); err != nil {
+		return fmt.Errorf("failed to update snooze options: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, c.T("snoozeoptions.updated", "options", strings.Join(args, ", ")))
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// renderReminderList builds the /reminders overview with per-reminder controls
+func (b *Bot) renderReminderList(user *User) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	reminders, err := b.db.GetUserReminders(user.ID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to get reminders: %w", err)
+	}
+	c := userCatalog(user)
+
+	if len(reminders) == 0 {
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(c.T("reminders.time_formats"), "reminder_help"),
+				tgbotapi.NewInlineKeyboardButtonData(c.T("menu.main"), "main_menu"),
+			),
+		)
+		return c.T("reminders.empty"), keyboard, nil
+	}
+
+	var text strings.Builder
+	text.WriteString(c.T("reminders.title") + "\n\n")
+
+	tf := userTimeFormat(user)
+	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+	for i, item := range reminders {
+		reminder := item.Reminder
+		text.WriteString(fmt.Sprintf("%d. <b>%s</b>\n", i+1, html.EscapeString(item.Todo.Title)))
+		text.WriteString(fmt.Sprintf("   📅 %s\n", tf.Time(reminder.NextNotifyTime)))
+		text.WriteString(fmt.Sprintf("   🔁 %s\n", b.describeRepeat(reminder, tf)))
+		if reminder.SnoozedUntil != nil {
+			text.WriteString("   " + c.T("snooze.until_compact", "time", tf.Time(*reminder.SnoozedUntil)) + "\n")
+		}
+		if reminder.IsPaused {
+			text.WriteString("   " + c.T("reminders.paused") + "\n")
+		}
+		text.WriteString("\n")
+
+		pauseButton := tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("⏸ %d", i+1), fmt.Sprintf("rpause:%s", reminder.ID))
+		if reminder.IsPaused {
+			pauseButton = tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("▶️ %d", i+1), fmt.Sprintf("rresume:%s", reminder.ID))
+		}
+		keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+			pauseButton,
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("✏️ %d", i+1), fmt.Sprintf("resched:%s", reminder.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("❌ %d", i+1), fmt.Sprintf("rcancel:%s", reminder.ID)),
+		))
+	}
+
+	text.WriteString(c.T("reminders.snooze_hint"))
+
+	keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(c.T("reminders.time_formats"), "reminder_help"),
+		tgbotapi.NewInlineKeyboardButtonData(c.T("menu.main"), "main_menu"),
+	))
+
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboardRows...), nil
+}
+
+// refreshReminderList redraws the /reminders message a callback came from
+func (b *Bot) refreshReminderList(callback *tgbotapi.CallbackQuery) error {
+	user, err := b.db.GetUserByTelegramID(callback.From.ID)
+	if err != nil || user == nil {
+		return err
+	}
+
+	text, keyboard, err := b.renderReminderList(user)
+	if err != nil {
+		return err
+	}
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID, text, keyboard)
+	edit.ParseMode = "HTML"
+
+	_, err = b.api.Send(edit)
+	return err
+}
+
+// handleReminderCancelCallback deletes a reminder from the /reminders list
+func (b *Bot) handleReminderCancelCallback(callback *tgbotapi.CallbackQuery, reminderIDStr string) error {
+	reminder, _, err := b.ownReminder(callback.From.ID, reminderIDStr)
+	if err != nil || reminder == nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            b.catalog(callback.From.ID).T("reminder.not_found"),
+		})
+		return err
+	}
+
+	if err := b.db.DeleteReminder(reminder.ID); err != nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            b.catalog(callback.From.ID).T("reminder.cancel_failed"),
+		})
+		return err
+	}
+
+	if err := b.refreshReminderList(callback); err != nil {
+		log.Printf("Failed to refresh reminder list: %v", err)
+	}
+
+	_, err = b.api.Request(tgbotapi.CallbackConfig{
+		CallbackQueryID: callback.ID,
+		Text:            b.catalog(callback.From.ID).T("reminder.cancelled"),
+	})
+	return err
+}
+
+// handleReminderPauseCallback pauses or resumes a reminder from the /reminders list
+func (b *Bot) handleReminderPauseCallback(callback *tgbotapi.CallbackQuery, reminderIDStr string, paused bool) error {
+	reminder, _, err := b.ownReminder(callback.From.ID, reminderIDStr)
+	if err != nil || reminder == nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            b.catalog(callback.From.ID).T("reminder.not_found"),
+		})
+		return err
+	}
+
+	if err := b.db.SetReminderPaused(reminder.ID, paused); err != nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            b.catalog(callback.From.ID).T("reminder.update_failed"),
+		})
+		return err
+	}
+
+	if err := b.refreshReminderList(callback); err != nil {
+		log.Printf("Failed to refresh reminder list: %v", err)
+	}
+
+	c := b.catalog(callback.From.ID)
+	callbackText := c.T("reminder.resumed")
+	if paused {
+		callbackText = c.T("reminder.paused")
+	}
+	_, err = b.api.Request(tgbotapi.CallbackConfig{
+		CallbackQueryID: callback.ID,
+		Text:            callbackText,
+	})
+	return err
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed 5-field cron expression (minute hour day-of-month month day-of-week)
+type cronSchedule struct {
+	minute  uint64
+	hour    uint64
+	dom     uint64
+	month   uint64
+	dow     uint64
+	domStar bool
+	dowStar bool
+}
+
+// cronField describes the valid range of a cron field
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var weekdayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	"sunday": 0, "monday": 1, "tuesday": 2, "wednesday": 3, "thursday": 4, "friday": 5, "saturday": 6,
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: monthNames},
+	{name: "day of week", min: 0, max: 7, names: weekdayNames},
+}
+
+// parseCron parses a standard 5-field cron expression
+func parseCron(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, newLocalizedError("error.cron_fields", "count", len(fields))
+	}
+
+	var bits [5]uint64
+	for i, field := range fields {
+		b, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		bits[i] = b
+	}
+
+	// 7 is an alias for Sunday
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	return &cronSchedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}, nil
+}
+
+// parseCronField parses one comma-separated cron field into a bitset
+func parseCronField(field string, spec cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(strings.ToLower(field), ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s < 1 {
+				return 0, newLocalizedError("error.cron_value", "field", spec.name, "input", part)
+			}
+			step = s
+			part = part[:i]
+		}
+
+		lo, hi := spec.min, spec.max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if lo, err = parseCronValue(bounds[0], spec); err != nil {
+				return 0, err
+			}
+			if hi, err = parseCronValue(bounds[1], spec); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, newLocalizedError("error.cron_value", "field", spec.name, "input", part)
+			}
+		default:
+			v, err := parseCronValue(part, spec)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			if step == 1 {
+				hi = v
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// parseCronValue parses a single number or name within a cron field
+func parseCronValue(s string, spec cronField) (int, error) {
+	if v, ok := spec.names[s]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < spec.min || v > spec.max {
+		return 0, newLocalizedError("error.cron_value", "field", spec.name, "input", s)
+	}
+	return v, nil
+}
+
+// matchesDay reports whether the schedule fires on the given date
+func (c *cronSchedule) matchesDay(t time.Time) bool {
+	if c.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+
+	// As in standard cron, a restricted day-of-month and day-of-week match either
+	switch {
+	case c.domStar && c.dowStar:
+		return true
+	case c.domStar:
+		return dowMatch
+	case c.dowStar:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
+
+// Next returns the first fire time strictly after the given time, evaluated
+// in loc so that wall-clock schedules stay aligned across DST changes
+func (c *cronSchedule) Next(after time.Time, loc *time.Location) (time.Time, error) {
+	after = after.In(loc)
+	day := time.Date(after.Year(), after.Month(), after.Day(), 0, 0, 0, 0, loc)
+
+	// Five years is enough for any satisfiable expression (e.g. Feb 29)
+	for i := 0; i < 5*366; i++ {
+		d := time.Date(day.Year(), day.Month(), day.Day()+i, 0, 0, 0, 0, loc)
+		if !c.matchesDay(d) {
+			continue
+		}
+		for h := 0; h < 24; h++ {
+			if c.hour&(1<<uint(h)) == 0 {
+				continue
+			}
+			for m := 0; m < 60; m++ {
+				if c.minute&(1<<uint(m)) == 0 {
+					continue
+				}
+				// Wall times skipped by DST are normalised forward by time.Date
+				t := time.Date(d.Year(), d.Month(), d.Day(), h, m, 0, 0, loc)
+				if t.After(after) {
+					return t, nil
+				}
+			}
+		}
+	}
+
+	return time.Time{}, newLocalizedError("error.cron_never")
+}
+
+// parseSchedule converts a schedule like "weekdays at 09:00",
+// "every mon,wed,fri 18:30", "first day of month 10:00" or a raw
+// 5-field cron expression into a validated cron expression
+func parseSchedule(spec string) (string, error) {
+	s := strings.ToLower(strings.TrimSpace(spec))
+	s = strings.TrimPrefix(s, "cron ")
+
+	if _, err := parseCron(s); err == nil {
+		return s, nil
+	}
+
+	// Split off the trailing time of day, optionally preceded by "at"
+	fields := strings.Fields(s)
+	if len(fields) < 2 {
+		return "", newLocalizedError("error.invalid_schedule", "input", spec)
+	}
+	hour, minute, err := parseClock(fields[len(fields)-1])
+	if err != nil {
+		return "", err
+	}
+	fields = fields[:len(fields)-1]
+	if fields[len(fields)-1] == "at" {
+		fields = fields[:len(fields)-1]
+	}
+	days := strings.Join(fields, " ")
+
+	var dom, dow string
+	switch days {
+	case "weekdays", "every weekday":
+		dom, dow = "*", "1-5"
+	case "weekends", "every weekend":
+		dom, dow = "*", "0,6"
+	case "daily", "every day":
+		dom, dow = "*", "*"
+	case "first day of month", "first day of the month", "monthly":
+		dom, dow = "1", "*"
+	default:
+		if !strings.HasPrefix(days, "every ") {
+			return "", newLocalizedError("error.invalid_schedule", "input", spec)
+		}
+		var nums []string
+		for _, name := range strings.Split(strings.TrimPrefix(days, "every "), ",") {
+			v, ok := weekdayNames[strings.TrimSpace(name)]
+			if !ok {
+				return "", newLocalizedError("error.unknown_day", "input", name)
+			}
+			nums = append(nums, strconv.Itoa(v))
+		}
+		dom, dow = "*", strings.Join(nums, ",")
+	}
+
+	return fmt.Sprintf("%d %d %s * %s", minute, hour, dom, dow), nil
+}
+
+// describeSchedule renders a cron expression for display
+func describeSchedule(c *Catalog, expr string) string {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return expr
+	}
+	minute, errM := strconv.Atoi(fields[0])
+	hour, errH := strconv.Atoi(fields[1])
+	if errM != nil || errH != nil || fields[3] != "*" {
+		return c.T("schedule.cron", "expr", expr)
+	}
+	at := fmt.Sprintf("%02d:%02d", hour, minute)
+
+	switch {
+	case fields[2] == "*" && fields[4] == "*":
+		return c.T("schedule.daily", "time", at)
+	case fields[2] == "*" && fields[4] == "1-5":
+		return c.T("schedule.weekdays", "time", at)
+	case fields[2] == "*" && fields[4] == "0,6":
+		return c.T("schedule.weekends", "time", at)
+	case fields[2] == "1" && fields[4] == "*":
+		return c.T("schedule.monthly", "time", at)
+	case fields[2] == "*":
+		var days []string
+		for _, d := range strings.Split(fields[4], ",") {
+			v, err := strconv.Atoi(d)
+			if err != nil || v < 0 || v > 6 {
+				return c.T("schedule.cron", "expr", expr)
+			}
+			days = append(days, c.T(fmt.Sprintf("schedule.day.%d", v)))
+		}
+		return c.T("schedule.days", "days", strings.Join(days, ","), "time", at)
+	}
+	return c.T("schedule.cron", "expr", expr)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronField(t *testing.T) {
+	tests := []struct {
+		field string
+		spec  cronField
+		want  []int
+	}{
+		{"*", cronFields[4], []int{0, 1, 2, 3, 4, 5, 6, 7}},
+		{"5", cronFields[0], []int{5}},
+		{"1,15", cronFields[2], []int{1, 15}},
+		{"1-5", cronFields[4], []int{1, 2, 3, 4, 5}},
+		{"mon-fri", cronFields[4], []int{1, 2, 3, 4, 5}},
+		{"*/15", cronFields[0], []int{0, 15, 30, 45}},
+		{"10-20/5", cronFields[1], []int{10, 15, 20}},
+		{"5/20", cronFields[0], []int{5, 25, 45}},
+		{"jan,jul-aug", cronFields[3], []int{1, 7, 8}},
+	}
+	for _, tt := range tests {
+		got, err := parseCronField(tt.field, tt.spec)
+		if err != nil {
+			t.Errorf("parseCronField(%q) error: %v", tt.field, err)
+			continue
+		}
+		var want uint64
+		for _, v := range tt.want {
+			want |= 1 << uint(v)
+		}
+		if got != want {
+			t.Errorf("parseCronField(%q) = %b, want %b", tt.field, got, want)
+		}
+	}
+}
+
+func TestParseCronFieldErrors(t *testing.T) {
+	tests := []struct {
+		field string
+		spec  cronField
+	}{
+		{"60", cronFields[0]},
+		{"24", cronFields[1]},
+		{"0", cronFields[2]},
+		{"13", cronFields[3]},
+		{"8", cronFields[4]},
+		{"5-1", cronFields[0]},
+		{"*/0", cronFields[0]},
+		{"*/x", cronFields[0]},
+		{"abc", cronFields[1]},
+		{"", cronFields[0]},
+	}
+	for _, tt := range tests {
+		if _, err := parseCronField(tt.field, tt.spec); err == nil {
+			t.Errorf("parseCronField(%q) succeeded, want an error", tt.field)
+		}
+	}
+}
+
+func TestParseCron(t *testing.T) {
+	if _, err := parseCron("0 9 * *"); err == nil {
+		t.Error("parseCron with four fields succeeded, want an error")
+	}
+
+	// 7 is Sunday as well as 0
+	cron, err := parseCron("0 9 * * 7")
+	if err != nil {
+		t.Fatalf("parseCron error: %v", err)
+	}
+	if cron.dow != 1 {
+		t.Errorf("dow = %b, want Sunday only", cron.dow)
+	}
+	if !cron.domStar || cron.dowStar {
+		t.Errorf("domStar, dowStar = %v, %v, want true, false", cron.domStar, cron.dowStar)
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// Sunday
+	sunday := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"later today", "30 18 * * *", sunday, time.Date(2026, 10, 18, 18, 30, 0, 0, time.UTC)},
+		{"strictly after", "0 10 * * *", sunday, time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)},
+		{"weekdays skip weekend", "30 9 * * 1-5", time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC), time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)},
+		{"first of month", "0 0 1 * *", sunday, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"day of month only", "0 9 13 * *", sunday, time.Date(2026, 11, 13, 9, 0, 0, 0, time.UTC)},
+		{"day of week only", "0 9 * * fri", sunday, time.Date(2026, 10, 23, 9, 0, 0, 0, time.UTC)},
+		{"day of month or week, week first", "0 9 13 * 5", sunday, time.Date(2026, 10, 23, 9, 0, 0, 0, time.UTC)},
+		{"day of month or week, month first", "0 9 20 * 5", sunday, time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)},
+		{"leap day", "0 12 29 2 *", sunday, time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"steps", "*/20 */6 * * *", sunday, time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		cron, err := parseCron(tt.expr)
+		if err != nil {
+			t.Errorf("%s: parseCron(%q) error: %v", tt.name, tt.expr, err)
+			continue
+		}
+		got, err := cron.Next(tt.after, time.UTC)
+		if err != nil {
+			t.Errorf("%s: Next error: %v", tt.name, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: Next(%v) = %v, want %v", tt.name, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestCronNextNever(t *testing.T) {
+	cron, err := parseCron("0 9 31 2 *")
+	if err != nil {
+		t.Fatalf("parseCron error: %v", err)
+	}
+	if _, err := cron.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.UTC); err == nil {
+		t.Error("Next of February 31st succeeded, want an error")
+	}
+}
+
+func TestCronNextAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+	cron, err := parseCron("0 9 * * *")
+	if err != nil {
+		t.Fatalf("parseCron error: %v", err)
+	}
+
+	// Clocks go forward on 2026-03-08; the reminder keeps its wall time
+	got, err := cron.Next(time.Date(2026, 3, 7, 12, 0, 0, 0, loc), loc)
+	if err != nil {
+		t.Fatalf("Next error: %v", err)
+	}
+	want := time.Date(2026, 3, 8, 9, 0, 0, 0, loc)
+	if !got.Equal(want) || got.Hour() != 9 {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"weekdays at 09:00", "0 9 * * 1-5"},
+		{"weekends 10am", "0 10 * * 0,6"},
+		{"daily 7:15", "15 7 * * *"},
+		{"every mon,wed,fri 18:30", "30 18 * * 1,3,5"},
+		{"first day of month 10:00", "0 10 1 * *"},
+		{"0 9 * * *", "0 9 * * *"},
+		{"cron */30 8-18 * * 1-5", "*/30 8-18 * * 1-5"},
+	}
+	for _, tt := range tests {
+		got, err := parseSchedule(tt.spec)
+		if err != nil {
+			t.Errorf("parseSchedule(%q) error: %v", tt.spec, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseSchedule(%q) = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"someday 09:00", "every funday 09:00", "weekdays", "weekdays at 25:00"} {
+		if _, err := parseSchedule(spec); err == nil {
+			t.Errorf("parseSchedule(%q) succeeded, want an error", spec)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"container/heap"
+	"context"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reminder scheduling tuning
+const (
+	// reminderSweepInterval is how often the schedule is reloaded from the
+	// database as a safety net for missed change notifications
+	reminderSweepInterval = 5 * time.Minute
+	// reminderPollInterval is how often the schedule is reloaded while no
+	// change listener is connected
+	reminderPollInterval = 30 * time.Second
+	// reminderHorizon is how far ahead fire times are kept in memory; later
+	// ones are picked up by a later sweep
+	reminderHorizon = 2 * reminderSweepInterval
+	// reminderListenRetry is how long to wait before reconnecting the listener
+	reminderListenRetry = 10 * time.Second
+	// escalationCheckInterval is how often ignored reminders are checked
+	escalationCheckInterval = time.Minute
+)
+
+// fireHeap is a min-heap of reminder fire times
+type fireHeap []ReminderFire
+
+func (h fireHeap) Len() int            { return len(h) }
+func (h fireHeap) Less(i, j int) bool  { return h[i].At.Before(h[j].At) }
+func (h fireHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *fireHeap) Push(x interface{}) { *h = append(*h, x.(ReminderFire)) }
+func (h *fireHeap) Pop() interface{} {
+	old := *h
+	fire := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return fire
+}
+
+// reminderScheduler keeps upcoming reminder fire times in memory so due
+// reminders are claimed when they fire instead of on a polling interval
+type reminderScheduler struct {
+	mu    sync.Mutex
+	fires fireHeap
+	// latest holds each reminder's current fire time; heap entries that
+	// no longer match are stale and skipped
+	latest map[uuid.UUID]time.Time
+	// wake is poked whenever the earliest fire time may have changed
+	wake chan struct{}
+
+	listening     bool
+	sweepNeeded   bool
+	lastSweep     time.Time
+	notifications int64
+	wakeups       int64
+	sweeps        int64
+
+	// Lag between when reminders were due and when they were sent
+	lagCount int64
+	lagTotal time.Duration
+	lagMax   time.Duration
+	lagLast  time.Duration
+}
+
+// newReminderScheduler creates an empty scheduler
+func newReminderScheduler() *reminderScheduler {
+	return &reminderScheduler{
+		latest: make(map[uuid.UUID]time.Time),
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// poke wakes the scheduler loop without blocking
+func (s *reminderScheduler) poke() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// schedule records when a reminder fires next. Times beyond the horizon are
+// left for a later sweep.
+func (s *reminderScheduler) schedule(reminderID uuid.UUID, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if at.After(time.Now().Add(reminderHorizon)) {
+		delete(s.latest, reminderID)
+		return
+	}
+	if current, ok := s.latest[reminderID]; ok && current.Equal(at) {
+		return
+	}
+	s.latest[reminderID] = at
+	heap.Push(&s.fires, ReminderFire{ReminderID: reminderID, At: at})
+	s.poke()
+}
+
+// unschedule forgets a reminder that no longer fires
+func (s *reminderScheduler) unschedule(reminderID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.latest, reminderID)
+}
+
+// next returns the earliest current fire time, dropping stale entries
+func (s *reminderScheduler) next() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.fires) > 0 {
+		fire := s.fires[0]
+		if at, ok := s.latest[fire.ReminderID]; ok && at.Equal(fire.At) {
+			return fire.At, true
+		}
+		heap.Pop(&s.fires)
+	}
+	return time.Time{}, false
+}
+
+// popDue removes the fire times that have passed and returns how many
+// current ones there were
+func (s *reminderScheduler) popDue(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := 0
+	for len(s.fires) > 0 && !s.fires[0].At.After(now) {
+		fire := heap.Pop(&s.fires).(ReminderFire)
+		if at, ok := s.latest[fire.ReminderID]; ok && at.Equal(fire.At) {
+			delete(s.latest, fire.ReminderID)
+			due++
+		}
+	}
+	if due > 0 {
+		s.wakeups++
+	}
+	return due
+}
+
+// setListening records whether change notifications are arriving. A sweep
+// follows every (re)connect to pick up changes made while disconnected.
+func (s *reminderScheduler) setListening(listening bool) {
+	s.mu.Lock()
+	s.listening = listening
+	s.sweepNeeded = true
+	s.mu.Unlock()
+	s.poke()
+}
+
+// sweepDue reports whether the schedule should be reloaded from the database
+func (s *reminderScheduler) sweepDue(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	interval := reminderSweepInterval
+	if !s.listening {
+		interval = reminderPollInterval
+	}
+	return s.sweepNeeded || now.Sub(s.lastSweep) >= interval
+}
+
+// sweepDone records a completed sweep
+func (s *reminderScheduler) sweepDone(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweepNeeded = false
+	s.lastSweep = now
+	s.sweeps++
+}
+
+// recordLag records how late a reminder was sent
+func (s *reminderScheduler) recordLag(lag time.Duration) {
+	if lag < 0 {
+		lag = 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lagCount++
+	s.lagTotal += lag
+	s.lagLast = lag
+	if lag > s.lagMax {
+		s.lagMax = lag
+	}
+}
+
+// handleNotification applies a reminder change notification payload
+func (s *reminderScheduler) handleNotification(payload string) {
+	s.mu.Lock()
+	s.notifications++
+	s.mu.Unlock()
+
+	fields := strings.Fields(payload)
+	if len(fields) == 0 {
+		return
+	}
+	reminderID, err := uuid.Parse(fields[0])
+	if err != nil {
+		log.Printf("Invalid reminder change notification %q", payload)
+		return
+	}
+	if len(fields) == 1 {
+		s.unschedule(reminderID)
+		return
+	}
+
+	seconds, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		log.Printf("Invalid reminder change notification %q", payload)
+		return
+	}
+	s.schedule(reminderID, time.UnixMicro(int64(math.Round(seconds*1e6))))
+}
+
+// statsText renders the scheduler statistics for /serverstats
+func (s *reminderScheduler) statsText(c *Catalog) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mode := c.T("scheduler.polling", "interval", formatInterval(reminderPollInterval))
+	if s.listening {
+		mode = c.T("scheduler.listening")
+	}
+
+	avg := time.Duration(0)
+	if s.lagCount > 0 {
+		avg = s.lagTotal / time.Duration(s.lagCount)
+	}
+
+	return c.T("scheduler.stats",
+		"mode", mode,
+		"upcoming", len(s.latest),
+		"horizon", formatInterval(reminderHorizon),
+		"sent", s.lagCount,
+		"avg", formatLag(avg),
+		"max", formatLag(s.lagMax),
+		"last", formatLag(s.lagLast),
+		"wakeups", s.wakeups,
+		"notifications", s.notifications,
+		"sweeps", s.sweeps)
+}
+
+// formatLag renders a scheduling lag to millisecond precision
+func formatLag(d time.Duration) string {
+	return d.Round(time.Millisecond).String()
+}
+
+// reminderChecker runs in background and sends reminders as they fall due.
+// It sleeps until the earliest known fire time, is woken when reminders
+// change, and periodically reloads the schedule from the database.
+func (b *Bot) reminderChecker() {
+	go b.listenForReminderChanges()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	sweep := time.NewTicker(reminderPollInterval)
+	defer sweep.Stop()
+	escalations := time.NewTicker(escalationCheckInterval)
+	defer escalations.Stop()
+
+	for {
+		now := time.Now()
+		if b.scheduler.sweepDue(now) {
+			b.sweepReminders(now)
+		}
+
+		wait := reminderPollInterval
+		if at, ok := b.scheduler.next(); ok {
+			wait = time.Until(at)
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-timer.C:
+			if b.scheduler.popDue(time.Now()) > 0 {
+				b.sendDueReminders()
+			}
+		case <-b.scheduler.wake:
+		case <-sweep.C:
+		case <-escalations.C:
+			b.checkEscalations()
+		}
+	}
+}
+
+// sendDueReminders claims and sends due reminders until none are left
+func (b *Bot) sendDueReminders() {
+	for b.checkAndSendReminders() == reminderClaimBatch {
+	}
+}
+
+// sweepReminders sends anything already due, e.g. after an expired claim,
+// and reloads upcoming fire times from the database
+func (b *Bot) sweepReminders(now time.Time) {
+	b.sendDueReminders()
+
+	fires, err := b.db.GetUpcomingReminderFires(now.Add(reminderHorizon))
+	if err != nil {
+		log.Printf("Failed to load upcoming reminders: %v", err)
+		return
+	}
+	for _, fire := range fires {
+		b.scheduler.schedule(fire.ReminderID, fire.At)
+	}
+	b.scheduler.sweepDone(now)
+}
+
+// listenForReminderChanges keeps a database listener connected, feeding
+// reminder changes into the scheduler
+func (b *Bot) listenForReminderChanges() {
+	for {
+		err := b.db.ListenReminderChanges(context.Background(), func() {
+			log.Println("Listening for reminder changes")
+			b.scheduler.setListening(true)
+		}, b.scheduler.handleNotification)
+
+		b.scheduler.setListening(false)
+		log.Printf("Reminder change listener stopped, polling until it reconnects: %v", err)
+		time.Sleep(reminderListenRetry)
+	}
+}
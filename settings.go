@@ -0,0 +1,541 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// settingOption is one choice in a settings submenu
+type settingOption struct {
+	value string
+	label string
+}
+
+// settingSection is one entry of the settings panel. Options are chosen
+// with set:<key>:<value>, so values must not contain colons; clock times
+// are packed as "0900" and ranges as "2200-0700". Its title and optional
+// hint are the catalog messages settings.<key>.title and settings.<key>.hint.
+type settingSection struct {
+	key     string
+	hint    bool
+	options func(c *Catalog) []settingOption
+	// current returns the option value matching the user's setting
+	current func(user *User) string
+	// change turns a chosen option value into a settings update
+	change func(value string) UserSettings
+	// applied runs after the change is stored, if set
+	applied func(b *Bot, user *User)
+}
+
+// title returns the section's name in the panel
+func (s settingSection) title(c *Catalog) string {
+	return c.T("settings." + s.key + ".title")
+}
+
+// weekStarts maps users.week_start to the first day of the week
+var weekStarts = map[string]time.Weekday{
+	"mon": time.Monday,
+	"sun": time.Sunday,
+	"sat": time.Saturday,
+}
+
+// listViews lists the views /list can show, in the order settings shows them
+var listViews = []string{"all", "pending", "today", "week"}
+
+// catalogOptions builds options labelled by the catalog messages
+// <prefix><value>
+func catalogOptions(prefix string, values ...string) func(c *Catalog) []settingOption {
+	return func(c *Catalog) []settingOption {
+		options := make([]settingOption, len(values))
+		for i, value := range values {
+			options[i] = settingOption{value, c.T(prefix + value)}
+		}
+		return options
+	}
+}
+
+// dateOptions builds the date format options, each labelled with a sample
+// date written in that format
+func dateOptions(c *Catalog) []settingOption {
+	sample := time.Date(2026, time.December, 31, 0, 0, 0, 0, time.UTC)
+	options := make([]settingOption, len(dateFormats))
+	for i, format := range dateFormats {
+		options[i] = settingOption{format, formatDate(c, &User{DateFormat: format}, sample, sample.AddDate(1, 0, 0))}
+	}
+	return options
+}
+
+// settingSections lists the settings panel entries in display order. The
+// timezone entry opens the timezone menu and is handled separately.
+var settingSections = []settingSection{
+	{
+		key: "lang",
+		options: func(*Catalog) []settingOption {
+			options := make([]settingOption, len(languages))
+			for i, catalog := range languages {
+				options[i] = settingOption{catalog.Language, catalog.Flag + " " + catalog.Name}
+			}
+			return options
+		},
+		current: func(user *User) string { return user.Language },
+		change:  func(value string) UserSettings { return UserSettings{Language: &value} },
+	},
+	{
+		key:     "remind",
+		hint:    true,
+		options: catalogOptions("settings.remind.", "0", "1", "2", "6", "12", "24", "48"),
+		current: func(user *User) string {
+			if user.ReminderOffsets != nil {
+				offsets, _ := parseOffsets(*user.ReminderOffsets)
+				return formatOffsets(offsets)
+			}
+			return strconv.Itoa(user.DefaultReminderInterval)
+		},
+		change: func(value string) UserSettings {
+			hours, _ := strconv.Atoi(value)
+			return UserSettings{DefaultReminderInterval: &hours}
+		},
+		applied: func(b *Bot, user *User) {
+			if user.ReminderOffsets != nil {
+				if err := b.db.UpdateUserReminderOffsets(user.ID, nil); err != nil {
+					log.Printf("Failed to clear reminder offsets of user %s: %v", user.ID, err)
+					return
+				}
+				user.ReminderOffsets = nil
+			}
+			b.resyncUserDueReminders(user)
+		},
+	},
+	{
+		key:     "style",
+		hint:    true,
+		options: catalogOptions("style.", notificationStyles...),
+		current: notificationStyle,
+		change:  func(value string) UserSettings { return UserSettings{NotificationStyle: &value} },
+	},
+	{
+		key:  "quiet",
+		hint: true,
+		options: func(c *Catalog) []settingOption {
+			return []settingOption{
+				{"off", c.T("settings.off")},
+				{"2100-0600", "21:00–06:00"},
+				{"2200-0700", "22:00–07:00"},
+				{"2300-0700", "23:00–07:00"},
+				{"0000-0800", "00:00–08:00"},
+			}
+		},
+		current: func(user *User) string {
+			if user.QuietStart == nil || user.QuietEnd == nil {
+				return "off"
+			}
+			return packClock(*user.QuietStart) + "-" + packClock(*user.QuietEnd)
+		},
+		change: func(value string) UserSettings {
+			start, end, _ := strings.Cut(value, "-")
+			start, end = unpackClock(start), unpackClock(end)
+			return UserSettings{QuietStart: &start, QuietEnd: &end}
+		},
+	},
+	{
+		key:     "digest",
+		hint:    true,
+		options: clockOptions("0700", "0800", "0900", "1200", "1800"),
+		current: func(user *User) string {
+			if !user.DigestEnabled {
+				return "off"
+			}
+			return packClock(user.DigestTime)
+		},
+		change: func(value string) UserSettings {
+			enabled := value != "off"
+			settings := UserSettings{DigestEnabled: &enabled}
+			if enabled {
+				digestTime := unpackClock(value)
+				settings.DigestTime = &digestTime
+			}
+			return settings
+		},
+	},
+	{
+		key:     "briefing",
+		hint:    true,
+		options: clockOptions("0600", "0700", "0730", "0800", "0900"),
+		current: func(user *User) string { return optionalClock(user.BriefingTime) },
+		change: func(value string) UserSettings {
+			briefingTime := unpackClock(value)
+			return UserSettings{BriefingTime: &briefingTime}
+		},
+	},
+	{
+		key:     "review",
+		hint:    true,
+		options: clockOptions("1800", "1900", "2000", "2100", "2200"),
+		current: func(user *User) string { return optionalClock(user.ReviewTime) },
+		change: func(value string) UserSettings {
+			reviewTime := unpackClock(value)
+			return UserSettings{ReviewTime: &reviewTime}
+		},
+	},
+	{
+		key:     "week",
+		hint:    true,
+		options: catalogOptions("settings.week.", "mon", "sun", "sat"),
+		current: func(user *User) string { return user.WeekStart },
+		change:  func(value string) UserSettings { return UserSettings{WeekStart: &value} },
+	},
+	{
+		key:     "clock",
+		options: catalogOptions("settings.clock.", "24", "12"),
+		current: func(user *User) string {
+			if user.Clock24h {
+				return "24"
+			}
+			return "12"
+		},
+		change: func(value string) UserSettings {
+			clock24h := value == "24"
+			return UserSettings{Clock24h: &clock24h}
+		},
+	},
+	{
+		key:     "date",
+		options: dateOptions,
+		current: dateFormat,
+		change:  func(value string) UserSettings { return UserSettings{DateFormat: &value} },
+	},
+	{
+		key:     "priority",
+		hint:    true,
+		options: catalogOptions("priority.", "high", "medium", "low"),
+		current: func(user *User) string { return user.DefaultPriority },
+		change:  func(value string) UserSettings { return UserSettings{DefaultPriority: &value} },
+	},
+	{
+		key:     "list",
+		hint:    true,
+		options: catalogOptions("list.view.", listViews...),
+		current: func(user *User) string { return user.DefaultList },
+		change:  func(value string) UserSettings { return UserSettings{DefaultList: &value} },
+	},
+}
+
+// clockOptions builds an "Off" option followed by the given packed times
+func clockOptions(times ...string) func(c *Catalog) []settingOption {
+	return func(c *Catalog) []settingOption {
+		options := []settingOption{{"off", c.T("settings.off")}}
+		for _, t := range times {
+			options = append(options, settingOption{t, unpackClock(t)})
+		}
+		return options
+	}
+}
+
+// packClock turns "09:00" into the callback form "0900"
+func packClock(clock string) string {
+	return strings.ReplaceAll(clock, ":", "")
+}
+
+// unpackClock turns a packed "0900" back into "09:00"; "off" becomes empty
+func unpackClock(packed string) string {
+	if len(packed) != 4 {
+		return ""
+	}
+	return packed[:2] + ":" + packed[2:]
+}
+
+// optionalClock returns the packed form of an optional time, or "off"
+func optionalClock(clock *string) string {
+	if clock == nil {
+		return "off"
+	}
+	return packClock(*clock)
+}
+
+// findSettingSection looks a settings panel entry up by key
+func findSettingSection(key string) (settingSection, bool) {
+	for _, section := range settingSections {
+		if section.key == key {
+			return section, true
+		}
+	}
+	return settingSection{}, false
+}
+
+// settingValue describes the user's current value of a setting
+func settingValue(c *Catalog, section settingSection, user *User) string {
+	current := section.current(user)
+	for _, option := range section.options(c) {
+		if option.value == current {
+			return option.label
+		}
+	}
+	// Values set by commands may not be one of the offered options
+	if start, end, ok := strings.Cut(current, "-"); ok && len(start) == 4 && len(end) == 4 {
+		return unpackClock(start) + "–" + unpackClock(end)
+	}
+	if len(current) == 4 {
+		if _, err := strconv.Atoi(current); err == nil {
+			return unpackClock(current)
+		}
+	}
+	return current
+}
+
+// dateFormat returns the user's date format, defaulting to written dates
+func dateFormat(user *User) string {
+	if user != nil {
+		for _, format := range dateFormats {
+			if user.DateFormat == format {
+				return format
+			}
+		}
+	}
+	return dateFormatText
+}
+
+// defaultPriority returns the priority new tasks get
+func defaultPriority(user *User) string {
+	switch user.DefaultPriority {
+	case "high", "low":
+		return user.DefaultPriority
+	}
+	return "medium"
+}
+
+// localWeek returns the start and end of the user's current local week
+func (b *Bot) localWeek(user *User, now time.Time) (time.Time, time.Time) {
+	start, _ := b.localDay(user, now)
+	first, ok := weekStarts[user.WeekStart]
+	if !ok {
+		first = time.Monday
+	}
+	start = start.AddDate(0, 0, -((int(start.Weekday()) - int(first) + 7) % 7))
+	return start, start.AddDate(0, 0, 7)
+}
+
+// listViewFilter returns which tasks a list view shows. Unknown views show
+// everything.
+func (b *Bot) listViewFilter(user *User, view string, now time.Time) func(Todo) bool {
+	var end time.Time
+	switch view {
+	case "pending":
+		return func(todo Todo) bool { return todo.Status == "pending" }
+	case "today":
+		_, end = b.localDay(user, now)
+	case "week":
+		_, end = b.localWeek(user, now)
+	default:
+		return func(Todo) bool { return true }
+	}
+
+	return func(todo Todo) bool {
+		return todo.Status == "pending" && todo.DueTime != nil && todo.DueTime.Before(end)
+	}
+}
+
+// listView picks the view /list shows: a valid argument, else the user's default
+func listView(user *User, arg string) string {
+	for _, view := range listViews {
+		if view == arg {
+			return arg
+		}
+	}
+	return user.DefaultList
+}
+
+// listTitle returns the heading of a task list view
+func listTitle(c *Catalog, view string) string {
+	switch view {
+	case "pending", "today", "week":
+		return c.T("list.view_title", "view", c.T("list.view."+view))
+	}
+	return c.T("list.title")
+}
+
+// settingsPanel builds the main settings panel
+func (b *Bot) settingsPanel(user *User) (string, tgbotapi.InlineKeyboardMarkup) {
+	c := userCatalog(user)
+	zone := b.getUserTimezone(user.TelegramID)
+
+	var text strings.Builder
+	text.WriteString(c.T("settings.panel",
+		"name", html.EscapeString(user.Name), "zone", html.EscapeString(zone), "offset", zoneOffset(zone, time.Now())) + "\n")
+	for _, section := range settingSections {
+		text.WriteString(fmt.Sprintf("%s: <b>%s</b>\n", section.title(c), html.EscapeString(settingValue(c, section, user))))
+	}
+	text.WriteString("\n" + c.T("settings.tap"))
+
+	buttons := []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData(c.T("settings.timezone"), "set:tz"),
+	}
+	for _, section := range settingSections {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(section.title(c), "set:"+section.key))
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(buttons); i += 2 {
+		rows = append(rows, buttons[i:min(i+2, len(buttons))])
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(c.T("menu.main"), "main_menu"),
+		tgbotapi.NewInlineKeyboardButtonData(c.T("menu.help"), "help"),
+	))
+
+	return text.String(), tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// settingSubmenu builds the options of one setting, ticking the current one
+func settingSubmenu(section settingSection, user *User) (string, tgbotapi.InlineKeyboardMarkup) {
+	c := userCatalog(user)
+	text := section.title(c) + "\n\n" + c.T("settings.current", "value", html.EscapeString(settingValue(c, section, user)))
+	if section.hint {
+		text += "\n\n" + c.T("settings."+section.key+".hint")
+	}
+
+	current := section.current(user)
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for _, option := range section.options(c) {
+		label := option.label
+		if option.value == current {
+			label = "✓ " + label
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, "set:"+section.key+":"+option.value))
+		if len(row) == 3 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	rows = append(rows, settingsBackRow(c))
+
+	return text, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// settingsBackRow returns the button leading back to the settings panel
+func settingsBackRow(c *Catalog) []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(c.T("settings.back"), "set:main"),
+	)
+}
+
+// handleSettings handles the settings callback
+func (b *Bot) handleSettings(callback *tgbotapi.CallbackQuery) error {
+	user, err := b.db.GetUserByTelegramID(callback.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            catalogFor(callback.From.LanguageCode).T("error.start_first"),
+		})
+		return err
+	}
+
+	text, keyboard := b.settingsPanel(user)
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = keyboard
+	if _, err := b.api.Send(msg); err != nil {
+		return err
+	}
+
+	_, err = b.api.Request(tgbotapi.CallbackConfig{
+		CallbackQueryID: callback.ID,
+	})
+	return err
+}
+
+// handleSettingsCommand handles the /settings command
+func (b *Bot) handleSettingsCommand(message *tgbotapi.Message) error {
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	text, keyboard := b.settingsPanel(user)
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = keyboard
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleSettingsCallback navigates the settings panel and applies choices,
+// editing the panel message in place
+func (b *Bot) handleSettingsCallback(callback *tgbotapi.CallbackQuery, key, value string) error {
+	user, err := b.db.GetUserByTelegramID(callback.From.ID)
+	if err != nil || user == nil || callback.Message == nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+		})
+		return err
+	}
+
+	var text string
+	var keyboard tgbotapi.InlineKeyboardMarkup
+	answer := ""
+
+	section, found := findSettingSection(key)
+	switch {
+	case key == "tz":
+		text, keyboard = b.timezoneMenuText(user), timezoneMenuKeyboard(userCatalog(user))
+
+	case !found:
+		text, keyboard = b.settingsPanel(user)
+
+	case value == "":
+		text, keyboard = settingSubmenu(section, user)
+
+	default:
+		valid := false
+		for _, option := range section.options(userCatalog(user)) {
+			if option.value == value {
+				valid = true
+			}
+		}
+		if !valid {
+			_, err := b.api.Request(tgbotapi.CallbackConfig{
+				CallbackQueryID: callback.ID,
+			})
+			return err
+		}
+
+		updated, err := b.db.UpdateUserSettings(user.ID, section.change(value))
+		if err != nil {
+			return fmt.Errorf("failed to update settings: %w", err)
+		}
+		if section.applied != nil {
+			section.applied(b, updated)
+		}
+		text, keyboard = settingSubmenu(section, updated)
+		answer = userCatalog(updated).T("settings.saved")
+	}
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID, text, keyboard)
+	edit.ParseMode = "HTML"
+	if _, err := b.api.Send(edit); err != nil {
+		log.Printf("Failed to update settings panel: %v", err)
+	}
+
+	_, err = b.api.Request(tgbotapi.CallbackConfig{
+		CallbackQueryID: callback.ID,
+		Text:            answer,
+	})
+	return err
+}
@@ -0,0 +1,524 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/google/uuid"
+)
+
+// A user can share their personal list through invite links that open the
+// bot with /start join_<token>. People who join see the owner's tasks in
+// their own /list. Viewers can only look; editors can add, complete,
+// reopen, delete and reschedule tasks. Reminders and assignments stay with
+// the owner. The owner manages links and members with /share, members see
+// and leave their lists with /shared.
+
+// Roles of the members of a shared list
+const (
+	roleViewer = "viewer"
+	roleEditor = "editor"
+)
+
+// joinPayloadPrefix starts the /start payload of invite links
+const joinPayloadPrefix = "join_"
+
+// newInviteToken returns a random token for an invite link
+func newInviteToken() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate invite token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// inviteLink returns the deep link of an invite
+func (b *Bot) inviteLink(invite *ListInvite) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s%s", b.api.Self.UserName, joinPayloadPrefix, invite.Token)
+}
+
+// inviteActive reports whether an invite can still be used
+func inviteActive(invite *ListInvite, now time.Time) bool {
+	return invite.RevokedAt == nil && (invite.ExpiresAt == nil || invite.ExpiresAt.After(now))
+}
+
+// roleName returns the translated name of a list role
+func roleName(c *Catalog, role string) string {
+	return c.T("share.role." + role)
+}
+
+// listRole returns a member's role on a user's list, or "" if they are not
+// a member
+func (b *Bot) listRole(ownerID, memberID uuid.UUID) string {
+	role, err := b.db.GetListRole(ownerID, memberID)
+	if err != nil {
+		log.Printf("Failed to get role of %s on list of %s: %v", memberID, ownerID, err)
+	}
+	return role
+}
+
+// todoOwner returns the owner of a task in a user's list, which differs
+// from the user for shared lists and assigned tasks
+func (b *Bot) todoOwner(todo *Todo, user *User) (*User, error) {
+	if ownsTodo(todo, user) {
+		return user, nil
+	}
+	owner, err := b.db.GetUserByID(todo.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if owner == nil {
+		return nil, fmt.Errorf("owner of todo %s not found", todo.ID)
+	}
+	return owner, nil
+}
+
+// notifyUser sends a short HTML message to a user, logging failures
+func (b *Bot) notifyUser(user *User, text string) {
+	msg := tgbotapi.NewMessage(user.TelegramID, text)
+	msg.ParseMode = "HTML"
+	if _, err := b.api.Send(msg); err != nil {
+		log.Printf("Failed to notify user %s: %v", user.ID, err)
+	}
+}
+
+// handleShare handles the /share command: without arguments it shows the
+// list's members and links, with a role and an optional expiry it creates
+// an invite link
+func (b *Bot) handleShare(message *tgbotapi.Message) error {
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	c := userCatalog(user)
+
+	fields := strings.Fields(strings.ToLower(message.CommandArguments()))
+	if len(fields) == 0 {
+		text, keyboard, err := b.shareOverview(user)
+		if err != nil {
+			return err
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		msg.ParseMode = "HTML"
+		msg.DisableWebPagePreview = true
+		if len(keyboard.InlineKeyboard) > 0 {
+			msg.ReplyMarkup = keyboard
+		}
+		_, err = b.api.Send(msg)
+		return err
+	}
+
+	role := fields[0]
+	if (role != roleViewer && role != roleEditor) || len(fields) > 2 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("share.usage"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	var expiresAt *time.Time
+	if len(fields) == 2 {
+		d, err := parseDuration(fields[1])
+		if err != nil || d <= 0 {
+			msg := tgbotapi.NewMessage(message.Chat.ID, c.T("share.invalid_expiry", "input", fields[1]))
+			_, err := b.api.Send(msg)
+			return err
+		}
+		expires := time.Now().Add(d).UTC()
+		expiresAt = &expires
+	}
+
+	token, err := newInviteToken()
+	if err != nil {
+		return err
+	}
+	invite, err := b.db.CreateListInvite(NewListInvite{
+		OwnerID:   user.ID,
+		Token:     token,
+		Role:      role,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("share.failed"))
+		_, err2 := b.api.Send(msg)
+		return err2
+	}
+
+	text := c.T("share.link_created", "role", roleName(c, role), "link", b.inviteLink(invite)) + "\n\n"
+	if invite.ExpiresAt != nil {
+		text += c.T("share.expires", "time", userTimeFormat(user).When(*invite.ExpiresAt))
+	} else {
+		text += c.T("share.no_expiry")
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "HTML"
+	msg.DisableWebPagePreview = true
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("share.revoke_link"), fmt.Sprintf("shrevoke:%s", invite.ID)),
+		),
+	)
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// shareOverview renders the members and active links of a user's list,
+// with buttons to change roles, remove members and revoke links
+func (b *Bot) shareOverview(user *User) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	c := userCatalog(user)
+	members, err := b.db.GetListMembers(user.ID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to get list members: %w", err)
+	}
+	invites, err := b.db.GetActiveListInvites(user.ID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to get list invites: %w", err)
+	}
+
+	var text strings.Builder
+	text.WriteString(c.T("share.title") + "\n\n")
+
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	if len(members) == 0 {
+		text.WriteString(c.T("share.no_members") + "\n")
+	} else {
+		text.WriteString(c.T("share.members_title") + "\n")
+	}
+	for _, member := range members {
+		name := userDisplayName(&member.User)
+		text.WriteString(fmt.Sprintf("• %s — %s\n", html.EscapeString(name), roleName(c, member.Role)))
+
+		toggle, label := roleEditor, c.T("share.make_editor", "name", name)
+		if member.Role == roleEditor {
+			toggle, label = roleViewer, c.T("share.make_viewer", "name", name)
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("shrole:%s:%s", member.User.ID, toggle)),
+			tgbotapi.NewInlineKeyboardButtonData(c.T("share.remove", "name", name), fmt.Sprintf("shrm:%s", member.User.ID)),
+		))
+	}
+
+	if len(invites) > 0 {
+		text.WriteString("\n" + c.T("share.links_title") + "\n")
+	}
+	tf := userTimeFormat(user)
+	for i, invite := range invites {
+		expiry := c.T("share.no_expiry_short")
+		if invite.ExpiresAt != nil {
+			expiry = c.T("share.expires_short", "time", tf.Time(*invite.ExpiresAt))
+		}
+		text.WriteString(fmt.Sprintf("%d. %s — %s, %s\n", i+1, b.inviteLink(&invite), roleName(c, invite.Role), expiry))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("share.revoke", "number", i+1), fmt.Sprintf("shrevoke:%s", invite.ID)),
+		))
+	}
+
+	text.WriteString("\n" + c.T("share.hint"))
+	return text.String(), tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
+
+// handleShareCallback handles the owner's buttons to revoke links, change
+// roles and remove members, then refreshes the overview
+func (b *Bot) handleShareCallback(callback *tgbotapi.CallbackQuery, action, idStr, arg string) error {
+	user, err := b.db.GetUserByTelegramID(callback.From.ID)
+	id, parseErr := uuid.Parse(idStr)
+	if err != nil || user == nil || parseErr != nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+		})
+		return err
+	}
+	c := userCatalog(user)
+
+	switch action {
+	case "shrevoke":
+		_, err = b.db.RevokeListInvite(user.ID, id)
+
+	case "shrole", "shrm":
+		var member *User
+		member, err = b.db.GetUserByID(id)
+		if err != nil || member == nil || b.listRole(user.ID, id) == "" {
+			_, err := b.api.Request(tgbotapi.CallbackConfig{
+				CallbackQueryID: callback.ID,
+				Text:            c.T("share.not_member"),
+			})
+			return err
+		}
+
+		mc := userCatalog(member)
+		owner := html.EscapeString(userDisplayName(user))
+		if action == "shrm" {
+			if err = b.db.RemoveListMember(user.ID, id); err == nil {
+				b.notifyUser(member, mc.T("share.removed_member", "name", owner))
+			}
+		} else if arg == roleViewer || arg == roleEditor {
+			if err = b.db.UpdateListMemberRole(user.ID, id, arg); err == nil {
+				b.notifyUser(member, mc.T("share.role_changed", "name", owner, "role", roleName(mc, arg)))
+			}
+		}
+	}
+	if err != nil {
+		log.Printf("Failed to update sharing of user %s: %v", user.ID, err)
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            c.T("share.failed"),
+		})
+		return err
+	}
+
+	text, keyboard, err := b.shareOverview(user)
+	if err == nil {
+		edit := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID, text, keyboard)
+		edit.ParseMode = "HTML"
+		edit.DisableWebPagePreview = true
+		if _, err := b.api.Send(edit); err != nil {
+			log.Printf("Failed to refresh sharing overview: %v", err)
+		}
+	}
+
+	_, err = b.api.Request(tgbotapi.CallbackConfig{
+		CallbackQueryID: callback.ID,
+		Text:            c.T("share.updated"),
+	})
+	return err
+}
+
+// joinList adds a user to the list behind an invite token
+func (b *Bot) joinList(chatID int64, user *User, token string) error {
+	c := userCatalog(user)
+	invite, err := b.db.GetListInviteByToken(token)
+	if err != nil {
+		return fmt.Errorf("failed to get list invite: %w", err)
+	}
+	if invite == nil || !inviteActive(invite, time.Now()) {
+		msg := tgbotapi.NewMessage(chatID, c.T("share.invite_invalid"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	if invite.OwnerID == user.ID {
+		msg := tgbotapi.NewMessage(chatID, c.T("share.own_link"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	owner, err := b.db.GetUserByID(invite.OwnerID)
+	if err != nil || owner == nil {
+		return fmt.Errorf("failed to get list owner: %w", err)
+	}
+
+	joined, err := b.db.JoinList(owner.ID, user.ID, invite.Role)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, c.T("share.failed"))
+		_, err2 := b.api.Send(msg)
+		return err2
+	}
+
+	// Only the owner changes the role of an existing member, so a link
+	// cannot undo a demotion
+	if !joined {
+		role := b.listRole(owner.ID, user.ID)
+		msg := tgbotapi.NewMessage(chatID, c.T("share.already_member",
+			"name", html.EscapeString(userDisplayName(owner)), "role", roleName(c, role)))
+		msg.ParseMode = "HTML"
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	oc := userCatalog(owner)
+	b.notifyUser(owner, oc.T("share.joined_owner",
+		"name", html.EscapeString(userDisplayName(user)),
+		"role", roleName(oc, invite.Role)))
+
+	text := c.T("share.joined", "name", html.EscapeString(userDisplayName(owner)), "role", roleName(c, invite.Role))
+	if invite.Role == roleEditor {
+		text += "\n" + c.T("share.joined_editor")
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleShared handles the /shared command, listing the lists the user
+// joined
+func (b *Bot) handleShared(message *tgbotapi.Message) error {
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	text, keyboard, err := b.sharedListsMessage(user)
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "HTML"
+	if len(keyboard.InlineKeyboard) > 0 {
+		msg.ReplyMarkup = keyboard
+	}
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// sharedListsMessage renders the lists a user joined, with buttons to add
+// tasks to them and to leave them
+func (b *Bot) sharedListsMessage(user *User) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	c := userCatalog(user)
+	lists, err := b.db.GetSharedLists(user.ID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to get shared lists: %w", err)
+	}
+
+	var text strings.Builder
+	text.WriteString(c.T("share.shared_title") + "\n\n")
+	if len(lists) == 0 {
+		text.WriteString(c.T("share.shared_none"))
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	for _, list := range lists {
+		name := userDisplayName(&list.User)
+		text.WriteString(fmt.Sprintf("• %s — %s\n", html.EscapeString(name), roleName(c, list.Role)))
+
+		var row []tgbotapi.InlineKeyboardButton
+		if list.Role == roleEditor {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(c.T("share.add_to", "name", name), fmt.Sprintf("shadd:%s", list.User.ID)))
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(c.T("share.leave", "name", name), fmt.Sprintf("shleave:%s", list.User.ID)))
+		rows = append(rows, row)
+	}
+
+	return text.String(), tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}, nil
+}
+
+// handleSharedListCallback handles a member's buttons to add a task to a
+// shared list and to leave it
+func (b *Bot) handleSharedListCallback(callback *tgbotapi.CallbackQuery, action, ownerIDStr string) error {
+	user, err := b.db.GetUserByTelegramID(callback.From.ID)
+	ownerID, parseErr := uuid.Parse(ownerIDStr)
+	if err != nil || user == nil || parseErr != nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+		})
+		return err
+	}
+	c := userCatalog(user)
+
+	owner, err := b.db.GetUserByID(ownerID)
+	role := b.listRole(ownerID, user.ID)
+	if err != nil || owner == nil || role == "" {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+			Text:            c.T("share.not_member"),
+		})
+		return err
+	}
+
+	switch action {
+	case "shadd":
+		if role != roleEditor {
+			_, err := b.api.Request(tgbotapi.CallbackConfig{
+				CallbackQueryID: callback.ID,
+				Text:            c.T("share.view_only"),
+			})
+			return err
+		}
+		b.setPending(callback.From.ID, pendingInput{Kind: pendingSharedAdd, OwnerID: ownerID})
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, c.T("share.add_prompt", "name", html.EscapeString(userDisplayName(owner))))
+		msg.ParseMode = "HTML"
+		if _, err := b.api.Send(msg); err != nil {
+			return err
+		}
+
+	case "shleave":
+		if err := b.db.RemoveListMember(ownerID, user.ID); err != nil {
+			log.Printf("Failed to leave list of %s: %v", ownerID, err)
+			_, err := b.api.Request(tgbotapi.CallbackConfig{
+				CallbackQueryID: callback.ID,
+				Text:            c.T("share.failed"),
+			})
+			return err
+		}
+		b.notifyUser(owner, userCatalog(owner).T("share.left", "name", html.EscapeString(userDisplayName(user))))
+
+		if text, keyboard, err := b.sharedListsMessage(user); err == nil {
+			edit := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID, text, keyboard)
+			edit.ParseMode = "HTML"
+			if _, err := b.api.Send(edit); err != nil {
+				log.Printf("Failed to refresh shared lists: %v", err)
+			}
+		}
+	}
+
+	_, err = b.api.Request(tgbotapi.CallbackConfig{
+		CallbackQueryID: callback.ID,
+	})
+	return err
+}
+
+// handleSharedAddInput adds the task an editor typed to a shared list
+func (b *Bot) handleSharedAddInput(message *tgbotapi.Message, input pendingInput) error {
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil || user == nil {
+		return err
+	}
+	c := userCatalog(user)
+
+	owner, err := b.db.GetUserByID(input.OwnerID)
+	if err != nil || owner == nil || b.listRole(input.OwnerID, user.ID) != roleEditor {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("share.not_member"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	args, dueTime := splitDueSuffix(message.Text, b.nowInUserTimezone(message.From.ID), b.userLocation(message.From.ID))
+	if dueTime != nil {
+		due := dueTime.UTC()
+		dueTime = &due
+	}
+	title, description := splitTitle(args)
+	if title == "" {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("add.missing_title"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	todo, err := b.db.CreateTodo(NewTodo{
+		UserID:      owner.ID,
+		Title:       title,
+		Description: description,
+		DueTime:     dueTime,
+		Priority:    defaultPriority(owner),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create todo: %w", err)
+	}
+	if todo.DueTime != nil {
+		if _, err := b.syncDueReminders(todo, owner); err != nil {
+			log.Printf("Failed to schedule due reminders for todo %s: %v", todo.ID, err)
+		}
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, c.T("share.added",
+		"title", html.EscapeString(todo.Title),
+		"name", html.EscapeString(userDisplayName(owner))))
+	msg.ParseMode = "HTML"
+	_, err = b.api.Send(msg)
+	return err
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Notification styles stored in users.notification_style
+const (
+	// styleDetailed is the full multi-line message with emoji
+	styleDetailed = "detailed"
+	// styleCompact fits a notification on one line
+	styleCompact = "compact"
+	// styleMinimal shows titles only, without emoji, for lock screens
+	styleMinimal = "minimal"
+)
+
+// notificationStyles lists the styles in the order settings shows them
+var notificationStyles = []string{styleDetailed, styleCompact, styleMinimal}
+
+// notificationStyle returns the user's style, treating unknown values as detailed
+func notificationStyle(user *User) string {
+	if user != nil {
+		switch user.NotificationStyle {
+		case styleCompact, styleMinimal:
+			return user.NotificationStyle
+		}
+	}
+	return styleDetailed
+}
+
+// styled picks the text matching the user's notification style
+func styled(user *User, detailed, compact, minimal string) string {
+	switch notificationStyle(user) {
+	case styleCompact:
+		return compact
+	case styleMinimal:
+		return minimal
+	default:
+		return detailed
+	}
+}
+
+// styleName returns the display name of a notification style
+func styleName(c *Catalog, style string) string {
+	return c.T("style." + style)
+}
+
+// numberedTitles lists titles one per line, numbered to match message buttons
+func numberedTitles(titles []string) string {
+	lines := make([]string, len(titles))
+	for i, title := range titles {
+		lines[i] = fmt.Sprintf("%d. %s", i+1, title)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// styleKeyboardRow returns the settings buttons for choosing a notification style
+func styleKeyboardRow(user *User) []tgbotapi.InlineKeyboardButton {
+	c := userCatalog(user)
+	current := notificationStyle(user)
+
+	var row []tgbotapi.InlineKeyboardButton
+	for _, style := range notificationStyles {
+		label := styleName(c, style)
+		if style == current {
+			label = "✓ " + label
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, "style:"+style))
+	}
+	return row
+}
+
+// handleStyleCallback changes the user's notification style from settings
+func (b *Bot) handleStyleCallback(callback *tgbotapi.CallbackQuery, style string) error {
+	valid := false
+	for _, s := range notificationStyles {
+		if s == style {
+			valid = true
+		}
+	}
+
+	user, err := b.db.GetUserByTelegramID(callback.From.ID)
+	if err != nil || user == nil || !valid {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+		})
+		return err
+	}
+
+	previous := notificationStyle(user)
+	if err := b.db.UpdateUserNotificationStyle(user.ID, style); err != nil {
+		return fmt.Errorf("failed to update notification style: %w", err)
+	}
+	user.NotificationStyle = style
+
+	// Tick the new choice in place
+	if previous != style && callback.Message != nil && callback.Message.ReplyMarkup != nil {
+		rows := callback.Message.ReplyMarkup.InlineKeyboard
+		for i, row := range rows {
+			if len(row) > 0 && row[0].CallbackData != nil && strings.HasPrefix(*row[0].CallbackData, "style:") {
+				rows[i] = styleKeyboardRow(user)
+			}
+		}
+		edit := tgbotapi.NewEditMessageReplyMarkup(callback.Message.Chat.ID, callback.Message.MessageID,
+			tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows})
+		if _, err := b.api.Send(edit); err != nil {
+			log.Printf("Failed to update settings keyboard: %v", err)
+		}
+	}
+
+	_, err = b.api.Request(tgbotapi.CallbackConfig{
+		CallbackQueryID: callback.ID,
+		Text:            userCatalog(user).T("style.changed", "style", styleName(userCatalog(user), style)),
+	})
+	return err
+}
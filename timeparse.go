@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseClock parses a time of day like "18:00", "9am" or "9:30pm"
+func parseClock(s string) (hour, minute int, err error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return 0, 0, newLocalizedError("error.empty_clock")
+	}
+
+	meridiem := ""
+	if strings.HasSuffix(s, "am") || strings.HasSuffix(s, "pm") {
+		meridiem = s[len(s)-2:]
+		s = strings.TrimSpace(s[:len(s)-2])
+	}
+
+	hourStr, minuteStr := s, "0"
+	if i := strings.Index(s, ":"); i >= 0 {
+		hourStr, minuteStr = s[:i], s[i+1:]
+	} else if meridiem == "" {
+		// A bare number without am/pm is too ambiguous to be a time of day
+		return 0, 0, newLocalizedError("error.invalid_clock", "input", s)
+	}
+
+	hour, err = strconv.Atoi(hourStr)
+	if err != nil {
+		return 0, 0, newLocalizedError("error.invalid_hour", "input", hourStr)
+	}
+	minute, err = strconv.Atoi(minuteStr)
+	if err != nil || len(minuteStr) != 2 && minuteStr != "0" {
+		return 0, 0, newLocalizedError("error.invalid_minute", "input", minuteStr)
+	}
+
+	switch meridiem {
+	case "am":
+		if hour < 1 || hour > 12 {
+			return 0, 0, newLocalizedError("error.invalid_hour", "input", hour)
+		}
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, newLocalizedError("error.invalid_hour", "input", hour)
+		}
+		if hour != 12 {
+			hour += 12
+		}
+	}
+
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, 0, newLocalizedError("error.clock_range", "input", fmt.Sprintf("%02d:%02d", hour, minute))
+	}
+
+	return hour, minute, nil
+}
+
+// parseReminderTime parses an absolute reminder time such as "at 18:00",
+// "tomorrow 9am", "2026-11-02 08:00" or "1h before due" in the given location.
+// If a bare time of day has already passed today it is rolled to tomorrow and
+// rolled is set so the caller can tell the user.
+func parseReminderTime(input string, now time.Time, loc *time.Location, due *time.Time) (t time.Time, rolled bool, err error) {
+	s := strings.ToLower(strings.TrimSpace(input))
+	now = now.In(loc)
+
+	// "<duration> before due"
+	if strings.HasSuffix(s, " before due") {
+		if due == nil {
+			return time.Time{}, false, newLocalizedError("error.no_due_time")
+		}
+		offset, err := parseDuration(strings.TrimSpace(strings.TrimSuffix(s, " before due")))
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		t = due.In(loc).Add(-offset)
+		if !t.After(now) {
+			return time.Time{}, false, newLocalizedError("error.offset_past", "offset", offset)
+		}
+		return t, false, nil
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return time.Time{}, false, newLocalizedError("error.empty_reminder_time")
+	}
+
+	// Work out which day is meant, then the time of day on that day
+	var day time.Time
+	explicitDay := true
+	switch {
+	case fields[0] == "today":
+		day = now
+		fields = fields[1:]
+	case fields[0] == "tomorrow":
+		day = now.AddDate(0, 0, 1)
+		fields = fields[1:]
+	default:
+		if d, err := time.ParseInLocation("2006-01-02", fields[0], loc); err == nil {
+			day = d
+			fields = fields[1:]
+		} else {
+			day = now
+			explicitDay = false
+		}
+	}
+
+	if len(fields) > 0 && fields[0] == "at" {
+		fields = fields[1:]
+	}
+
+	hour, minute := 9, 0
+	switch {
+	case len(fields) == 0 && explicitDay:
+		// "tomorrow" or "2026-11-02" on their own default to 09:00
+	case len(fields) == 1:
+		hour, minute, err = parseClock(fields[0])
+		if err != nil {
+			return time.Time{}, false, err
+		}
+	default:
+		return time.Time{}, false, newLocalizedError("error.invalid_reminder_time", "input", input)
+	}
+
+	t = time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, loc)
+	if !t.After(now) {
+		if explicitDay {
+			return time.Time{}, false, newLocalizedError("error.time_past", "time", t.Format("2006-01-02 15:04"))
+		}
+		t = time.Date(day.Year(), day.Month(), day.Day()+1, hour, minute, 0, 0, loc)
+		rolled = true
+	}
+
+	return t, rolled, nil
+}
+
+// reminderSpec is a parsed /remind time specification
+type reminderSpec struct {
+	NextTime time.Time
+	// Delay is set when the first fire time was given relative to now
+	Delay    time.Duration
+	Interval time.Duration
+	Schedule *string
+	// Count is the number of occurrences; 0 repeats until Until or forever
+	Count  int
+	Until  *time.Time
+	Rolled bool
+}
+
+// parseRepeatLimit strips a trailing "x5", "until 2026-12-31" or "forever"
+// from a repeating spec and returns the remaining text
+func parseRepeatLimit(spec string, loc *time.Location) (rest string, count int, until *time.Time, err error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return spec, 0, nil, nil
+	}
+
+	last := fields[len(fields)-1]
+	switch {
+	case last == "forever":
+		return strings.Join(fields[:len(fields)-1], " "), 0, nil, nil
+	case strings.HasPrefix(last, "x") && len(last) > 1:
+		n, err := strconv.Atoi(last[1:])
+		if err != nil || n < 1 {
+			return "", 0, nil, newLocalizedError("error.invalid_repeat_count", "input", last)
+		}
+		return strings.Join(fields[:len(fields)-1], " "), n, nil, nil
+	case len(fields) >= 2 && fields[len(fields)-2] == "until":
+		d, err := time.ParseInLocation("2006-01-02", last, loc)
+		if err != nil {
+			return "", 0, nil, newLocalizedError("error.invalid_end_date", "input", last)
+		}
+		// The end date is inclusive
+		end := d.AddDate(0, 0, 1).Add(-time.Second)
+		return strings.Join(fields[:len(fields)-2], " "), 0, &end, nil
+	}
+
+	return spec, 0, nil, nil
+}
+
+// parseReminderSpec parses everything /remind accepts after the task number:
+// relative delays ("2h"), repeating intervals ("every 45m x4"), calendar
+// schedules ("weekdays at 09:00 until 2026-12-31") and absolute times
+func parseReminderSpec(input string, now time.Time, loc *time.Location, due *time.Time) (reminderSpec, error) {
+	s := strings.ToLower(strings.TrimSpace(input))
+	now = now.In(loc)
+
+	// Relative one-shot delay
+	if d, err := parseDuration(strings.TrimPrefix(s, "in ")); err == nil && d > 0 {
+		return reminderSpec{NextTime: now.Add(d), Delay: d, Count: 1}, nil
+	}
+
+	// Fixed interval, first fire one interval from now
+	if strings.HasPrefix(s, "every ") {
+		rest, count, until, err := parseRepeatLimit(s, loc)
+		if err != nil {
+			return reminderSpec{}, err
+		}
+		if d, err := parseDuration(strings.TrimSpace(strings.TrimPrefix(rest, "every "))); err == nil {
+			if d < time.Minute {
+				return reminderSpec{}, newLocalizedError("error.interval_too_short")
+			}
+			spec := reminderSpec{NextTime: now.Add(d), Delay: d, Interval: d, Count: count, Until: until}
+			if until != nil && spec.NextTime.After(*until) {
+				return reminderSpec{}, newLocalizedError("error.end_before_start")
+			}
+			return spec, nil
+		}
+	}
+
+	// Calendar schedule
+	if rest, count, until, err := parseRepeatLimit(s, loc); err == nil {
+		if expr, err := parseSchedule(rest); err == nil {
+			cron, _ := parseCron(expr)
+			next, err := cron.Next(now, loc)
+			if err != nil {
+				return reminderSpec{}, err
+			}
+			if until != nil && next.After(*until) {
+				return reminderSpec{}, newLocalizedError("error.end_before_start")
+			}
+			return reminderSpec{NextTime: next, Schedule: &expr, Count: count, Until: until}, nil
+		}
+	}
+
+	// Absolute one-shot time
+	t, rolled, err := parseReminderTime(s, now, loc, due)
+	if err != nil {
+		return reminderSpec{}, err
+	}
+	return reminderSpec{NextTime: t, Count: 1, Rolled: rolled}, nil
+}
+
+// formatInterval renders a repeat interval compactly, e.g. "45m", "2h" or "1w"
+func formatInterval(d time.Duration) string {
+	const day = 24 * time.Hour
+	switch {
+	case d%(7*day) == 0:
+		return fmt.Sprintf("%dw", d/(7*day))
+	case d%day == 0:
+		return fmt.Sprintf("%dd", d/day)
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
+
+// resolveSnooze turns a snooze option like "10m", "tonight" or "tomorrow"
+// into the time the reminder should fire again. "tonight" means 20:00
+// today (or an hour from now once that has passed) and "tomorrow" means
+// 09:00 tomorrow.
+func resolveSnooze(option string, now time.Time, loc *time.Location) (time.Time, error) {
+	now = now.In(loc)
+	switch option {
+	case "tonight":
+		t := time.Date(now.Year(), now.Month(), now.Day(), 20, 0, 0, 0, loc)
+		if !t.After(now) {
+			return now.Add(time.Hour), nil
+		}
+		return t, nil
+	case "tomorrow":
+		return time.Date(now.Year(), now.Month(), now.Day()+1, 9, 0, 0, 0, loc), nil
+	}
+
+	d, err := parseDuration(option)
+	if err != nil || d <= 0 {
+		return time.Time{}, newLocalizedError("error.invalid_snooze", "input", option)
+	}
+	return now.Add(d), nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		input        string
+		hour, minute int
+	}{
+		{"18:00", 18, 0},
+		{"9:05", 9, 5},
+		{"9am", 9, 0},
+		{"12am", 0, 0},
+		{"12pm", 12, 0},
+		{"9:30pm", 21, 30},
+	}
+	for _, tt := range tests {
+		hour, minute, err := parseClock(tt.input)
+		if err != nil {
+			t.Errorf("parseClock(%q) error: %v", tt.input, err)
+			continue
+		}
+		if hour != tt.hour || minute != tt.minute {
+			t.Errorf("parseClock(%q) = %d:%02d, want %d:%02d", tt.input, hour, minute, tt.hour, tt.minute)
+		}
+	}
+
+	for _, input := range []string{"", "9", "24:00", "9:60", "9:5", "13pm", "0am", "ab:cd"} {
+		if _, _, err := parseClock(input); err == nil {
+			t.Errorf("parseClock(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestParseReminderSpec(t *testing.T) {
+	// Sunday 10:00
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	due := now.Add(3 * time.Hour)
+	weekdays := "0 9 * * 1-5"
+	until := time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC)
+
+	tests := []struct {
+		input string
+		want  reminderSpec
+	}{
+		{"2h", reminderSpec{NextTime: now.Add(2 * time.Hour), Delay: 2 * time.Hour, Count: 1}},
+		{"in 30m", reminderSpec{NextTime: now.Add(30 * time.Minute), Delay: 30 * time.Minute, Count: 1}},
+		{"every 45m x4", reminderSpec{NextTime: now.Add(45 * time.Minute), Delay: 45 * time.Minute, Interval: 45 * time.Minute, Count: 4}},
+		{"every 1d forever", reminderSpec{NextTime: now.Add(24 * time.Hour), Delay: 24 * time.Hour, Interval: 24 * time.Hour}},
+		{"weekdays at 09:00 until 2026-12-31", reminderSpec{NextTime: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC), Schedule: &weekdays, Until: &until}},
+		{"at 18:00", reminderSpec{NextTime: time.Date(2026, 10, 18, 18, 0, 0, 0, time.UTC), Count: 1}},
+		{"08:00", reminderSpec{NextTime: time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC), Count: 1, Rolled: true}},
+		{"tomorrow 9am", reminderSpec{NextTime: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC), Count: 1}},
+		{"tomorrow", reminderSpec{NextTime: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC), Count: 1}},
+		{"2026-11-02 08:00", reminderSpec{NextTime: time.Date(2026, 11, 2, 8, 0, 0, 0, time.UTC), Count: 1}},
+		{"1h before due", reminderSpec{NextTime: due.Add(-time.Hour), Count: 1}},
+	}
+	for _, tt := range tests {
+		got, err := parseReminderSpec(tt.input, now, time.UTC, &due)
+		if err != nil {
+			t.Errorf("parseReminderSpec(%q) error: %v", tt.input, err)
+			continue
+		}
+		if !got.NextTime.Equal(tt.want.NextTime) || got.Delay != tt.want.Delay || got.Interval != tt.want.Interval ||
+			got.Count != tt.want.Count || got.Rolled != tt.want.Rolled {
+			t.Errorf("parseReminderSpec(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+		if (got.Schedule == nil) != (tt.want.Schedule == nil) || got.Schedule != nil && *got.Schedule != *tt.want.Schedule {
+			t.Errorf("parseReminderSpec(%q) schedule = %v, want %v", tt.input, got.Schedule, tt.want.Schedule)
+		}
+		if (got.Until == nil) != (tt.want.Until == nil) || got.Until != nil && !got.Until.Equal(*tt.want.Until) {
+			t.Errorf("parseReminderSpec(%q) until = %v, want %v", tt.input, got.Until, tt.want.Until)
+		}
+	}
+}
+
+func TestParseReminderSpecErrors(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	due := now.Add(30 * time.Minute)
+
+	tests := []struct {
+		input string
+		due   *time.Time
+	}{
+		{"", &due},
+		{"every 0m", &due},
+		{"every 1h x0", &due},
+		{"every 1d until 2026-10-01", &due},
+		{"weekdays 09:00 until 2026-10-18", &due},
+		{"2026-10-17 08:00", &due},
+		{"today 09:00", &due},
+		{"1h before due", &due},
+		{"1h before due", nil},
+		{"next week sometime", &due},
+	}
+	for _, tt := range tests {
+		if spec, err := parseReminderSpec(tt.input, now, time.UTC, tt.due); err == nil {
+			t.Errorf("parseReminderSpec(%q) = %+v, want an error", tt.input, spec)
+		}
+	}
+}
+
+func TestFormatInterval(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{45 * time.Minute, "45m"},
+		{90 * time.Minute, "90m"},
+		{2 * time.Hour, "2h"},
+		{48 * time.Hour, "2d"},
+		{14 * 24 * time.Hour, "2w"},
+	}
+	for _, tt := range tests {
+		if got := formatInterval(tt.d); got != tt.want {
+			t.Errorf("formatInterval(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,456 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	// Embed the zone database so timezones work on hosts without one
+	_ "time/tzdata"
+)
+
+// defaultTimezone is used for new users and when a stored zone is invalid
+const defaultTimezone = "Asia/Bangkok"
+
+// maxTimezoneMatches caps how many search results are offered as buttons
+const maxTimezoneMatches = 8
+
+// timezoneRegions lists the region menus in display order
+var timezoneRegions = []string{"Asia", "Europe", "America", "Africa", "Australia", "Pacific", "Atlantic", "Indian"}
+
+// validTimezone reports whether name is a zone time.LoadLocation accepts.
+// "Local" is rejected as it depends on the server.
+func validTimezone(name string) bool {
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// zoneOffset renders a zone's current UTC offset, e.g. "UTC+7" or "UTC+5:30"
+func zoneOffset(zone string, now time.Time) string {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return ""
+	}
+	_, offset := now.In(loc).Zone()
+
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	hours, minutes := offset/3600, offset%3600/60
+	if minutes != 0 {
+		return fmt.Sprintf("UTC%s%d:%02d", sign, hours, minutes)
+	}
+	return fmt.Sprintf("UTC%s%d", sign, hours)
+}
+
+// regionCities returns one representative city per zone in a region,
+// sorted by UTC offset and then name
+func regionCities(region string, now time.Time) []timezoneCity {
+	seen := make(map[string]bool)
+	var cities []timezoneCity
+	for _, city := range timezoneCities {
+		if !strings.HasPrefix(city.Zone, region+"/") || seen[city.Zone] {
+			continue
+		}
+		seen[city.Zone] = true
+		cities = append(cities, city)
+	}
+
+	offset := func(zone string) int {
+		loc, _ := time.LoadLocation(zone)
+		_, seconds := now.In(loc).Zone()
+		return seconds
+	}
+	sort.SliceStable(cities, func(i, j int) bool {
+		oi, oj := offset(cities[i].Zone), offset(cities[j].Zone)
+		if oi != oj {
+			return oi < oj
+		}
+		return cities[i].Name < cities[j].Name
+	})
+	return cities
+}
+
+// searchTimezones finds zones by city, country or zone name. An exact IANA
+// zone name matches itself; otherwise each matching zone is listed once
+// with the first matching city.
+func searchTimezones(query string) []timezoneCity {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return nil
+	}
+
+	for _, city := range timezoneCities {
+		if strings.ToLower(city.Zone) == q {
+			return []timezoneCity{city}
+		}
+	}
+	if validTimezone(query) && (strings.Contains(query, "/") || strings.EqualFold(query, "UTC")) {
+		return []timezoneCity{{Name: query, Zone: query}}
+	}
+
+	// Exact city names win over partial matches
+	var exact, partial []timezoneCity
+	seen := make(map[string]bool)
+	for _, city := range timezoneCities {
+		name := strings.ToLower(city.Name)
+		zone := strings.ToLower(strings.ReplaceAll(city.Zone, "_", " "))
+		switch {
+		case name == q:
+			exact = append(exact, city)
+		case strings.Contains(name, q), strings.ToLower(city.Country) == q, strings.HasSuffix(zone, "/"+q):
+			if !seen[city.Zone] {
+				seen[city.Zone] = true
+				partial = append(partial, city)
+			}
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+	return partial
+}
+
+// zoneCandidateMargin is how much farther than the nearest city another
+// city may be and still have its zone offered for a shared location
+const zoneCandidateMargin = 300.0
+
+// nearestCity returns the bundled city closest to a coordinate
+func nearestCity(lat, lon float64) timezoneCity {
+	best := timezoneCities[0]
+	bestDistance := math.Inf(1)
+	for _, city := range timezoneCities {
+		if d := greatCircleDistance(lat, lon, city.Lat, city.Lon); d < bestDistance {
+			best, bestDistance = city, d
+		}
+	}
+	return best
+}
+
+// nearbyZoneCities returns the nearest city for each zone within
+// zoneCandidateMargin km of the nearest city, closest first. The city table
+// is not a boundary map, so more than one result means the location may be
+// near a zone border and the user has to pick.
+func nearbyZoneCities(lat, lon float64) []timezoneCity {
+	nearest := nearestCity(lat, lon)
+	limit := greatCircleDistance(lat, lon, nearest.Lat, nearest.Lon) + zoneCandidateMargin
+
+	type candidate struct {
+		city     timezoneCity
+		distance float64
+	}
+	byZone := make(map[string]candidate)
+	for _, city := range timezoneCities {
+		d := greatCircleDistance(lat, lon, city.Lat, city.Lon)
+		if d > limit {
+			continue
+		}
+		if c, ok := byZone[city.Zone]; !ok || d < c.distance {
+			byZone[city.Zone] = candidate{city, d}
+		}
+	}
+
+	candidates := make([]candidate, 0, len(byZone))
+	for _, c := range byZone {
+		candidates = append(candidates, c)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].distance < candidates[j].distance })
+
+	cities := make([]timezoneCity, len(candidates))
+	for i, c := range candidates {
+		cities[i] = c.city
+	}
+	return cities
+}
+
+// greatCircleDistance returns the distance between two coordinates in
+// kilometres using the haversine formula
+func greatCircleDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadius = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+// timezoneButton builds a button that sets the given city's zone with the
+// callback action, e.g. "tz:set"
+func timezoneButton(city timezoneCity, now time.Time, action string) tgbotapi.InlineKeyboardButton {
+	label := fmt.Sprintf("%s (%s)", city.Name, zoneOffset(city.Zone, now))
+	return tgbotapi.NewInlineKeyboardButtonData(label, action+":"+city.Zone)
+}
+
+// timezoneButtonRows lays city buttons out two per row
+func timezoneButtonRows(cities []timezoneCity, now time.Time, action string) [][]tgbotapi.InlineKeyboardButton {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(cities); i += 2 {
+		row := []tgbotapi.InlineKeyboardButton{timezoneButton(cities[i], now, action)}
+		if i+1 < len(cities) {
+			row = append(row, timezoneButton(cities[i+1], now, action))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// timezoneRegionRows lays the region buttons out four per row, opening
+// each region with the callback action, e.g. "tz:region"
+func timezoneRegionRows(action string) [][]tgbotapi.InlineKeyboardButton {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(timezoneRegions); i += 4 {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, region := range timezoneRegions[i:min(i+4, len(timezoneRegions))] {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(region, action+":"+region))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// timezoneMenuKeyboard builds the region menu
+func timezoneMenuKeyboard(c *Catalog) tgbotapi.InlineKeyboardMarkup {
+	rows := timezoneRegionRows("tz:region")
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(c.T("timezone.use_location"), "tz:location"),
+		tgbotapi.NewInlineKeyboardButtonData("UTC", "tz:set:UTC"),
+	))
+	rows = append(rows, settingsBackRow(c))
+	return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// timezoneMenuText describes the current timezone and how to change it
+func (b *Bot) timezoneMenuText(user *User) string {
+	zone := b.getUserTimezone(user.TelegramID)
+	c := userCatalog(user)
+	return c.T("timezone.menu",
+		"zone", html.EscapeString(zone),
+		"offset", zoneOffset(zone, time.Now()),
+		"time", formatClock(c, user, b.nowInUserTimezone(user.TelegramID)))
+}
+
+// setUserTimezone validates and stores a user's timezone, then moves
+// calendar-based reminders to the new wall clock
+func (b *Bot) setUserTimezone(user *User, zone string) error {
+	if !validTimezone(zone) {
+		return fmt.Errorf("unknown timezone: %s", zone)
+	}
+	if _, err := b.db.UpdateUserSettings(user.ID, UserSettings{Timezone: &zone}); err != nil {
+		return err
+	}
+	user.Timezone = zone
+
+	reminders, err := b.db.GetUserReminders(user.ID)
+	if err != nil {
+		log.Printf("Failed to get reminders of user %s: %v", user.ID, err)
+		return nil
+	}
+	for _, item := range reminders {
+		// Snoozes are absolute and stay as they are
+		if item.Reminder.Schedule == nil || item.Reminder.SnoozedUntil != nil {
+			continue
+		}
+		next, err := b.nextScheduledTime(*item.Reminder.Schedule, user)
+		if err != nil {
+			continue
+		}
+		if err := b.db.UpdateReminderTime(item.Reminder.ID, next, item.Reminder.RepeatCount); err != nil {
+			log.Printf("Failed to move reminder %s to the new timezone: %v", item.Reminder.ID, err)
+		}
+	}
+	return nil
+}
+
+// timezoneSetText confirms a timezone change
+func timezoneSetText(zone, city string, user *User) string {
+	c := userCatalog(user)
+	text := c.T("timezone.set", "zone", html.EscapeString(zone), "offset", zoneOffset(zone, time.Now()))
+	if city != "" && city != zone {
+		text += "\n📍 " + html.EscapeString(city)
+	}
+	text += "\n\n" + c.T("timezone.local_time", "time", formatClock(c, user, time.Now().In(mustLoadLocation(zone))))
+	return text
+}
+
+// mustLoadLocation loads a zone already checked with validTimezone
+func mustLoadLocation(zone string) *time.Location {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// handleTimezone handles the /timezone command
+func (b *Bot) handleTimezone(message *tgbotapi.Message) error {
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+	c := userCatalog(user)
+
+	query := strings.TrimSpace(message.CommandArguments())
+	if query == "" {
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.timezoneMenuText(user))
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = timezoneMenuKeyboard(c)
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	matches := searchTimezones(query)
+	switch {
+	case len(matches) == 0:
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("timezone.not_found", "query", query))
+		msg.ReplyMarkup = timezoneMenuKeyboard(c)
+		_, err := b.api.Send(msg)
+		return err
+
+	case len(matches) == 1:
+		if err := b.setUserTimezone(user, matches[0].Zone); err != nil {
+			return fmt.Errorf("failed to set timezone: %w", err)
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, timezoneSetText(matches[0].Zone, matches[0].Name, user))
+		msg.ParseMode = "HTML"
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	if len(matches) > maxTimezoneMatches {
+		matches = matches[:maxTimezoneMatches]
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, c.T("timezone.which"))
+	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: timezoneButtonRows(matches, time.Now(), "tz:set")}
+	_, err = b.api.Send(msg)
+	return err
+}
+
+// handleTimezoneCallback handles the region menu, city and location buttons
+func (b *Bot) handleTimezoneCallback(callback *tgbotapi.CallbackQuery, action, arg string) error {
+	user, err := b.db.GetUserByTelegramID(callback.From.ID)
+	if err != nil || user == nil {
+		_, err := b.api.Request(tgbotapi.CallbackConfig{
+			CallbackQueryID: callback.ID,
+		})
+		return err
+	}
+	c := userCatalog(user)
+
+	switch action {
+	case "menu":
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.timezoneMenuText(user))
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = timezoneMenuKeyboard(c)
+		if _, err := b.api.Send(msg); err != nil {
+			return err
+		}
+
+	case "region":
+		rows := timezoneButtonRows(regionCities(arg, time.Now()), time.Now(), "tz:set")
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.T("timezone.regions"), "tz:regions"),
+		))
+		edit := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID,
+			c.T("timezone.region", "region", html.EscapeString(arg)),
+			tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows})
+		edit.ParseMode = "HTML"
+		if _, err := b.api.Send(edit); err != nil {
+			log.Printf("Failed to show timezone region: %v", err)
+		}
+
+	case "regions":
+		edit := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID,
+			b.timezoneMenuText(user), timezoneMenuKeyboard(c))
+		edit.ParseMode = "HTML"
+		if _, err := b.api.Send(edit); err != nil {
+			log.Printf("Failed to show timezone regions: %v", err)
+		}
+
+	case "location":
+		button := tgbotapi.NewKeyboardButtonLocation(c.T("timezone.share_location"))
+		keyboard := tgbotapi.NewReplyKeyboard(tgbotapi.NewKeyboardButtonRow(button))
+		keyboard.OneTimeKeyboard = true
+		keyboard.ResizeKeyboard = true
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, c.T("timezone.location_prompt"))
+		msg.ReplyMarkup = keyboard
+		if _, err := b.api.Send(msg); err != nil {
+			return err
+		}
+
+	case "set":
+		if err := b.setUserTimezone(user, arg); err != nil {
+			_, err := b.api.Request(tgbotapi.CallbackConfig{
+				CallbackQueryID: callback.ID,
+				Text:            c.T("timezone.unknown"),
+			})
+			return err
+		}
+		city := ""
+		if matches := searchTimezones(arg); len(matches) == 1 {
+			city = matches[0].Name
+		}
+		edit := tgbotapi.NewEditMessageTextAndMarkup(callback.Message.Chat.ID, callback.Message.MessageID,
+			timezoneSetText(arg, city, user), tgbotapi.NewInlineKeyboardMarkup(settingsBackRow(c)))
+		edit.ParseMode = "HTML"
+		if _, err := b.api.Send(edit); err != nil {
+			log.Printf("Failed to confirm timezone: %v", err)
+		}
+	}
+
+	_, err = b.api.Request(tgbotapi.CallbackConfig{
+		CallbackQueryID: callback.ID,
+	})
+	return err
+}
+
+// handleLocation sets the timezone from a shared location
+func (b *Bot) handleLocation(message *tgbotapi.Message) error {
+	user, err := b.db.GetUserByTelegramID(message.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, catalogFor(message.From.LanguageCode).T("error.start_first"))
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	c := userCatalog(user)
+	cities := nearbyZoneCities(message.Location.Latitude, message.Location.Longitude)
+	if len(cities) > 1 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, c.T("timezone.location_which"))
+		msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: timezoneButtonRows(cities, time.Now(), "tz:set")}
+		_, err := b.api.Send(msg)
+		return err
+	}
+
+	city := cities[0]
+	if err := b.setUserTimezone(user, city.Zone); err != nil {
+		return fmt.Errorf("failed to set timezone: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, timezoneSetText(city.Zone, c.T("timezone.nearest", "city", city.Name), user)+
+		"\n\n"+c.T("timezone.wrong"))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+	_, err = b.api.Send(msg)
+	return err
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestNearbyZoneCities(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		want     []string
+	}{
+		{"central Bangkok", 13.75, 100.50, []string{"Asia/Bangkok"}},
+		{"Nong Khai on the Mekong", 17.88, 102.74, []string{"Asia/Vientiane", "Asia/Bangkok"}},
+	}
+	for _, tt := range tests {
+		got := nearbyZoneCities(tt.lat, tt.lon)
+		var zones []string
+		for _, city := range got {
+			zones = append(zones, city.Zone)
+		}
+		if len(zones) != len(tt.want) {
+			t.Errorf("%s: zones = %v, want %v", tt.name, zones, tt.want)
+			continue
+		}
+		for i := range zones {
+			if zones[i] != tt.want[i] {
+				t.Errorf("%s: zones = %v, want %v", tt.name, zones, tt.want)
+				break
+			}
+		}
+	}
+}
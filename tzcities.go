@@ -0,0 +1,174 @@
+package main
+
+// timezoneCity is a city in the bundled timezone table
+type timezoneCity struct {
+	Name    string
+	Country string
+	Zone    string
+	Lat     float64
+	Lon     float64
+}
+
+// timezoneCities maps major cities to their IANA zones. It is not a zone
+// boundary table: shared locations resolve through the nearest cities, so
+// a location close to a border or far from any listed city can land in the
+// wrong zone, and nearbyZoneCities asks the user whenever cities in more
+// than one zone are close. The first city listed for a zone represents it
+// in the region menus.
+var timezoneCities = []timezoneCity{
+	// Asia
+	{"Bangkok", "Thailand", "Asia/Bangkok", 13.75, 100.50},
+	{"Chiang Mai", "Thailand", "Asia/Bangkok", 18.79, 98.98},
+	{"Phuket", "Thailand", "Asia/Bangkok", 7.88, 98.39},
+	{"Hat Yai", "Thailand", "Asia/Bangkok", 7.01, 100.47},
+	{"Khon Kaen", "Thailand", "Asia/Bangkok", 16.44, 102.83},
+	{"Vientiane", "Laos", "Asia/Vientiane", 17.97, 102.60},
+	{"Phnom Penh", "Cambodia", "Asia/Phnom_Penh", 11.56, 104.92},
+	{"Ho Chi Minh City", "Vietnam", "Asia/Ho_Chi_Minh", 10.82, 106.63},
+	{"Hanoi", "Vietnam", "Asia/Ho_Chi_Minh", 21.03, 105.85},
+	{"Yangon", "Myanmar", "Asia/Yangon", 16.87, 96.20},
+	{"Kuala Lumpur", "Malaysia", "Asia/Kuala_Lumpur", 3.14, 101.69},
+	{"Singapore", "Singapore", "Asia/Singapore", 1.35, 103.82},
+	{"Jakarta", "Indonesia", "Asia/Jakarta", -6.21, 106.85},
+	{"Bali", "Indonesia", "Asia/Makassar", -8.65, 115.22},
+	{"Manila", "Philippines", "Asia/Manila", 14.60, 120.98},
+	{"Hong Kong", "Hong Kong", "Asia/Hong_Kong", 22.32, 114.17},
+	{"Shanghai", "China", "Asia/Shanghai", 31.23, 121.47},
+	{"Beijing", "China", "Asia/Shanghai", 39.90, 116.41},
+	{"Shenzhen", "China", "Asia/Shanghai", 22.54, 114.06},
+	{"Taipei", "Taiwan", "Asia/Taipei", 25.03, 121.57},
+	{"Seoul", "South Korea", "Asia/Seoul", 37.57, 126.98},
+	{"Tokyo", "Japan", "Asia/Tokyo", 35.68, 139.69},
+	{"Osaka", "Japan", "Asia/Tokyo", 34.69, 135.50},
+	{"Ulaanbaatar", "Mongolia", "Asia/Ulaanbaatar", 47.89, 106.91},
+	{"Kolkata", "India", "Asia/Kolkata", 22.57, 88.36},
+	{"Mumbai", "India", "Asia/Kolkata", 19.08, 72.88},
+	{"Delhi", "India", "Asia/Kolkata", 28.70, 77.10},
+	{"Bangalore", "India", "Asia/Kolkata", 12.97, 77.59},
+	{"Colombo", "Sri Lanka", "Asia/Colombo", 6.93, 79.86},
+	{"Kathmandu", "Nepal", "Asia/Kathmandu", 27.72, 85.32},
+	{"Dhaka", "Bangladesh", "Asia/Dhaka", 23.81, 90.41},
+	{"Karachi", "Pakistan", "Asia/Karachi", 24.86, 67.01},
+	{"Kabul", "Afghanistan", "Asia/Kabul", 34.56, 69.21},
+	{"Tashkent", "Uzbekistan", "Asia/Tashkent", 41.30, 69.24},
+	{"Almaty", "Kazakhstan", "Asia/Almaty", 43.24, 76.89},
+	{"Dubai", "United Arab Emirates", "Asia/Dubai", 25.20, 55.27},
+	{"Abu Dhabi", "United Arab Emirates", "Asia/Dubai", 24.45, 54.38},
+	{"Muscat", "Oman", "Asia/Muscat", 23.59, 58.41},
+	{"Doha", "Qatar", "Asia/Qatar", 25.29, 51.53},
+	{"Riyadh", "Saudi Arabia", "Asia/Riyadh", 24.71, 46.68},
+	{"Tehran", "Iran", "Asia/Tehran", 35.69, 51.39},
+	{"Baghdad", "Iraq", "Asia/Baghdad", 33.31, 44.36},
+	{"Jerusalem", "Israel", "Asia/Jerusalem", 31.77, 35.21},
+	{"Tel Aviv", "Israel", "Asia/Jerusalem", 32.09, 34.78},
+	{"Beirut", "Lebanon", "Asia/Beirut", 33.89, 35.50},
+	{"Yerevan", "Armenia", "Asia/Yerevan", 40.18, 44.51},
+	{"Tbilisi", "Georgia", "Asia/Tbilisi", 41.72, 44.79},
+	{"Baku", "Azerbaijan", "Asia/Baku", 40.41, 49.87},
+	{"Novosibirsk", "Russia", "Asia/Novosibirsk", 55.01, 82.93},
+	{"Vladivostok", "Russia", "Asia/Vladivostok", 43.12, 131.89},
+	{"Yekaterinburg", "Russia", "Asia/Yekaterinburg", 56.84, 60.61},
+
+	// Europe
+	{"London", "United Kingdom", "Europe/London", 51.51, -0.13},
+	{"Manchester", "United Kingdom", "Europe/London", 53.48, -2.24},
+	{"Edinburgh", "United Kingdom", "Europe/London", 55.95, -3.19},
+	{"Dublin", "Ireland", "Europe/Dublin", 53.35, -6.26},
+	{"Lisbon", "Portugal", "Europe/Lisbon", 38.72, -9.14},
+	{"Madrid", "Spain", "Europe/Madrid", 40.42, -3.70},
+	{"Barcelona", "Spain", "Europe/Madrid", 41.39, 2.17},
+	{"Paris", "France", "Europe/Paris", 48.86, 2.35},
+	{"Brussels", "Belgium", "Europe/Brussels", 50.85, 4.35},
+	{"Amsterdam", "Netherlands", "Europe/Amsterdam", 52.37, 4.90},
+	{"Berlin", "Germany", "Europe/Berlin", 52.52, 13.40},
+	{"Munich", "Germany", "Europe/Berlin", 48.14, 11.58},
+	{"Frankfurt", "Germany", "Europe/Berlin", 50.11, 8.68},
+	{"Zurich", "Switzerland", "Europe/Zurich", 47.38, 8.54},
+	{"Vienna", "Austria", "Europe/Vienna", 48.21, 16.37},
+	{"Rome", "Italy", "Europe/Rome", 41.90, 12.50},
+	{"Milan", "Italy", "Europe/Rome", 45.46, 9.19},
+	{"Copenhagen", "Denmark", "Europe/Copenhagen", 55.68, 12.57},
+	{"Oslo", "Norway", "Europe/Oslo", 59.91, 10.75},
+	{"Stockholm", "Sweden", "Europe/Stockholm", 59.33, 18.07},
+	{"Helsinki", "Finland", "Europe/Helsinki", 60.17, 24.94},
+	{"Warsaw", "Poland", "Europe/Warsaw", 52.23, 21.01},
+	{"Prague", "Czechia", "Europe/Prague", 50.08, 14.44},
+	{"Budapest", "Hungary", "Europe/Budapest", 47.50, 19.04},
+	{"Bucharest", "Romania", "Europe/Bucharest", 44.43, 26.10},
+	{"Sofia", "Bulgaria", "Europe/Sofia", 42.70, 23.32},
+	{"Athens", "Greece", "Europe/Athens", 37.98, 23.73},
+	{"Istanbul", "Turkey", "Europe/Istanbul", 41.01, 28.98},
+	{"Kyiv", "Ukraine", "Europe/Kyiv", 50.45, 30.52},
+	{"Moscow", "Russia", "Europe/Moscow", 55.76, 37.62},
+	{"Saint Petersburg", "Russia", "Europe/Moscow", 59.93, 30.34},
+
+	// Africa
+	{"Cairo", "Egypt", "Africa/Cairo", 30.04, 31.24},
+	{"Casablanca", "Morocco", "Africa/Casablanca", 33.57, -7.59},
+	{"Lagos", "Nigeria", "Africa/Lagos", 6.52, 3.38},
+	{"Accra", "Ghana", "Africa/Accra", 5.60, -0.19},
+	{"Nairobi", "Kenya", "Africa/Nairobi", -1.29, 36.82},
+	{"Addis Ababa", "Ethiopia", "Africa/Addis_Ababa", 9.03, 38.74},
+	{"Johannesburg", "South Africa", "Africa/Johannesburg", -26.20, 28.05},
+	{"Cape Town", "South Africa", "Africa/Johannesburg", -33.92, 18.42},
+	{"Kinshasa", "DR Congo", "Africa/Kinshasa", -4.44, 15.27},
+	{"Algiers", "Algeria", "Africa/Algiers", 36.75, 3.06},
+	{"Tunis", "Tunisia", "Africa/Tunis", 36.81, 10.18},
+	{"Dakar", "Senegal", "Africa/Dakar", 14.72, -17.47},
+
+	// America
+	{"New York", "United States", "America/New_York", 40.71, -74.01},
+	{"Boston", "United States", "America/New_York", 42.36, -71.06},
+	{"Washington", "United States", "America/New_York", 38.91, -77.04},
+	{"Miami", "United States", "America/New_York", 25.76, -80.19},
+	{"Atlanta", "United States", "America/New_York", 33.75, -84.39},
+	{"Toronto", "Canada", "America/Toronto", 43.65, -79.38},
+	{"Montreal", "Canada", "America/Toronto", 45.50, -73.57},
+	{"Chicago", "United States", "America/Chicago", 41.88, -87.63},
+	{"Houston", "United States", "America/Chicago", 29.76, -95.37},
+	{"Dallas", "United States", "America/Chicago", 32.78, -96.80},
+	{"Winnipeg", "Canada", "America/Winnipeg", 49.90, -97.14},
+	{"Mexico City", "Mexico", "America/Mexico_City", 19.43, -99.13},
+	{"Denver", "United States", "America/Denver", 39.74, -104.99},
+	{"Phoenix", "United States", "America/Phoenix", 33.45, -112.07},
+	{"Calgary", "Canada", "America/Edmonton", 51.05, -114.07},
+	{"Los Angeles", "United States", "America/Los_Angeles", 34.05, -118.24},
+	{"San Francisco", "United States", "America/Los_Angeles", 37.77, -122.42},
+	{"Seattle", "United States", "America/Los_Angeles", 47.61, -122.33},
+	{"Vancouver", "Canada", "America/Vancouver", 49.28, -123.12},
+	{"Anchorage", "United States", "America/Anchorage", 61.22, -149.90},
+	{"Halifax", "Canada", "America/Halifax", 44.65, -63.58},
+	{"St. John's", "Canada", "America/St_Johns", 47.56, -52.71},
+	{"Havana", "Cuba", "America/Havana", 23.11, -82.37},
+	{"Panama City", "Panama", "America/Panama", 8.98, -79.52},
+	{"Bogota", "Colombia", "America/Bogota", 4.71, -74.07},
+	{"Lima", "Peru", "America/Lima", -12.05, -77.04},
+	{"Caracas", "Venezuela", "America/Caracas", 10.48, -66.90},
+	{"Santiago", "Chile", "America/Santiago", -33.45, -70.67},
+	{"Buenos Aires", "Argentina", "America/Argentina/Buenos_Aires", -34.60, -58.38},
+	{"Sao Paulo", "Brazil", "America/Sao_Paulo", -23.55, -46.63},
+	{"Rio de Janeiro", "Brazil", "America/Sao_Paulo", -22.91, -43.17},
+	{"Manaus", "Brazil", "America/Manaus", -3.12, -60.02},
+	{"Montevideo", "Uruguay", "America/Montevideo", -34.90, -56.16},
+
+	// Pacific and Australia
+	{"Sydney", "Australia", "Australia/Sydney", -33.87, 151.21},
+	{"Melbourne", "Australia", "Australia/Melbourne", -37.81, 144.96},
+	{"Brisbane", "Australia", "Australia/Brisbane", -27.47, 153.03},
+	{"Adelaide", "Australia", "Australia/Adelaide", -34.93, 138.60},
+	{"Darwin", "Australia", "Australia/Darwin", -12.46, 130.84},
+	{"Perth", "Australia", "Australia/Perth", -31.95, 115.86},
+	{"Auckland", "New Zealand", "Pacific/Auckland", -36.85, 174.76},
+	{"Wellington", "New Zealand", "Pacific/Auckland", -41.29, 174.78},
+	{"Fiji", "Fiji", "Pacific/Fiji", -18.14, 178.44},
+	{"Guam", "Guam", "Pacific/Guam", 13.44, 144.79},
+	{"Port Moresby", "Papua New Guinea", "Pacific/Port_Moresby", -9.44, 147.18},
+	{"Honolulu", "United States", "Pacific/Honolulu", 21.31, -157.86},
+
+	// Atlantic and Indian Ocean
+	{"Reykjavik", "Iceland", "Atlantic/Reykjavik", 64.15, -21.94},
+	{"Azores", "Portugal", "Atlantic/Azores", 37.74, -25.67},
+	{"Canary Islands", "Spain", "Atlantic/Canary", 28.29, -16.63},
+	{"Maldives", "Maldives", "Indian/Maldives", 4.18, 73.51},
+	{"Mauritius", "Mauritius", "Indian/Mauritius", -20.16, 57.50},
+}